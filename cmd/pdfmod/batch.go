@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const batchUsage = `Usage:
+  pdfmod batch [-set FIELD=VALUE]... [-rename] [-template TEMPLATE] <file.pdf|dir>...
+
+Edits the metadata of each PDF as the interactive flow does for a
+selection of files, without prompting. The -set fields are written in a
+single update, along with the configured producer and author unless they
+are set; an empty VALUE removes the field. With -rename, each file is then
+renamed by the rename template, as with pdfmod rename.`
+
+func runBatch(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fields := map[string]string{}
+	fs.Func("set", "write FIELD=VALUE, such as Title=Report (repeatable)", func(s string) error {
+		field, value, ok := strings.Cut(s, "=")
+		if !ok || field == "" {
+			return fmt.Errorf("%q is not FIELD=VALUE", s)
+		}
+		fields[field] = value
+		return nil
+	})
+	rename := fs.Bool("rename", false, "rename the files by the rename template")
+	template := fs.String("template", cfg.RenameTemplate(), "template of the new names, with -rename")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if len(fields) == 0 && !*rename || fs.NArg() == 0 {
+		return usageError(errors.New(batchUsage))
+	}
+	if *rename && *template == "" {
+		return usageError(errors.New("-rename needs a rename template"))
+	}
+	if len(fields) > 0 {
+		for field, value := range map[string]string{"Producer": cfg.Producer(), "Author": cfg.Author()} {
+			if _, ok := fields[field]; !ok && value != "" {
+				fields[field] = value
+			}
+		}
+	}
+
+	files, err := pdfFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	svc := newMetadataService()
+	renamer := newRenamer(*template)
+	return forEachFile(files, func(path string) error {
+		if len(fields) > 0 {
+			if err := svc.UpdateMetadata(path, fields); err != nil {
+				return err
+			}
+		}
+		newPath := path
+		if *rename {
+			var err error
+			if newPath, err = renamer.rename(path); err != nil {
+				return err
+			}
+		}
+		if out != nil {
+			return out.Result(path, struct {
+				Fields map[string]string `json:"fields"`
+				Path   string            `json:"path"`
+			}{fields, newPath})
+		}
+		var done []string
+		if len(fields) > 0 {
+			done = append(done, "updated "+strings.Join(slices.Sorted(maps.Keys(fields)), ", "))
+		}
+		if *rename {
+			done = append(done, "renamed to "+newPath)
+		}
+		fmt.Printf("%s: %s\n", path, strings.Join(done, "; "))
+		return nil
+	})
+}
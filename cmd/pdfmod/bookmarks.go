@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const bookmarksUsage = `Usage:
+  pdfmod bookmarks get [-format text|json] <file.pdf>
+  pdfmod bookmarks set [-format text|json] <file.pdf> <outline-file>
+
+The text format has one bookmark per line: two spaces of indentation per
+level, the target page number ("-" for none) and the title. The json
+format of get is the record of -output json; set reads it, or a plain
+array of bookmarks. An outline file of "-" reads from standard input.`
+
+func runBookmarks(args []string) error {
+	if len(args) == 0 {
+		return usageError(errors.New(bookmarksUsage))
+	}
+	outlines := pdf.NewPDFService()
+	switch args[0] {
+	case "get":
+		return runBookmarksGet(outlines, args[1:])
+	case "set":
+		return runBookmarksSet(outlines, args[1:])
+	default:
+		return usageError(fmt.Errorf("unknown bookmarks command %q\n%s", args[0], bookmarksUsage))
+	}
+}
+
+func runBookmarksGet(outlines pdf.OutlineHandler, args []string) error {
+	fs := flag.NewFlagSet("bookmarks get", flag.ContinueOnError)
+	formatFlag(fs, "bookmarks")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(bookmarksUsage))
+	}
+
+	items, err := outlines.ReadOutline(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		return out.Result(fs.Arg(0), bookmarksResult{Bookmarks: orEmpty(items)})
+	}
+	return pdf.WriteOutlineText(os.Stdout, items)
+}
+
+func runBookmarksSet(outlines pdf.OutlineHandler, args []string) error {
+	fs := flag.NewFlagSet("bookmarks set", flag.ContinueOnError)
+	format := fs.String("format", "", "input format: text or json (default: from the file extension)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 2 {
+		return usageError(errors.New(bookmarksUsage))
+	}
+	pdfPath, outlinePath := fs.Arg(0), fs.Arg(1)
+
+	var in io.Reader = os.Stdin
+	if outlinePath != "-" {
+		f, err := os.Open(outlinePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if *format == "" {
+		*format = "text"
+		if strings.EqualFold(filepath.Ext(outlinePath), ".json") {
+			*format = "json"
+		}
+	}
+
+	var items []*pdf.OutlineItem
+	var err error
+	switch *format {
+	case "text":
+		items, err = pdf.ParseOutlineText(in)
+	case "json":
+		items, err = parseOutlineJSON(in)
+	default:
+		return usageError(fmt.Errorf("unknown format %q", *format))
+	}
+	if err != nil {
+		return err
+	}
+	if err := outlines.WriteOutline(pdfPath, items); err != nil {
+		return err
+	}
+	if out != nil {
+		return out.Result(pdfPath, bookmarksResult{Bookmarks: orEmpty(items)})
+	}
+	return nil
+}
+
+// parseOutlineJSON reads bookmarks from the result record of bookmarks get,
+// or from a plain array of bookmarks.
+func parseOutlineJSON(r io.Reader) ([]*pdf.OutlineItem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return pdf.ParseOutlineJSON(bytes.NewReader(data))
+	}
+	var record struct {
+		Data bookmarksResult `json:"data"`
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid outline JSON: %w", err)
+	}
+	return record.Data.Bookmarks, nil
+}
+
+// bookmarksResult is the JSON output of the bookmarks commands.
+type bookmarksResult struct {
+	Bookmarks []*pdf.OutlineItem `json:"bookmarks"`
+}
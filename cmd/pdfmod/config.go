@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sidshirsat/pdfmod/internal/config"
+)
+
+const configUsage = `Usage:
+  pdfmod [flags] config [-format text|json]
+
+Shows the effective configuration and where each value comes from. Values
+are read, in increasing precedence, from the defaults, the user file
+$XDG_CONFIG_HOME/pdfmod/config (or ~/.config/pdfmod/config), the nearest
+.pdfmod file in the working directory or its parents, PDFMOD_* environment
+variables and the global flags. Files hold "key = value" lines:
+
+  producer = Example Corp
+  rename_template = {title} - {author}
+  collision = suffix`
+
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	formatFlag(fs, "config")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 0 {
+		return usageError(errors.New(configUsage))
+	}
+
+	result := struct {
+		Files  []string       `json:"files"`
+		Values []config.Value `json:"values"`
+	}{append([]string{}, cfg.Files...), cfg.Values()}
+	if out != nil {
+		return out.Result("", result)
+	}
+
+	if len(cfg.Files) == 0 {
+		fmt.Printf("No configuration files found (user file: %s)\n\n", config.UserFile())
+	} else {
+		fmt.Println("Configuration files:")
+		for _, path := range cfg.Files {
+			fmt.Printf("  %s\n", path)
+		}
+		fmt.Println()
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE\tENV")
+	for _, v := range cfg.Values() {
+		fmt.Fprintf(tw, "%s\t%q\t%s\t%s\n", v.Key, v.Value, v.Source, v.Env)
+	}
+	return tw.Flush()
+}
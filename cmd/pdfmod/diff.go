@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const diffUsage = `Usage:
+  pdfmod diff <old.pdf> <new.pdf>
+  pdfmod diff -revisions <file.pdf>
+
+Shows what differs between two PDFs: Info entries, XMP properties, page
+count, page sizes and rotation, outline items and attachments. Added
+entries are marked +, removed ones - and changed ones ~.
+
+With -revisions, compares each incremental update of file.pdf with the
+revision before it, oldest first.`
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	revisions := fs.Bool("revisions", false, "compare the incremental updates of one file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	var differ pdf.Differ = pdf.NewPDFService()
+	if *revisions {
+		if fs.NArg() != 1 {
+			return usageError(errors.New(diffUsage))
+		}
+		return diffRevisions(differ, fs.Arg(0))
+	}
+	if fs.NArg() != 2 {
+		return usageError(errors.New(diffUsage))
+	}
+
+	changes, err := differ.DiffFiles(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		return out.Result(fs.Arg(1), struct {
+			Old     string       `json:"old"`
+			Changes []pdf.Change `json:"changes"`
+		}{fs.Arg(0), orEmpty(changes)})
+	}
+	fmt.Printf("--- %s\n+++ %s\n", fs.Arg(0), fs.Arg(1))
+	printChanges(changes)
+	return nil
+}
+
+func diffRevisions(differ pdf.Differ, path string) error {
+	diffs, err := differ.DiffRevisions(path)
+	if err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		if out != nil {
+			d.Changes = orEmpty(d.Changes)
+			if err := out.Result(path, d); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Printf("revision %d -> %d (%d bytes)\n", d.From, d.To, d.Size)
+		printChanges(d.Changes)
+	}
+	if out != nil {
+		return out.Summary(struct {
+			Revisions int `json:"revisions"`
+		}{len(diffs) + 1})
+	}
+	if len(diffs) == 0 {
+		fmt.Printf("%s has no incremental updates.\n", path)
+	}
+	return nil
+}
+
+// printChanges lists changes under a heading for each section.
+func printChanges(changes []pdf.Change) {
+	if len(changes) == 0 {
+		fmt.Println("  no differences")
+		return
+	}
+	section := ""
+	for _, c := range changes {
+		if c.Section != section {
+			section = c.Section
+			fmt.Printf("  %s:\n", section)
+		}
+		switch c.Kind {
+		case pdf.ChangeAdded:
+			fmt.Printf("    + %s: %s\n", c.Key, c.New)
+		case pdf.ChangeRemoved:
+			fmt.Printf("    - %s: %s\n", c.Key, c.Old)
+		default:
+			fmt.Printf("    ~ %s: %s -> %s\n", c.Key, c.Old, c.New)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sidshirsat/pdfmod/internal/manager"
+)
+
+const dupesUsage = `Usage:
+  pdfmod dupes [-r] [-exact] [-delete | -link] <dir|file.pdf>...
+
+Groups PDFs with the same content: files identical byte for byte, and
+files that differ only in their Info dictionary, XMP metadata, file
+identifier or incremental updates. Files that are not readable PDFs are
+only compared byte for byte, and with -exact every file is. A file named
+more than once, or through several hard links, counts once.
+
+In each group the file with the most metadata is suggested as the keeper,
+then the oldest, then the one with the shortest path. -delete removes the
+other files of each group and -link replaces them with hard links to the
+keeper, which keeps their paths working; both lose the metadata of the
+replaced files.`
+
+func runDupes(args []string) error {
+	fs := flag.NewFlagSet("dupes", flag.ContinueOnError)
+	recursive := fs.Bool("r", false, "include the PDFs in subdirectories")
+	exact := fs.Bool("exact", false, "only group files that are identical byte for byte")
+	remove := fs.Bool("delete", false, "delete the duplicates")
+	link := fs.Bool("link", false, "replace the duplicates with hard links to the keeper")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageError(errors.New(dupesUsage))
+	}
+	if *remove && *link {
+		return usageError(errors.New("-delete and -link cannot be combined"))
+	}
+
+	files, err := pdfFiles(fs.Args())
+	if *recursive {
+		files, err = pdfFilesRecursive(fs.Args())
+	}
+	if err != nil {
+		return err
+	}
+	svc := newMetadataService()
+	finder := manager.NewDuplicateFinder(svc, svc)
+	finder.BytesOnly = *exact
+	groups, errs := finder.Find(files)
+	for _, err := range errs {
+		if out == nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else if err := out.Error(err.Path, err.Err); err != nil {
+			return err
+		}
+	}
+
+	failed := len(errs)
+	duplicates, size := 0, int64(0)
+	for _, group := range groups {
+		duplicates += len(group.Duplicates)
+		for _, dup := range group.Duplicates {
+			size += dup.Size
+		}
+		if out == nil {
+			printDuplicateGroup(group)
+		} else if err := out.Result(group.Keeper.Path, group); err != nil {
+			return err
+		}
+		if *remove || *link {
+			failed += removeDuplicates(group, *link)
+		}
+	}
+
+	if out == nil {
+		if len(groups) == 0 {
+			fmt.Println("No duplicates found.")
+		} else {
+			fmt.Printf("%d groups, %d duplicates, %d bytes\n", len(groups), duplicates, size)
+		}
+	} else if err := out.Summary(struct {
+		Groups     int   `json:"groups"`
+		Duplicates int   `json:"duplicates"`
+		Bytes      int64 `json:"bytes"`
+	}{len(groups), duplicates, size}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return failedError("%d files could not be compared or replaced", failed)
+	}
+	return nil
+}
+
+func printDuplicateGroup(group manager.DuplicateGroup) {
+	fmt.Printf("%s (keep: %s)\n", group.Keeper.Path, group.Reason)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, dup := range group.Duplicates {
+		match := "same bytes"
+		if dup.Match == manager.MatchContent {
+			match = "same content"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", dup.Path, match)
+	}
+	tw.Flush()
+}
+
+// removeDuplicates deletes or links the duplicates of group and returns the
+// number that failed.
+func removeDuplicates(group manager.DuplicateGroup, link bool) int {
+	action := "deleted"
+	if link {
+		action = "linked"
+	}
+	failed := 0
+	for _, dup := range group.Duplicates {
+		err := manager.RemoveDuplicate(group.Keeper.Path, dup.Path, link)
+		if err != nil {
+			failed++
+		}
+		switch {
+		case out != nil && err != nil:
+			out.Error(dup.Path, err)
+		case out != nil:
+			out.Result(dup.Path, struct {
+				Action string `json:"action"`
+				Keeper string `json:"keeper"`
+			}{action, group.Keeper.Path})
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+		default:
+			fmt.Printf("  %s %s\n", action, dup.Path)
+		}
+	}
+	return failed
+}
+
+// pdfFilesRecursive is pdfFiles including the PDFs in subdirectories.
+// Hidden directories are skipped.
+func pdfFilesRecursive(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != root && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if path == root || strings.EqualFold(filepath.Ext(path), ".pdf") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
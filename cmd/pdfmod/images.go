@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const imagesUsage = `Usage:
+  pdfmod images [-pages SELECTION] [-format text|json] [-extract DIR] <file.pdf>
+
+Lists the images used by each page with their size, color space, bits per
+component, filter and encoded byte size. With -extract, each image is also
+written to DIR: JPEG data as .jpg, other images as .png with soft masks
+turned into transparency.`
+
+func runImages(args []string) error {
+	fs := flag.NewFlagSet("images", flag.ContinueOnError)
+	selection := fs.String("pages", "", "pages to list (default: all)")
+	formatFlag(fs, "images")
+	outDir := fs.String("extract", "", "directory to extract the images to")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(imagesUsage))
+	}
+
+	var handler pdf.ImageHandler = pdf.NewPDFService()
+	if *outDir != "" {
+		return extractImages(handler, fs.Arg(0), *selection, *outDir)
+	}
+	images, err := handler.ListImages(fs.Arg(0), *selection)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		return writeImageRecords(fs.Arg(0), images)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PAGE\tNAME\tOBJECT\tSIZE\tCOLORSPACE\tBPC\tFILTER\tBYTES\tSMASK")
+	for _, img := range images {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%dx%d\t%s\t%d\t%s\t%d\t%s\n", img.Page, img.Name, img.Object,
+			img.Width, img.Height, img.ColorSpace, img.BitsPerComponent, img.Filter, img.Size, yesNo(img.SMask))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	count, total := imageTotals(images)
+	fmt.Printf("%d images, %d bytes\n", count, total)
+	return nil
+}
+
+// imageTotals returns the number of distinct images and their total size.
+// An image used on several pages counts once.
+func imageTotals(images []pdf.ImageInfo) (count, total int) {
+	counted := map[int]bool{}
+	for _, img := range images {
+		if !counted[img.Object] {
+			counted[img.Object] = true
+			total += img.Size
+		}
+	}
+	return len(counted), total
+}
+
+func writeImageRecords(path string, images []pdf.ImageInfo) error {
+	for _, img := range images {
+		if err := out.Result(path, img); err != nil {
+			return err
+		}
+	}
+	count, total := imageTotals(images)
+	return out.Summary(struct {
+		Images int `json:"images"`
+		Bytes  int `json:"bytes"`
+	}{count, total})
+}
+
+func extractImages(handler pdf.ImageHandler, path, selection, outDir string) error {
+	extracted, err := handler.ExtractImages(path, selection, outDir)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, img := range extracted {
+		if img.Error != "" {
+			failed++
+			message := fmt.Sprintf("page %d, object %d: %s", img.Page, img.Object, img.Error)
+			if out == nil {
+				fmt.Fprintln(os.Stderr, message)
+			} else if err := out.Error(path, failedError("%s", message)); err != nil {
+				return err
+			}
+			continue
+		}
+		if out == nil {
+			fmt.Println(img.Path)
+		} else if err := out.Result(path, img); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return failedError("%d of %d images could not be extracted", failed, len(extracted))
+	}
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	pdflib "github.com/sidshirsat/pdfmod/pdf"
+)
+
+const infoUsage = `Usage:
+  pdfmod info <file.pdf|dir>...
+
+Prints the PDF version, page count, size and metadata of each file.`
+
+// fileInfo is the result of info for a file.
+type fileInfo struct {
+	Version  string            `json:"version"`
+	Pages    int               `json:"pages"`
+	Size     int64             `json:"size"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func runInfo(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageError(errors.New(infoUsage))
+	}
+
+	files, err := pdfFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	return forEachFile(files, func(path string) error {
+		info, err := readFileInfo(path)
+		if err != nil {
+			return err
+		}
+		if out != nil {
+			return out.Result(path, info)
+		}
+		fmt.Println(path)
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "  Version\t%s\n", info.Version)
+		fmt.Fprintf(tw, "  Pages\t%d\n", info.Pages)
+		fmt.Fprintf(tw, "  Size\t%d bytes\n", info.Size)
+		for _, key := range slices.Sorted(maps.Keys(info.Metadata)) {
+			fmt.Fprintf(tw, "  %s\t%s\n", key, info.Metadata[key])
+		}
+		return tw.Flush()
+	})
+}
+
+func readFileInfo(path string) (*fileInfo, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := pdflib.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+	pages, err := doc.Pages()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := doc.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{Version: doc.Version(), Pages: len(pages), Size: stat.Size(), Metadata: fields}, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const labelsUsage = `Usage:
+  pdfmod labels get [-list] [-pages SELECTION] <file.pdf>
+  pdfmod labels set <file.pdf> <RANGES>
+
+RANGES is a comma separated list of PAGE:STYLE[:PREFIX[:START]] where STYLE
+is D (1, 2), R (I, II), r (i, ii), A (A, B), a (a, b) or - (prefix only),
+for example "1:r,5:D,21:D:A-". Use "none" to remove page labels.
+
+SELECTION accepts page numbers, labels and ranges such as "i-iv,A-1", with
+"#N" always meaning the Nth physical page.`
+
+func runLabels(args []string) error {
+	if len(args) == 0 {
+		return usageError(errors.New(labelsUsage))
+	}
+	labels := pdf.NewPDFService()
+	switch args[0] {
+	case "get":
+		return runLabelsGet(labels, args[1:])
+	case "set":
+		return runLabelsSet(labels, args[1:])
+	default:
+		return usageError(fmt.Errorf("unknown labels command %q\n%s", args[0], labelsUsage))
+	}
+}
+
+func runLabelsGet(handler pdf.PageLabelHandler, args []string) error {
+	fs := flag.NewFlagSet("labels get", flag.ContinueOnError)
+	list := fs.Bool("list", false, "print the label of every page instead of the ranges")
+	selection := fs.String("pages", "", "only list the selected pages (implies -list)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(labelsUsage))
+	}
+
+	ranges, labels, err := handler.ReadPageLabels(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if !*list && *selection == "" {
+		if out != nil {
+			return out.Result(fs.Arg(0), labelsResult{Ranges: orEmpty(ranges)})
+		}
+		fmt.Println(pdf.FormatPageLabelRanges(ranges))
+		return nil
+	}
+
+	pages := make([]int, len(labels))
+	for i := range pages {
+		pages[i] = i + 1
+	}
+	if *selection != "" {
+		pages, err = pdf.ParsePageSelection(*selection, labels)
+		if err != nil {
+			return err
+		}
+	}
+	for _, page := range pages {
+		if out == nil {
+			fmt.Fprintf(os.Stdout, "%d\t%s\n", page, labels[page-1])
+			continue
+		}
+		result := struct {
+			Page  int    `json:"page"`
+			Label string `json:"label"`
+		}{page, labels[page-1]}
+		if err := out.Result(fs.Arg(0), result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelsResult is the JSON output of the page label ranges of a file.
+type labelsResult struct {
+	Ranges []pdf.PageLabelRange `json:"ranges"`
+}
+
+func runLabelsSet(handler pdf.PageLabelHandler, args []string) error {
+	if len(args) != 2 {
+		return usageError(errors.New(labelsUsage))
+	}
+	ranges, err := pdf.ParsePageLabelRanges(args[1])
+	if err != nil {
+		return usageError(err)
+	}
+	if err := handler.WritePageLabels(args[0], ranges); err != nil {
+		return err
+	}
+	if out != nil {
+		return out.Result(args[0], labelsResult{Ranges: orEmpty(ranges)})
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	pdflib "github.com/sidshirsat/pdfmod/pdf"
+)
+
+// metadataService is the PDF service of the commands that read and write
+// metadata. Metadata is read and written through the public pdf package, so
+// that the command line tool and library users see and write the same
+// fields. Verifying updates and the other features, such as validation,
+// come from the embedded service.
+type metadataService struct {
+	*pdf.PDFService
+}
+
+func newMetadataService() *metadataService {
+	service := pdf.NewPDFService()
+	service.Logger = logger
+	service.ReadFields = pdflib.ReadMetadata
+	service.WriteFields = pdflib.WriteMetadata
+	return &metadataService{PDFService: service}
+}
+
+var (
+	_ pdf.PDFMetadataHandler = &metadataService{}
+	_ pdf.InfoHandler        = &metadataService{}
+)
+
+// ReadInfo returns the Info entries of the PDF file.
+func (s *metadataService) ReadInfo(filePath string) (map[string]string, error) {
+	fields, err := pdflib.ReadMetadata(filePath)
+	if err != nil {
+		return nil, err
+	}
+	maps.DeleteFunc(fields, func(key, _ string) bool { return strings.Contains(key, ":") })
+	return fields, nil
+}
+
+// WriteInfo updates Info entries of the PDF file, which are mirrored into
+// the XMP metadata of files that claim PDF/A conformance.
+func (s *metadataService) WriteInfo(filePath string, fields map[string]string) error {
+	return pdflib.WriteMetadata(filePath, fields)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pdflib "github.com/sidshirsat/pdfmod/pdf"
+)
+
+func TestMetadataService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(path, reportPDF(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	svc := newMetadataService()
+	if err := svc.UpdateMetadata(path, map[string]string{"Title": "Annual Report", "Producer": "Team Corp"}); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+	if err := svc.WriteMetadata(path, map[string]string{"dc:creator": "Ada"}); err != nil {
+		t.Fatalf("WriteMetadata failed: %v", err)
+	}
+
+	// The library reads what the commands wrote.
+	fields, err := pdflib.ReadMetadata(path)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if fields["Title"] != "Annual Report" || fields["Producer"] != "Team Corp" || fields["dc:creator"] != "Ada" {
+		t.Errorf("Unexpected metadata %v", fields)
+	}
+	info, err := svc.ReadInfo(path)
+	if err != nil {
+		t.Fatalf("ReadInfo failed: %v", err)
+	}
+	if _, ok := info["dc:creator"]; ok || info["Title"] != "Annual Report" {
+		t.Errorf("Expected only Info entries, got %v", info)
+	}
+}
@@ -1,31 +1,210 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
 
+	"github.com/sidshirsat/pdfmod/internal/config"
 	"github.com/sidshirsat/pdfmod/internal/file"
 	"github.com/sidshirsat/pdfmod/internal/manager"
-	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/output"
+	"github.com/sidshirsat/pdfmod/internal/tui"
 	"github.com/sidshirsat/pdfmod/internal/utils"
 )
 
+// command is a pdfmod subcommand. Running pdfmod without a command starts
+// the interactive flow.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"batch", "edit the metadata of PDFs without prompting", runBatch},
+	{"bookmarks", "read or replace the document outline", runBookmarks},
+	{"config", "show the effective configuration", runConfig},
+	{"diff", "compare the metadata and structure of PDFs or revisions", runDiff},
+	{"dupes", "find PDFs with the same content", runDupes},
+	{"images", "list and extract embedded images", runImages},
+	{"info", "show the version, pages, size and metadata of PDFs", runInfo},
+	{"labels", "read or replace page labels", runLabels},
+	{"merge", "combine the pages of several PDFs", runMerge},
+	{"meta", "read metadata and export and import manifests", runMeta},
+	{"optimize", "reduce the file size", runOptimize},
+	{"rename", "rename PDFs by the rename template", runRename},
+	{"repair", "rebuild damaged cross-reference data", runRepair},
+	{"rules", "apply metadata rules from a file", runRules},
+	{"serve", "serve a local HTTP API", runServe},
+	{"split", "write page ranges to separate PDFs", runSplit},
+	{"text", "extract the plain text of pages", runText},
+	{"title", "suggest titles for PDFs without one", runTitle},
+	{"validate", "check the structure of PDFs", runValidate},
+	{"watch", "process PDFs added to a directory", runWatch},
+}
+
 func main() {
-	// Initialize services
+	if err := run(os.Args[1:]); err != nil {
+		reportError(err)
+		os.Exit(1)
+	}
+}
+
+// cfg holds the settings from configuration files, the environment and the
+// global flags.
+var cfg *config.Config
+
+// logger writes log messages to stderr at the configured level and format.
+var logger *slog.Logger
+
+func run(args []string) error {
+	out = nil
+	fs := flag.NewFlagSet("pdfmod", flag.ContinueOnError)
+	fs.Usage = usage
+	flags := map[string]*string{}
+	for _, v := range config.Defaults() {
+		flags[v.Key] = fs.String(flagName(v.Key), "", v.Help)
+	}
+	quiet := fs.Bool("quiet", false, "only log errors")
+	verbose := fs.Bool("verbose", false, "log details, including metadata values")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	args = fs.Args()
 
-	pdfMetadataHandler := pdf.NewPDFService()
-	basePrompter := &utils.BasePrompter{}
+	var err error
+	if cfg, err = config.Load(config.Options{}); err != nil {
+		return err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if key := strings.ReplaceAll(f.Name, "-", "_"); err == nil && flags[key] != nil {
+			err = cfg.Set(key, *flags[key], config.SourceFlag+" -"+f.Name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	switch {
+	case *quiet && *verbose:
+		return usageError(errors.New("-quiet and -verbose cannot be combined"))
+	case *quiet:
+		err = cfg.Set(config.KeyLogLevel, "error", config.SourceFlag+" -quiet")
+	case *verbose:
+		err = cfg.Set(config.KeyLogLevel, "debug", config.SourceFlag+" -verbose")
+	}
+	if err != nil {
+		return err
+	}
+	if logger, err = utils.NewLogger(os.Stderr, cfg.LogLevel(), cfg.LogFormat()); err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	utils.SetColorEnabled(utils.ColorEnabled(cfg.Color(), os.Stdout, os.Getenv))
+	jsonOutput := cfg.Output() == "json"
+	if jsonOutput {
+		out = output.NewWriter(stdout, "")
+	}
+
+	if len(args) == 0 {
+		if jsonOutput {
+			return usageError(errors.New("-output json needs a command"))
+		}
+		return runInteractive()
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			if jsonOutput {
+				out = output.NewWriter(stdout, cmd.name)
+			}
+			return cmd.run(args[1:])
+		}
+	}
+	if !jsonOutput {
+		usage()
+	}
+	return usageError(fmt.Errorf("unknown command %q", args[0]))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: pdfmod [flags] [command] [arguments]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command, pdfmod runs interactively on the PDFs in ./pdf_files.")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nFlags, which override the configuration (see pdfmod config):")
+	for _, v := range config.Defaults() {
+		fmt.Fprintf(os.Stderr, "  -%-16s %s\n", flagName(v.Key), v.Help)
+	}
+	fmt.Fprintf(os.Stderr, "  -%-16s %s\n", "quiet", "only log errors, as -log-level error")
+	fmt.Fprintf(os.Stderr, "  -%-16s %s\n", "verbose", "log details, including metadata values, as -log-level debug")
+	fmt.Fprintln(os.Stderr, "\nWith -output json, commands write result, error and summary records to stdout,")
+	fmt.Fprintln(os.Stderr, "one JSON object per line. Errors have a stable code, such as not_found or not_pdf.")
+}
+
+// flagName returns the global flag of a setting, such as -rename-template.
+func flagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+func runInteractive() error {
+	// Initialize services
+	pdfMetadataHandler := newMetadataService()
+	// Ctrl-C cancels the pending prompt instead of killing the process.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	basePrompter := &utils.BasePrompter{Interrupt: interrupt}
 	prompter := &utils.ConsolePrompter{
 		Prompter: basePrompter,
 	}
 	fileHandler := &file.FilePickerService{
-		Prompter: prompter,
+		Prompter:  prompter,
+		Collision: cfg.Collision(),
+		Sort:      cfg.Sort(),
 	}
 	// Initialize PDF Manager
 	pdfManager := manager.NewPDFManager(fileHandler, pdfMetadataHandler, prompter)
+	pdfManager.TitleSuggester = manager.NewTitleSuggester(pdfMetadataHandler, pdfMetadataHandler, pdfMetadataHandler)
+	pdfManager.Defaults = manager.Defaults{
+		WorkDir:        cfg.WorkDir(),
+		Producer:       cfg.Producer(),
+		Author:         cfg.Author(),
+		RenameTemplate: cfg.RenameTemplate(),
+	}
+
+	// Use the full-screen interface on terminals unless line prompts are
+	// configured.
+	terminal := tui.IsTerminal(os.Stdin) && tui.IsTerminal(os.Stdout)
+	if cfg.UI() == "tui" && !terminal {
+		return errors.New("the terminal UI needs standard input and output to be a terminal")
+	}
+	if cfg.UI() != "line" && terminal {
+		dir, err := pdfManager.WorkDir()
+		if err != nil {
+			return err
+		}
+		model, err := tui.NewModel(fileHandler, pdfMetadataHandler, dir, pdfManager.Defaults, cfg.Sort())
+		if err != nil {
+			return err
+		}
+		return tui.Run(os.Stdin, os.Stdout, model)
+	}
 
 	// Execute the manager operation
 	err := pdfManager.Execute()
-	if err != nil {
-		log.Fatalf("Error: %v", err)
+	if errors.Is(err, utils.ErrInterrupted) || errors.Is(err, utils.ErrInputClosed) {
+		fmt.Println("Cancelled.")
+		return nil
 	}
+	return err
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const mergeUsage = `Usage:
+  pdfmod merge -o OUTPUT <file.pdf>...
+
+Writes the pages of the files, in order, to OUTPUT. The document
+information is taken from the first file; bookmarks, forms and page labels
+are not carried over.`
+
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	output := fs.String("o", "", "write the merged file to OUTPUT")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 || *output == "" {
+		return usageError(errors.New(mergeUsage))
+	}
+
+	var assembler pdf.PageAssembler = pdf.NewPDFService()
+	if err := assembler.Merge(fs.Args(), *output); err != nil {
+		return err
+	}
+	if out != nil {
+		return out.Result(*output, struct {
+			Inputs []string `json:"inputs"`
+		}{fs.Args()})
+	}
+	fmt.Printf("Merged %d files into %s\n", fs.NArg(), *output)
+	return nil
+}
+
+const splitUsage = `Usage:
+  pdfmod split [-o DIR] <file.pdf> [PAGES...]
+
+Writes each page selection, such as 1-3 or 4-, to its own file named after
+the input with the part number appended: report-1.pdf, report-2.pdf, ...
+Without selections every page becomes a file. Parts are written next to the
+input unless -o is given.`
+
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ContinueOnError)
+	outDir := fs.String("o", "", "write the parts to DIR")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageError(errors.New(splitUsage))
+	}
+
+	var assembler pdf.PageAssembler = pdf.NewPDFService()
+	paths, err := assembler.Split(fs.Arg(0), *outDir, fs.Args()[1:])
+	for _, path := range paths {
+		if out == nil {
+			fmt.Println(path)
+			continue
+		}
+		result := struct {
+			Part string `json:"part"`
+		}{path}
+		if err := out.Result(fs.Arg(0), result); err != nil {
+			return err
+		}
+	}
+	return err
+}
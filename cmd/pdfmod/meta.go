@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	pdflib "github.com/sidshirsat/pdfmod/pdf"
+)
+
+const metaUsage = `Usage:
+  pdfmod meta get [-field NAME]... <file.pdf|dir>...
+  pdfmod meta export [-format csv|json] <dir> > meta.csv
+  pdfmod meta import [-format csv|json] [-dir DIR] <meta.csv>
+
+get prints the Info entries and XMP properties of each file, or only the
+fields named with -field. A single field of a single file is printed
+without its name.
+
+export writes the path, SHA-256 hash and every Info entry and XMP property
+of the PDFs in dir. XMP properties are named like dc:title.
+
+import applies an edited manifest. Rows are matched to files by path, or
+else by hash among the PDFs in -dir or the directory of the row's path.
+Only changed fields are written; an empty value removes the field. The
+format defaults to json for .json files and csv otherwise.`
+
+func runMeta(args []string) error {
+	if len(args) == 0 {
+		return usageError(errors.New(metaUsage))
+	}
+	switch args[0] {
+	case "get":
+		return runMetaGet(args[1:])
+	case "export":
+		return runMetaExport(args[1:])
+	case "import":
+		return runMetaImport(args[1:])
+	}
+	return usageError(errors.New(metaUsage))
+}
+
+func newMetadataManifest() *manager.MetadataManifest {
+	return manager.NewMetadataManifest(&file.FilePickerService{}, newMetadataService())
+}
+
+func runMetaGet(args []string) error {
+	fs := flag.NewFlagSet("meta get", flag.ContinueOnError)
+	var names []string
+	fs.Func("field", "only print the field NAME, such as Title or dc:title (repeatable)", func(name string) error {
+		names = append(names, name)
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageError(errors.New(metaUsage))
+	}
+
+	files, err := pdfFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	return forEachFile(files, func(path string) error {
+		fields, err := pdflib.ReadMetadata(path)
+		if err != nil {
+			return err
+		}
+		if names != nil {
+			fields = pickFields(fields, names)
+		}
+		if out != nil {
+			return out.Result(path, struct {
+				Fields map[string]string `json:"fields"`
+			}{fields})
+		}
+		if len(files) == 1 && len(names) == 1 {
+			fmt.Println(fields[names[0]])
+			return nil
+		}
+		indent := ""
+		if len(files) > 1 {
+			fmt.Println(path)
+			indent = "  "
+		}
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			fmt.Printf("%s%s: %s\n", indent, name, fields[name])
+		}
+		return nil
+	})
+}
+
+// pickFields returns the fields named in names that are set.
+func pickFields(fields map[string]string, names []string) map[string]string {
+	picked := map[string]string{}
+	for _, name := range names {
+		if value, ok := fields[name]; ok {
+			picked[name] = value
+		}
+	}
+	return picked
+}
+
+func runMetaExport(args []string) error {
+	fs := flag.NewFlagSet("meta export", flag.ContinueOnError)
+	format := fs.String("format", "csv", "output format: csv or json")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(metaUsage))
+	}
+	manifest, err := newMetadataManifest().Export(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		for _, row := range manifest.Rows {
+			result := struct {
+				SHA256 string            `json:"sha256"`
+				Fields map[string]string `json:"fields"`
+			}{row.SHA256, row.Fields}
+			if err := out.Result(row.Path, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch *format {
+	case "csv":
+		return manifest.WriteCSV(os.Stdout)
+	case "json":
+		return manifest.WriteJSON(os.Stdout)
+	}
+	return usageError(fmt.Errorf("unknown format %q", *format))
+}
+
+func runMetaImport(args []string) error {
+	fs := flag.NewFlagSet("meta import", flag.ContinueOnError)
+	format := fs.String("format", "", "manifest format: csv or json")
+	dir := fs.String("dir", "", "directory to search for files matched by hash")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(metaUsage))
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = "csv"
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			*format = "json"
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var read func(io.Reader) (*manager.Manifest, error)
+	switch *format {
+	case "csv":
+		read = manager.ReadCSV
+	case "json":
+		read = manager.ReadJSON
+	default:
+		return usageError(fmt.Errorf("unknown format %q", *format))
+	}
+	manifest, err := read(f)
+	if err != nil {
+		return err
+	}
+
+	updated, failed := 0, 0
+	for _, result := range newMetadataManifest().Import(manifest, *dir) {
+		if result.Error != "" {
+			failed++
+		} else if len(result.Changed) > 0 {
+			updated++
+		}
+		if out != nil {
+			if err := writeImportRecord(result); err != nil {
+				return err
+			}
+			continue
+		}
+		switch {
+		case result.Error != "":
+			fmt.Fprintf(os.Stderr, "row %d: %s: %s\n", result.Row, result.Path, result.Error)
+		case len(result.Changed) > 0:
+			via := ""
+			if result.Match == manager.MatchSHA256 {
+				via = " (matched by hash)"
+			}
+			fmt.Printf("%s%s: updated %s\n", result.Path, via, strings.Join(result.Changed, ", "))
+		}
+	}
+	summary := struct {
+		Rows    int `json:"rows"`
+		Updated int `json:"updated"`
+		Failed  int `json:"failed"`
+	}{len(manifest.Rows), updated, failed}
+	if out == nil {
+		fmt.Printf("%d of %d files updated\n", updated, len(manifest.Rows))
+	} else if err := out.Summary(summary); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return failedError("%d of %d rows failed", failed, len(manifest.Rows))
+	}
+	return nil
+}
+
+func writeImportRecord(result manager.ImportResult) error {
+	if result.Error != "" {
+		return out.Error(result.Path, failedError("row %d: %s", result.Row, result.Error))
+	}
+	return out.Result(result.Path, struct {
+		Row     int      `json:"row"`
+		Match   string   `json:"match"`
+		Changed []string `json:"changed"`
+	}{result.Row, result.Match, orEmpty(result.Changed)})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const optimizeUsage = `Usage:
+  pdfmod optimize [-o OUTPUT] [-object-streams=false] [-dpi DPI] [-jpeg-quality Q]
+                  [-title TITLE] [-producer PRODUCER] <file.pdf>
+
+Rewrites the file without unused objects, merging identical streams and
+fonts and recompressing streams, and reports the size before and after.
+The file is replaced unless -o is given. -title and -producer update the
+metadata in the same write. Otherwise, when the optimized file would not be
+smaller, the original is kept, or copied to OUTPUT.
+
+With -dpi or -jpeg-quality, images are re-encoded as JPEG, after
+downsampling those shown at more than DPI pixels per inch, and the savings
+are listed per page.`
+
+func runOptimize(args []string) error {
+	fs := flag.NewFlagSet("optimize", flag.ContinueOnError)
+	output := fs.String("o", "", "write the optimized file to OUTPUT")
+	objectStreams := fs.Bool("object-streams", true, "pack objects into object streams (requires PDF 1.5)")
+	title := fs.String("title", "", "set the document title")
+	producer := fs.String("producer", "", "set the document producer")
+	dpi := fs.Float64("dpi", 0, "downsample images shown above this resolution")
+	quality := fs.Int("jpeg-quality", 0, fmt.Sprintf("re-encode images as JPEG at this quality (default %d with -dpi)", pdf.DefaultJPEGQuality))
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(optimizeUsage))
+	}
+
+	opts := pdf.OptimizeOptions{ObjectStreams: *objectStreams, Info: map[string]string{}}
+	if *title != "" {
+		opts.Info["Title"] = *title
+	}
+	if *producer != "" {
+		opts.Info["Producer"] = *producer
+	}
+	if *dpi > 0 || *quality > 0 {
+		opts.Images = &pdf.ImageCompression{DPI: *dpi, Quality: *quality}
+		if *quality == 0 {
+			opts.Images.Quality = pdf.DefaultJPEGQuality
+		}
+	}
+	var optimizer pdf.Optimizer = pdf.NewPDFService()
+	report, err := optimizer.Optimize(fs.Arg(0), *output, opts)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		return out.Result(fs.Arg(0), report)
+	}
+
+	if report.Unchanged {
+		fmt.Printf("Size: %d bytes, unchanged: optimizing did not make the file smaller\n", report.SizeBefore)
+		return nil
+	}
+	saved := report.SizeBefore - report.SizeAfter
+	percent := 0.0
+	if report.SizeBefore > 0 {
+		percent = float64(saved) / float64(report.SizeBefore) * 100
+	}
+	fmt.Printf("Size: %d -> %d bytes (%.1f%% smaller)\n", report.SizeBefore, report.SizeAfter, percent)
+	fmt.Printf("Objects: %d -> %d (%d unused, %d merged)\n", report.ObjectsBefore, report.ObjectsAfter, report.UnusedObjects, report.MergedObjects)
+	fmt.Printf("Recompressed streams: %d\n", report.RecompressedStreams)
+	if opts.Images == nil {
+		return nil
+	}
+
+	if len(report.Pages) == 0 {
+		fmt.Println("No images could be made smaller.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "PAGE\tIMAGES\tBEFORE\tAFTER\tSAVED\t")
+	for _, page := range report.Pages {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t\n", page.Page, page.Images, page.SizeBefore, page.SizeAfter, page.SizeBefore-page.SizeAfter)
+	}
+	return tw.Flush()
+}
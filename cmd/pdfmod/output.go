@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sidshirsat/pdfmod/internal/output"
+)
+
+// stdout receives the records of commands with -output json.
+var stdout io.Writer = os.Stdout
+
+// out writes the results of the running command as JSON lines with
+// -output json, and is nil otherwise.
+var out *output.Writer
+
+// reportError writes the error a command failed with as an error record
+// with -output json, and as a message on stderr otherwise.
+func reportError(err error) {
+	if out != nil && out.Error("", err) == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+}
+
+// usageError reports a command called with invalid arguments.
+func usageError(err error) error {
+	return output.WithCode(output.CodeUsage, err)
+}
+
+// failedError reports a command that failed for some of its files, which
+// have error records of their own.
+func failedError(format string, args ...any) error {
+	return output.WithCode(output.CodeFailed, fmt.Errorf(format, args...))
+}
+
+// forEachFile runs fn on each file. The files fn fails for are reported
+// with an error record, or on stderr, and make the command fail once every
+// file was processed.
+func forEachFile(files []string, fn func(path string) error) error {
+	failed := 0
+	for _, path := range files {
+		if err := fn(path); err != nil {
+			failed++
+			if out == nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			} else if err := out.Error(path, err); err != nil {
+				return err
+			}
+		}
+	}
+	if failed > 0 {
+		return failedError("%d of %d files failed", failed, len(files))
+	}
+	return nil
+}
+
+// formatFlag defines the -format flag of the commands that had a JSON
+// output of their own before -output json: -format json now selects the
+// records of -output json.
+func formatFlag(fs *flag.FlagSet, command string) {
+	fs.Func("format", "output format: text, or json as with -output json", func(value string) error {
+		switch value {
+		case "text":
+			return nil
+		case "json":
+			if out == nil {
+				out = output.NewWriter(stdout, command)
+			}
+			return nil
+		}
+		return fmt.Errorf("unknown format %q", value)
+	})
+}
+
+// orEmpty returns s, or an empty slice instead of nil, so that results always
+// hold JSON arrays.
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
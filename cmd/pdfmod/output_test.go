@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/config"
+	"github.com/sidshirsat/pdfmod/internal/output"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the JSON output")
+
+// reportPDF returns a one-page PDF with a title and a producer.
+func reportPDF() []byte {
+	return pdftest.BuildWithTrailer("/Info 4 0 R ",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Title (Quarterly Report) /Producer (Example Corp) >>",
+	)
+}
+
+// runJSON runs pdfmod with -output json in a directory holding report.pdf,
+// broken.pdf, reports/report.pdf, a rules file and a metadata manifest, and
+// returns what it wrote to stdout. The files have a fixed modification time.
+func runJSON(t *testing.T, args ...string) string {
+	t.Helper()
+	return runIn(t, append([]string{"-output", "json"}, args...)...)
+}
+
+// runIn runs pdfmod with errors logged only, in the directory runJSON
+// describes, and returns what it wrote to stdout.
+func runIn(t *testing.T, args ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"report.pdf":         reportPDF(),
+		"broken.pdf":         []byte("not a PDF"),
+		"reports/report.pdf": reportPDF(),
+		"rules.json":         []byte(`{"rules": [{"name": "subject", "filename": "^report", "set": {"Subject": "Finance"}}]}`),
+		"meta.csv":           []byte("path,Title\nreport.pdf,Annual Report\nmissing.pdf,Lost\n"),
+	}
+	mtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Mkdir(filepath.Join(dir, "reports"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filepath.Join(dir, name), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only the defaults apply, whatever the environment of the test.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, v := range config.Defaults() {
+		t.Setenv(v.Env, "")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+	if err := run(append([]string{"-log-level", "error"}, args...)); err != nil {
+		reportError(err)
+	}
+	return buf.String()
+}
+
+func TestOutputJSON_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"info", []string{"info", "report.pdf", "broken.pdf"}},
+		{"meta-get", []string{"meta", "get", "report.pdf", "reports"}},
+		{"rename", []string{"rename", "-template", "{title} ({producer})", "report.pdf", "broken.pdf"}},
+		{"batch", []string{"batch", "-set", "Subject=Finance", "-rename", "-template", "{subject} {name}", "report.pdf", "broken.pdf"}},
+		{"validate", []string{"validate", "report.pdf", "broken.pdf"}},
+		{"diff", []string{"diff", "report.pdf", "reports/report.pdf"}},
+		{"meta-export", []string{"meta", "export", "reports"}},
+		{"meta-import", []string{"meta", "import", "meta.csv"}},
+		{"rules", []string{"rules", "-f", "rules.json", "report.pdf", "broken.pdf"}},
+		{"optimize", []string{"optimize", "-o", "small.pdf", "report.pdf"}},
+		{"repair", []string{"repair", "-o", "fixed.pdf", "report.pdf"}},
+		{"merge", []string{"merge", "-o", "merged.pdf", "report.pdf", "reports/report.pdf"}},
+		{"split", []string{"split", "report.pdf"}},
+		{"dupes", []string{"dupes", "report.pdf", "reports/report.pdf", "broken.pdf"}},
+		{"config", []string{"config"}},
+		{"title", []string{"title", "report.pdf", "broken.pdf"}},
+		{"text", []string{"text", "report.pdf"}},
+		{"labels", []string{"labels", "get", "-list", "report.pdf"}},
+		{"bookmarks", []string{"bookmarks", "get", "report.pdf"}},
+		{"images", []string{"images", "report.pdf"}},
+		{"not-found", []string{"text", "missing.pdf"}},
+		{"not-pdf", []string{"text", "broken.pdf"}},
+		{"usage", []string{"merge", "report.pdf"}},
+		{"unknown-command", []string{"frobnicate", "report.pdf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runJSON(t, tt.args...)
+			golden := filepath.Join("testdata", "output", tt.name+".jsonl")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read %s (run with -update to create it): %v", golden, err)
+			}
+			if got != string(want) {
+				t.Errorf("Output of %v changed; run with -update if intended.\ngot:\n%s\nwant:\n%s", tt.args, got, want)
+			}
+		})
+	}
+}
+
+func TestOutputJSON_FormatFlag(t *testing.T) {
+	// The -format json flag some commands had before -output json writes
+	// the same records.
+	tests := []struct {
+		format []string
+		output []string
+	}{
+		{[]string{"validate", "-format", "json", "report.pdf", "broken.pdf"}, []string{"validate", "report.pdf", "broken.pdf"}},
+		{[]string{"bookmarks", "get", "-format", "json", "report.pdf"}, []string{"bookmarks", "get", "report.pdf"}},
+		{[]string{"repair", "-format", "json", "-o", "fixed.pdf", "report.pdf"}, []string{"repair", "-o", "fixed.pdf", "report.pdf"}},
+	}
+	for _, tt := range tests {
+		if got, want := runIn(t, tt.format...), runJSON(t, tt.output...); got != want {
+			t.Errorf("Expected %v to match -output json:\ngot:\n%s\nwant:\n%s", tt.format, got, want)
+		}
+	}
+}
+
+func TestOutputJSON_Records(t *testing.T) {
+	// Every line is one record of a known type, and errors have a code.
+	got := runJSON(t, "title", "report.pdf", "broken.pdf")
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a record per file and an error, got %q", got)
+	}
+	for _, line := range lines {
+		var r output.Record
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("Invalid record %q: %v", line, err)
+		}
+		switch r.Type {
+		case output.TypeResult, output.TypeSummary:
+			if r.Data == nil {
+				t.Errorf("Expected data in %q", line)
+			}
+		case output.TypeError:
+			if r.Error == nil || r.Error.Code == "" || r.Error.Message == "" {
+				t.Errorf("Expected a code and message in %q", line)
+			}
+		default:
+			t.Errorf("Unknown record type in %q", line)
+		}
+		if r.Command != "title" {
+			t.Errorf("Expected the command in %q", line)
+		}
+	}
+	if last := lines[len(lines)-1]; !strings.Contains(last, `"code":"failed"`) {
+		t.Errorf("Expected the command to end with a failed error, got %q", last)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/utils"
+)
+
+const renameUsage = `Usage:
+  pdfmod rename [-template TEMPLATE] <file.pdf|dir>...
+
+Renames each PDF to the name the template builds from its metadata, as
+the interactive flow proposes. TEMPLATE defaults to the rename_template
+setting and may use {title}, {author}, {subject}, {producer}, {name} and
+{date}. Names that are taken are handled by the collision setting.`
+
+func runRename(args []string) error {
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	template := fs.String("template", cfg.RenameTemplate(), "template of the new names")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *template == "" || fs.NArg() == 0 {
+		return usageError(errors.New(renameUsage))
+	}
+
+	files, err := pdfFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	renamer := newRenamer(*template)
+	return forEachFile(files, func(path string) error {
+		newPath, err := renamer.rename(path)
+		if err != nil {
+			return err
+		}
+		if out != nil {
+			return out.Result(path, struct {
+				Path string `json:"path"`
+			}{newPath})
+		}
+		fmt.Printf("%s -> %s\n", path, newPath)
+		return nil
+	})
+}
+
+// renamer renames PDFs by a rename template.
+type renamer struct {
+	template string
+	files    file.FileHandler
+	metadata pdf.PDFMetadataHandler
+}
+
+func newRenamer(template string) *renamer {
+	return &renamer{
+		template: template,
+		files:    &file.FilePickerService{Collision: cfg.Collision()},
+		metadata: newMetadataService(),
+	}
+}
+
+// rename renames the file at path and returns its new path.
+func (r *renamer) rename(path string) (string, error) {
+	fields, err := r.metadata.ReadMetadata(path)
+	if err != nil {
+		return "", err
+	}
+	name := manager.ExpandTemplate(r.template, path, fields, time.Now())
+	for _, validate := range []utils.Validator{utils.NonEmpty, utils.MaxBytes(utils.MaxNameBytes)} {
+		if err := validate(name); err != nil {
+			return "", usageError(fmt.Errorf("invalid name %q from template %q: %w", name, r.template, err))
+		}
+	}
+	return r.files.RenameFile(path, name)
+}
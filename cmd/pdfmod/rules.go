@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/sidshirsat/pdfmod/internal/manager"
+)
+
+const rulesUsage = `Usage:
+  pdfmod rules -f RULES.json [-dry-run] [-format text|json] <file.pdf|dir>...
+
+Applies a rules file to the metadata of each PDF, in order. A rule matches
+on the file name and field values and strips or sets fields:
+
+  {"rules": [
+    {"name": "invoices", "filename": "^INV-(\\d+)",
+     "set": {"Title": "Invoice $1", "Subject": "Billing"}},
+    {"name": "word prefix", "strip": {"Title": "^Microsoft Word - "}},
+    {"name": "producer", "set": {"Producer": "Example Corp"}}
+  ]}
+
+With -dry-run, the edits are listed but not written.`
+
+func runRules(args []string) error {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	rulesPath := fs.String("f", "", "rules file")
+	dryRun := fs.Bool("dry-run", false, "list the edits without writing them")
+	formatFlag(fs, "rules")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *rulesPath == "" || fs.NArg() == 0 {
+		return usageError(errors.New(rulesUsage))
+	}
+
+	f, err := os.Open(*rulesPath)
+	if err != nil {
+		return err
+	}
+	set, err := manager.ReadRules(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	engine, err := manager.NewRulesEngine(newMetadataService(), set)
+	if err != nil {
+		return err
+	}
+	files, err := pdfFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	report := engine.Apply(files, *dryRun)
+	failed := 0
+	for _, result := range report.Files {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if out == nil {
+		printRulesReport(report)
+	} else if err := writeRulesRecords(report); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return failedError("%d of %d files failed", failed, len(files))
+	}
+	return nil
+}
+
+func writeRulesRecords(report *manager.RulesReport) error {
+	for _, result := range report.Files {
+		var err error
+		if result.Error != "" {
+			err = out.Error(result.Path, failedError("%s", result.Error))
+		} else {
+			err = out.Result(result.Path, struct {
+				Changes map[string]string `json:"changes"`
+				Rules   []string          `json:"rules"`
+			}{result.Changes, orEmpty(result.Rules)})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return out.Summary(struct {
+		DryRun bool                `json:"dryRun"`
+		Stats  []manager.RuleStats `json:"stats"`
+	}{report.DryRun, orEmpty(report.Stats)})
+}
+
+func printRulesReport(report *manager.RulesReport) {
+	verb := "updated"
+	if report.DryRun {
+		verb = "would update"
+	}
+	for _, result := range report.Files {
+		switch {
+		case result.Error != "":
+			fmt.Fprintf(os.Stderr, "%s: %s\n", result.Path, result.Error)
+		case len(result.Changes) > 0:
+			fmt.Printf("%s: %s\n", result.Path, verb)
+			fields := make([]string, 0, len(result.Changes))
+			for field := range result.Changes {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				if value := result.Changes[field]; value == "" {
+					fmt.Printf("  %s removed\n", field)
+				} else {
+					fmt.Printf("  %s = %q\n", field, value)
+				}
+			}
+		}
+	}
+
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tMATCHED\tCHANGED")
+	for _, s := range report.Stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", s.Rule, s.Matched, s.Changed)
+	}
+	tw.Flush()
+}
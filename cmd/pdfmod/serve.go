@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/server"
+)
+
+const serveUsage = `Usage:
+  pdfmod serve [-addr ADDR] [-root DIR] [-max-upload BYTES]
+
+Serves a JSON API for listing, uploading and downloading PDFs, reading and
+changing their metadata, and renaming, merging and splitting them. Paths in
+requests are relative to -root, which defaults to the configured workdir,
+and cannot lead outside it.
+
+  GET    /v1/files                  list the PDFs in the root, or in ?dir=
+  POST   /v1/files?path=            upload a PDF (?overwrite=true replaces one)
+  GET    /v1/files/content?path=    download a PDF
+  GET    /v1/metadata?path=         read the Info and XMP metadata
+  PATCH  /v1/metadata?path=         set fields, such as {"Title": "Report"}
+  POST   /v1/rename                 {"path": "a.pdf", "name": "b"}
+  POST   /v1/merge                  {"paths": ["a.pdf", "b.pdf"], "output": "ab.pdf"}
+  POST   /v1/split                  {"path": "a.pdf", "pages": ["1-3", "4-"]}
+
+Errors are returned as {"error": {"code": ..., "message": ...}}.`
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on")
+	root := fs.String("root", cfg.WorkDir(), "directory the API can access")
+	maxUpload := fs.Int64("max-upload", server.DefaultMaxUpload, "largest accepted upload in bytes")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 0 {
+		return usageError(errors.New(serveUsage))
+	}
+	dir, err := filepath.Abs(*root)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("root %s is not a directory", dir)
+	}
+
+	service := newMetadataService()
+	api := server.NewServer(dir, &file.FilePickerService{Collision: cfg.Collision()}, service, service)
+	api.MaxUpload = *maxUpload
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	logger.Info("serving", "root", dir, "url", "http://"+*addr)
+	if out != nil {
+		result := struct {
+			URL  string `json:"url"`
+			Root string `json:"root"`
+		}{"http://" + *addr, dir}
+		if err := out.Result("", result); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdown)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const textUsage = `Usage:
+  pdfmod text [-pages SELECTION] <file.pdf>
+
+Prints the text of each page, separated by form feeds. SELECTION accepts
+page numbers, labels and ranges such as "1-3,iv,A-1".`
+
+func runText(args []string) error {
+	fs := flag.NewFlagSet("text", flag.ContinueOnError)
+	selection := fs.String("pages", "", "pages to extract (default: all)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(textUsage))
+	}
+
+	var extractor pdf.TextExtractor = pdf.NewPDFService()
+	pages, err := extractor.ExtractText(fs.Arg(0), *selection)
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		if out == nil {
+			fmt.Printf("%s\n\f", page.Text)
+		} else if err := out.Result(fs.Arg(0), page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
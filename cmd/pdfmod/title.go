@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sidshirsat/pdfmod/internal/manager"
+)
+
+const titleUsage = `Usage:
+  pdfmod title [-apply] [-min-confidence N] <file.pdf|dir>...
+
+Suggests titles for PDFs whose title is missing or an authoring tool
+default, from the first page heading, the bookmarks and the file name.
+With -apply, the best suggestion is written when its confidence (0 to 1)
+reaches -min-confidence.`
+
+func runTitle(args []string) error {
+	fs := flag.NewFlagSet("title", flag.ContinueOnError)
+	apply := fs.Bool("apply", false, "write the best suggestion as the title")
+	minConfidence := fs.Float64("min-confidence", 0.6, "lowest confidence that -apply writes")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageError(errors.New(titleUsage))
+	}
+
+	files, err := pdfFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	svc := newMetadataService()
+	suggester := manager.NewTitleSuggester(svc, svc, svc)
+	return forEachFile(files, func(path string) error {
+		return suggestTitle(suggester, path, *apply, *minConfidence)
+	})
+}
+
+func suggestTitle(suggester *manager.TitleSuggester, path string, apply bool, minConfidence float64) error {
+	if apply {
+		best, written, err := suggester.ApplyBest(path, minConfidence)
+		if err != nil {
+			return err
+		}
+		if out != nil {
+			return out.Result(path, struct {
+				Best    manager.TitleCandidate `json:"best"`
+				Written bool                   `json:"written"`
+			}{best, written})
+		}
+		switch {
+		case written:
+			fmt.Printf("%s: set title %q (%s, %.2f)\n", path, best.Title, best.Source, best.Confidence)
+		case best.Title == "":
+			fmt.Printf("%s: no suggestion\n", path)
+		default:
+			fmt.Printf("%s: kept title, best suggestion %q (%s, %.2f)\n", path, best.Title, best.Source, best.Confidence)
+		}
+		return nil
+	}
+
+	suggestion, err := suggester.Suggest(path)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		suggestion.Candidates = orEmpty(suggestion.Candidates)
+		return out.Result(path, suggestion)
+	}
+	status := "ok"
+	if suggestion.NeedsTitle {
+		status = "needs title"
+	}
+	fmt.Printf("%s: %q (%s)\n", path, suggestion.Current, status)
+	for _, c := range suggestion.Candidates {
+		fmt.Printf("  %.2f  %-16s %s\n", c.Confidence, c.Source, c.Title)
+	}
+	return nil
+}
+
+// pdfFiles expands directories in paths to the PDF files directly inside
+// them.
+func pdfFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".pdf") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	return files, nil
+}
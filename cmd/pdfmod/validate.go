@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sidshirsat/pdfmod/internal/output"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+const validateUsage = `Usage:
+  pdfmod validate [-format text|json] [-strict] [-pdfa] <file.pdf|dir>...
+
+Checks the header, cross-reference data, trailer, object streams,
+references, stream lengths, page tree and Info/XMP agreement of each file.
+Exits with an error when any file has errors, or warnings with -strict.
+
+With -pdfa, also checks the PDF/A-2b requirements on encryption, output
+intents, embedded fonts, XMP metadata and PDF/A identification. These
+findings name the clause of ISO 19005-2 they violate.`
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	formatFlag(fs, "validate")
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	pdfa := fs.Bool("pdfa", false, "check PDF/A-2b conformance")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageError(errors.New(validateUsage))
+	}
+
+	files, err := pdfFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	var validator pdf.Validator = pdf.NewPDFService()
+	validate := validator.Validate
+	if *pdfa {
+		validate = validator.ValidatePDFA
+	}
+	failed := 0
+	for _, path := range files {
+		report, err := validate(path)
+		if err != nil {
+			if out == nil {
+				return err
+			}
+			failed++
+			if err := out.Error(path, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if !report.Valid() || *strict && len(report.Findings) > 0 {
+			failed++
+		}
+		switch {
+		case out != nil:
+			err = out.Result(path, struct {
+				Valid bool `json:"valid"`
+				*pdf.ValidationReport
+			}{report.Valid(), report})
+		default:
+			printValidationReport(report)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return output.WithCode(output.CodeInvalid, fmt.Errorf("%d of %d files failed validation", failed, len(files)))
+	}
+	return nil
+}
+
+func printValidationReport(report *pdf.ValidationReport) {
+	errs, warnings := report.Count(pdf.SeverityError), report.Count(pdf.SeverityWarning)
+	fmt.Printf("%s: %d errors, %d warnings\n", report.File, errs, warnings)
+	for _, f := range report.Findings {
+		fmt.Printf("  %s\n", f)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/sidshirsat/pdfmod/internal/config"
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/watch"
+)
+
+const watchUsage = `Usage:
+  pdfmod watch [-rules RULES.json] [-rename TEMPLATE] [-out DIR]
+               [-state FILE] [-settle DURATION] [-poll DURATION] [-polling] <dir>
+
+Processes the PDFs added to dir, such as by a scanner, once they have not
+changed for the -settle duration. Each file gets the metadata rules of
+-rules applied (see pdfmod rules), is renamed with the -rename template,
+which defaults to the configured rename_template, and is moved to -out.
+Renaming and moving follow the configured collision policy, except that
+unless collision is set explicitly a number is added to the name instead
+of overwriting an earlier file, such as after the scanner's counter resets.
+
+Changes are noticed through inotify on Linux, and by scanning dir every
+-poll interval elsewhere or with -polling. The hashes of processed files
+are kept in the -state file, .pdfmod-watch.json in dir by default, so they
+are not processed again after a restart; a file that failed is retried
+once its content changes. With -output json, a result or error record is
+written for each file. Stop with Ctrl-C or SIGTERM.`
+
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	rulesPath := fs.String("rules", "", "rules file applied to each PDF")
+	rename := fs.String("rename", cfg.RenameTemplate(), "template for the new name, such as {title} - {author}")
+	outDir := fs.String("out", "", "directory processed PDFs are moved to")
+	statePath := fs.String("state", "", "state file (default DIR/.pdfmod-watch.json)")
+	settle := fs.Duration("settle", watch.DefaultSettle, "how long a file must stay unchanged before it is processed")
+	poll := fs.Duration("poll", watch.DefaultPoll, "interval between scans of the directory")
+	polling := fs.Bool("polling", false, "scan the directory instead of using file notifications")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		return usageError(errors.New(watchUsage))
+	}
+	dir := fs.Arg(0)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if *outDir != "" {
+		if info, err := os.Stat(*outDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("output %s is not a directory", *outDir)
+		}
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(dir, ".pdfmod-watch.json")
+	}
+
+	// An unattended watch never replaces a file by default.
+	collision := file.CollisionSuffix
+	if cfg.IsSet(config.KeyCollision) {
+		collision = cfg.Collision()
+	}
+	metadata := newMetadataService()
+	processor := &watch.Processor{
+		Metadata:       metadata,
+		Files:          &file.FilePickerService{Collision: collision},
+		RenameTemplate: *rename,
+		OutDir:         *outDir,
+	}
+	if *rulesPath != "" {
+		f, err := os.Open(*rulesPath)
+		if err != nil {
+			return err
+		}
+		set, err := manager.ReadRules(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		if processor.Rules, err = manager.NewRulesEngine(metadata, set); err != nil {
+			return err
+		}
+	}
+	state, err := watch.LoadState(*statePath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	process := processor.Process
+	if out != nil {
+		process = func(path string) (string, error) {
+			newPath, err := processor.Process(path)
+			if err != nil {
+				out.Error(path, err)
+			} else {
+				out.Result(path, struct {
+					Path string `json:"path"`
+				}{newPath})
+			}
+			return newPath, err
+		}
+	}
+	w := &watch.Watcher{
+		Dir:     dir,
+		Process: process,
+		State:   state,
+		Settle:  *settle,
+		Poll:    *poll,
+		Polling: *polling,
+		Logger:  logger,
+	}
+	logger.Info("watching", "dir", dir)
+	if err := w.Run(ctx); err != nil {
+		return err
+	}
+	logger.Info("stopped watching", "dir", dir)
+	return nil
+}
@@ -0,0 +1,266 @@
+// Package config loads pdfmod settings from configuration files, the
+// environment and command line flags.
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Setting keys.
+const (
+	KeyProducer       = "producer"
+	KeyAuthor         = "author"
+	KeyWorkDir        = "workdir"
+	KeyRenameTemplate = "rename_template"
+	KeyCollision      = "collision"
+	KeyColor          = "color"
+	KeySort           = "sort"
+	KeyUI             = "ui"
+	KeyLogLevel       = "log_level"
+	KeyLogFormat      = "log_format"
+	KeyOutput         = "output"
+)
+
+// Sources of values other than files, which are named by their path.
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// ProjectFileName is the name of the project-local configuration file,
+// looked up in the working directory and its parents.
+const ProjectFileName = ".pdfmod"
+
+// setting describes a configuration key.
+type setting struct {
+	key    string
+	env    string
+	def    string
+	help   string
+	values []string
+}
+
+var settings = []setting{
+	{KeyProducer, "PDFMOD_PRODUCER", "", "default producer for metadata edits", nil},
+	{KeyAuthor, "PDFMOD_AUTHOR", "", "author written with metadata edits", nil},
+	{KeyWorkDir, "PDFMOD_WORKDIR", "pdf_files", "directory the interactive flow lists", nil},
+	{KeyRenameTemplate, "PDFMOD_RENAME_TEMPLATE", "", "default new name, such as {title} - {author}", nil},
+	{KeyCollision, "PDFMOD_COLLISION", "overwrite", "what renaming onto an existing file does", []string{"overwrite", "error", "suffix"}},
+	{KeyColor, "PDFMOD_COLOR", "auto", "colored output: auto colors terminals unless NO_COLOR is set", []string{"auto", "always", "never"}},
+	{KeyUI, "PDFMOD_UI", "auto", "interactive interface: full screen on terminals, or line prompts", []string{"auto", "tui", "line"}},
+	{KeySort, "PDFMOD_SORT", "name", "order of the files offered for selection", []string{"name", "date", "size"}},
+	{KeyLogLevel, "PDFMOD_LOG_LEVEL", "info", "least severe log messages shown", []string{"debug", "info", "warn", "error"}},
+	{KeyLogFormat, "PDFMOD_LOG_FORMAT", "text", "format of log messages on stderr", []string{"text", "json"}},
+	{KeyOutput, "PDFMOD_OUTPUT", "text", "format of command results: text, or json lines for scripts", []string{"text", "json"}},
+}
+
+func lookup(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// Value is a setting with the source it was taken from.
+type Value struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// Env is the environment variable that overrides the setting.
+	Env string `json:"env"`
+	// Help describes the setting.
+	Help string `json:"-"`
+}
+
+// Config holds the effective settings.
+type Config struct {
+	values map[string]Value
+	// Files lists the configuration files that were read, lowest
+	// precedence first.
+	Files []string
+}
+
+// Options control where Load looks for settings. Zero fields default to
+// the process environment and working directory.
+type Options struct {
+	Getenv  func(string) string
+	WorkDir string
+}
+
+// Load returns the defaults overridden, in increasing precedence, by the
+// user configuration file in the XDG config directory
+// ($XDG_CONFIG_HOME/pdfmod/config or ~/.config/pdfmod/config), the nearest
+// .pdfmod file in the working directory or its parents, and PDFMOD_*
+// environment variables. Flags are applied afterwards with Set.
+func Load(opts Options) (*Config, error) {
+	if opts.Getenv == nil {
+		opts.Getenv = os.Getenv
+	}
+	if opts.WorkDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("could not get current directory: %w", err)
+		}
+		opts.WorkDir = wd
+	}
+
+	c := &Config{values: map[string]Value{}}
+	for _, v := range Defaults() {
+		c.values[v.Key] = v
+	}
+	for _, path := range []string{userFile(opts.Getenv), projectFile(opts.WorkDir)} {
+		if path == "" {
+			continue
+		}
+		if err := c.readFile(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		c.Files = append(c.Files, path)
+	}
+	for _, s := range settings {
+		if value := opts.Getenv(s.env); value != "" {
+			if err := c.Set(s.key, value, SourceEnv+" "+s.env); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// userFile returns the path of the user configuration file.
+func userFile(getenv func(string) string) string {
+	dir := getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home := getenv("HOME")
+		if home == "" {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "pdfmod", "config")
+}
+
+// projectFile returns the nearest .pdfmod file in dir or its parents.
+func projectFile(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readFile reads "key = value" lines. Blank lines and lines starting with
+// # are ignored, and values may be quoted. A relative workdir is resolved
+// against the directory of the file.
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected key = value", path, n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if key == KeyWorkDir && value != "" && !filepath.IsAbs(value) {
+			value = filepath.Join(filepath.Dir(path), value)
+		}
+		if err := c.Set(key, value, path); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return nil
+}
+
+// Set overrides a setting, recording where the value came from.
+func (c *Config) Set(key, value, source string) error {
+	s, ok := lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if s.values != nil && !slices.Contains(s.values, value) {
+		return fmt.Errorf("invalid %s %q (%s): must be one of %s", key, value, source, strings.Join(s.values, ", "))
+	}
+	v := c.values[key]
+	v.Value, v.Source = value, source
+	c.values[key] = v
+	return nil
+}
+
+// Defaults returns every setting with its default value.
+func Defaults() []Value {
+	values := make([]Value, 0, len(settings))
+	for _, s := range settings {
+		values = append(values, Value{Key: s.key, Value: s.def, Source: SourceDefault, Env: s.env, Help: s.help})
+	}
+	return values
+}
+
+// Get returns the value of a setting.
+func (c *Config) Get(key string) string {
+	return c.values[key].Value
+}
+
+// IsSet reports whether a setting was configured rather than left at its
+// default.
+func (c *Config) IsSet(key string) bool {
+	return c.values[key].Source != SourceDefault
+}
+
+// Values returns every setting in a fixed order.
+func (c *Config) Values() []Value {
+	values := make([]Value, 0, len(settings))
+	for _, s := range settings {
+		values = append(values, c.values[s.key])
+	}
+	return values
+}
+
+// UserFile returns the path of the user configuration file Load reads.
+func UserFile() string {
+	return userFile(os.Getenv)
+}
+
+func (c *Config) Producer() string       { return c.Get(KeyProducer) }
+func (c *Config) Author() string         { return c.Get(KeyAuthor) }
+func (c *Config) WorkDir() string        { return c.Get(KeyWorkDir) }
+func (c *Config) RenameTemplate() string { return c.Get(KeyRenameTemplate) }
+func (c *Config) Collision() string      { return c.Get(KeyCollision) }
+func (c *Config) Color() string          { return c.Get(KeyColor) }
+func (c *Config) Sort() string           { return c.Get(KeySort) }
+func (c *Config) UI() string             { return c.Get(KeyUI) }
+func (c *Config) LogLevel() string       { return c.Get(KeyLogLevel) }
+func (c *Config) LogFormat() string      { return c.Get(KeyLogFormat) }
+func (c *Config) Output() string         { return c.Get(KeyOutput) }
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	root := t.TempDir()
+	userFile := filepath.Join(root, "xdg", "pdfmod", "config")
+	projectFile := filepath.Join(root, "project", ".pdfmod")
+	writeFile(t, userFile, "# team defaults\nproducer = User Corp\nauthor = 'Ada'\ncolor = never\n")
+	writeFile(t, projectFile, "producer = \"Project Corp\"\nworkdir = docs\n")
+	env := map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(root, "xdg"),
+		"PDFMOD_AUTHOR":   "Env Author",
+	}
+
+	cfg, err := config.Load(config.Options{
+		Getenv:  func(key string) string { return env[key] },
+		WorkDir: filepath.Join(root, "project", "sub", "dir"),
+	})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Set(config.KeyCollision, "suffix", "flag -collision"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if want := []string{userFile, projectFile}; !reflect.DeepEqual(cfg.Files, want) {
+		t.Errorf("Expected files %v, got %v", want, cfg.Files)
+	}
+	want := map[string][2]string{
+		config.KeyProducer:       {"Project Corp", projectFile},
+		config.KeyAuthor:         {"Env Author", "env PDFMOD_AUTHOR"},
+		config.KeyWorkDir:        {filepath.Join(root, "project", "docs"), projectFile},
+		config.KeyRenameTemplate: {"", config.SourceDefault},
+		config.KeyCollision:      {"suffix", "flag -collision"},
+		config.KeyColor:          {"never", userFile},
+		config.KeySort:           {"name", config.SourceDefault},
+		config.KeyUI:             {"auto", config.SourceDefault},
+		config.KeyLogLevel:       {"info", config.SourceDefault},
+		config.KeyLogFormat:      {"text", config.SourceDefault},
+		config.KeyOutput:         {"text", config.SourceDefault},
+	}
+	for _, v := range cfg.Values() {
+		if got := [2]string{v.Value, v.Source}; got != want[v.Key] {
+			t.Errorf("Expected %s = %q from %s, got %q from %s", v.Key, want[v.Key][0], want[v.Key][1], v.Value, v.Source)
+		}
+	}
+	if !cfg.IsSet(config.KeyCollision) || cfg.IsSet(config.KeySort) {
+		t.Error("Expected collision to be set and sort left at its default")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := map[string]string{
+		"producer":         ".pdfmod:1: expected key = value",
+		"colour = never":   `.pdfmod:1: unknown setting "colour"`,
+		"\ncolor = bright": `.pdfmod:2: invalid color "bright"`,
+	}
+	for content, want := range tests {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, ".pdfmod"), content)
+		_, err := config.Load(config.Options{Getenv: func(string) string { return "" }, WorkDir: dir})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+
+	env := map[string]string{"PDFMOD_COLLISION": "rename"}
+	_, err := config.Load(config.Options{Getenv: func(key string) string { return env[key] }, WorkDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "env PDFMOD_COLLISION") {
+		t.Errorf("Expected an error naming the environment variable, got %v", err)
+	}
+}
@@ -1,16 +1,41 @@
 package file
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/sidshirsat/pdfmod/internal/utils"
 )
 
+// Policies for renaming onto an existing file.
+const (
+	CollisionOverwrite = "overwrite"
+	CollisionError     = "error"
+	CollisionSuffix    = "suffix"
+)
+
+// Orders of the files offered for selection.
+const (
+	SortName = "name"
+	SortDate = "date"
+	SortSize = "size"
+)
+
 type FilePickerService struct {
 	Prompter utils.Prompter
+	// Sort orders the listed files. The default is SortName.
+	Sort string
+	// Collision decides what RenameFile does when the new name is taken.
+	// The default is CollisionOverwrite.
+	Collision string
 }
 
 var _ FileHandler = &FilePickerService{}
@@ -32,52 +57,267 @@ func (f *FilePickerService) ListFiles(dir string) ([]os.FileInfo, error) {
 	return fileInfos, nil
 }
 
-// SelectFile allows the user to select a file by entering the corresponding number.
+// SelectFile asks the user for one PDF among files and returns its name. See
+// SelectFiles for the input accepted besides a number.
 func (f *FilePickerService) SelectFile(files []os.FileInfo) (string, error) {
-	// Filter valid PDF files
-	var validPDFs []os.FileInfo
+	names, err := f.pick(files, false)
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// SelectFiles asks the user for one or more PDFs among files and returns
+// their names. Numbers and ranges such as "1,3,5-7" or "all" select from the
+// listed files. Other text narrows the list to names containing it, as do
+// numbers that are not in the list, and text after "/", such as "/2023",
+// always does. An empty answer clears the filter, and "sort name",
+// "sort date" or "sort size" reorders the list.
+func (f *FilePickerService) SelectFiles(files []os.FileInfo) ([]string, error) {
+	return f.pick(files, true)
+}
+
+func (f *FilePickerService) pick(files []os.FileInfo, multi bool) ([]string, error) {
+	var pdfs []os.FileInfo
 	for _, file := range files {
 		if !file.IsDir() && strings.HasSuffix(file.Name(), ".pdf") {
-			validPDFs = append(validPDFs, file)
+			pdfs = append(pdfs, file)
 		}
 	}
+	if len(pdfs) == 0 {
+		return nil, errors.New("no valid PDF files found. Consider adding files..")
+	}
 
-	// Display valid PDF files
-	for i, file := range validPDFs {
-		fmt.Printf("[%d] %s\n", i+1, file.Name())
+	order := f.Sort
+	SortFiles(pdfs, order)
+	prompt := "Select a file number, or /text to filter: "
+	if multi {
+		prompt = "Select files (such as 1,3,5-7 or all), or /text to filter: "
 	}
+	shown := pdfs
+	filter := ""
+	for {
+		for i, file := range shown {
+			fmt.Printf("[%d] %s\n", i+1, describe(file, order))
+		}
+		answer, err := f.Prompter.PromptUser(utils.Style(prompt, utils.RolePrompt))
+		if err != nil {
+			return nil, err
+		}
+
+		if by, ok := strings.CutPrefix(answer, "sort "); ok {
+			if by = strings.TrimSpace(by); by != SortName && by != SortDate && by != SortSize {
+				fmt.Println(utils.Style("Sort by name, date or size.", utils.RoleError))
+				continue
+			}
+			order = by
+			SortFiles(pdfs, order)
+			shown = filterFiles(pdfs, filter)
+			continue
+		}
+		text, isFilter := strings.CutPrefix(answer, "/")
+		if text == "" {
+			filter, shown = "", pdfs
+			continue
+		}
 
-	// Check if no valid PDFs were found
-	if len(validPDFs) == 0 {
-		return "", fmt.Errorf(utils.Colorize("no valid PDF files found. Consider adding files..", utils.Red))
+		var indexes []int
+		if !isFilter {
+			indexes, err = parseSelection(answer, len(shown))
+			// Numbers outside the list, such as a year, filter when
+			// names contain them.
+			isFilter = errors.Is(err, errNotSelection) || err != nil && len(filterFiles(pdfs, answer)) > 0
+		}
+		if isFilter {
+			matches := filterFiles(pdfs, text)
+			if len(matches) == 0 {
+				fmt.Println(utils.Style(fmt.Sprintf("No files match %q.", text), utils.RoleError))
+				continue
+			}
+			filter, shown = text, matches
+			continue
+		}
+		if err == nil && !multi && len(indexes) != 1 {
+			err = errors.New("select a single file")
+		}
+		if err != nil {
+			fmt.Println(utils.Style("Invalid selection: "+err.Error()+".", utils.RoleError))
+			continue
+		}
+		names := make([]string, len(indexes))
+		for i, index := range indexes {
+			names[i] = shown[index].Name()
+		}
+		return names, nil
 	}
+}
 
-	var selection int
-	for {
-		// Prompt for selection
-		fmt.Print(utils.Colorize("Select a file number: ", utils.Blue))
-		_, err := fmt.Scan(&selection)
+// errNotSelection marks input that is not a list of numbers.
+var errNotSelection = errors.New("not a selection")
+
+// parseSelection parses "all" or comma separated numbers and ranges of the
+// files numbered 1 to n, returning their indexes without duplicates in the
+// order given.
+func parseSelection(input string, n int) ([]int, error) {
+	if strings.EqualFold(input, "all") {
+		indexes := make([]int, n)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+	var indexes []int
+	seen := map[int]bool{}
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(strings.TrimSpace(first))
 		if err != nil {
-			return "", fmt.Errorf(utils.Colorize("invalid selection: %w", utils.Red), err)
+			return nil, errNotSelection
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+				return nil, errNotSelection
+			}
+		}
+		if lo < 1 || hi > n || lo > hi {
+			return nil, fmt.Errorf("%s is not within 1-%d", part, n)
 		}
+		for i := lo - 1; i < hi; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	return indexes, nil
+}
 
-		// Validate the selection
-		if selection >= 1 && selection <= len(validPDFs) {
-			selectedFile := validPDFs[selection-1]
-			return selectedFile.Name(), nil
-		} else {
-			fmt.Println(utils.Colorize("Invalid selection. Please select a valid file number.", utils.Red))
+// SortFiles orders files by name, by date with the newest first or by size
+// with the largest first.
+func SortFiles(files []os.FileInfo, by string) {
+	sort.SliceStable(files, func(i, j int) bool {
+		switch by {
+		case SortDate:
+			if !files[i].ModTime().Equal(files[j].ModTime()) {
+				return files[i].ModTime().After(files[j].ModTime())
+			}
+		case SortSize:
+			if files[i].Size() != files[j].Size() {
+				return files[i].Size() > files[j].Size()
+			}
 		}
+		return strings.ToLower(files[i].Name()) < strings.ToLower(files[j].Name())
+	})
+}
+
+// filterFiles returns the files whose name contains text, ignoring case.
+func filterFiles(files []os.FileInfo, text string) []os.FileInfo {
+	if text == "" {
+		return files
 	}
+	var matches []os.FileInfo
+	for _, file := range files {
+		if strings.Contains(strings.ToLower(file.Name()), strings.ToLower(text)) {
+			matches = append(matches, file)
+		}
+	}
+	return matches
 }
 
-// RenameFile renames a file with a new name.
+// describe returns the name of file with the attribute it is sorted by.
+func describe(file os.FileInfo, by string) string {
+	switch by {
+	case SortDate:
+		return fmt.Sprintf("%s (%s)", file.Name(), file.ModTime().Format("2006-01-02 15:04"))
+	case SortSize:
+		return fmt.Sprintf("%s (%d bytes)", file.Name(), file.Size())
+	}
+	return file.Name()
+}
+
+// RenameFile renames a file with a new name. When a different file already
+// has that name, it is replaced, the rename fails, or a numbered suffix such
+// as " (2)" is added, depending on the Collision policy.
 func (f *FilePickerService) RenameFile(filePath, newName string) (string, error) {
-	newPath := filepath.Join(filepath.Dir(filePath), newName+".pdf")
-	err := os.Rename(filePath, newPath)
+	newPath, err := f.target(filePath, filepath.Dir(filePath), newName)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename file: %w", err)
+	}
+	err = os.Rename(filePath, newPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to rename file: %w", err)
 	}
 
 	return newPath, nil
 }
+
+// MoveFile moves a file into dir, keeping its name, and returns its new
+// path. Names already taken in dir are handled by the Collision policy, as
+// in RenameFile. Files are copied when dir is on another file system.
+func (f *FilePickerService) MoveFile(filePath, dir string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	newPath, err := f.target(filePath, dir, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to move file: %w", err)
+	}
+	if err := os.Rename(filePath, newPath); err != nil {
+		var linkErr *os.LinkError
+		if !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+			return "", fmt.Errorf("failed to move file: %w", err)
+		}
+		if err := copyFile(filePath, newPath); err != nil {
+			return "", fmt.Errorf("failed to move file: %w", err)
+		}
+		if err := os.Remove(filePath); err != nil {
+			return "", fmt.Errorf("failed to move file: %w", err)
+		}
+	}
+	return newPath, nil
+}
+
+// target returns the path of the PDF named name in dir that filePath is
+// renamed or moved to, applying the Collision policy.
+func (f *FilePickerService) target(filePath, dir, name string) (string, error) {
+	newPath := filepath.Join(dir, name+".pdf")
+	if newPath != filePath && exists(newPath) {
+		switch f.Collision {
+		case CollisionError:
+			return "", fmt.Errorf("%w: %s", fs.ErrExist, newPath)
+		case CollisionSuffix:
+			for n := 2; exists(newPath); n++ {
+				newPath = filepath.Join(dir, fmt.Sprintf("%s (%d).pdf", name, n))
+			}
+		}
+	}
+	return newPath, nil
+}
+
+// copyFile copies src to dst, keeping its permissions.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -2,11 +2,17 @@ package file_test
 
 import (
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/utils"
+	"github.com/sidshirsat/pdfmod/mocks"
 )
 
 func TestFilePickerService_ListFiles_DirectoryNotFound(t *testing.T) {
@@ -19,6 +25,19 @@ func TestFilePickerService_ListFiles_DirectoryNotFound(t *testing.T) {
 	}
 }
 
+func TestFilePickerService_SelectFile_NoPDFs(t *testing.T) {
+	fps := &file.FilePickerService{}
+
+	// Errors are printed by the caller and never carry color escape codes.
+	_, err := fps.SelectFile(nil)
+	if err == nil {
+		t.Fatal("Expected an error without PDFs, got nil")
+	}
+	if strings.Contains(err.Error(), "\033[") {
+		t.Errorf("Expected a plain error, got %q", err)
+	}
+}
+
 func TestFilePickerService_SelectFile_Success(t *testing.T) {
 	// Resolve the absolute path to "pdf_files" in the project root
 	absPDFDir, err := filepath.Abs("pdf_files")
@@ -42,8 +61,12 @@ func TestFilePickerService_SelectFile_Success(t *testing.T) {
 		}
 	}
 
+	ctrl := gomock.NewController(t)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	mockPrompter.EXPECT().PromptUser(utils.Style("Select a file number, or /text to filter: ", utils.RolePrompt)).Return("1", nil).Times(1)
+
 	// Create FilePickerService and call ListFiles
-	fps := &file.FilePickerService{}
+	fps := &file.FilePickerService{Prompter: mockPrompter}
 	files, err := fps.ListFiles(absPDFDir)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
@@ -61,6 +84,135 @@ func TestFilePickerService_SelectFile_Success(t *testing.T) {
 	}
 }
 
+// pickerFiles creates PDFs with the given sizes, each a day older than the
+// one before, and lists them.
+func pickerFiles(t *testing.T, sizes map[string]int) []os.FileInfo {
+	t.Helper()
+	dir := t.TempDir()
+	day := 0
+	for _, name := range []string{"b-report.pdf", "a-invoice.pdf", "c-report-draft.pdf", "notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, sizes[name]), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		mtime := time.Now().AddDate(0, 0, -day)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("Failed to set time of %s: %v", name, err)
+		}
+		day++
+	}
+	files, err := (&file.FilePickerService{}).ListFiles(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return files
+}
+
+func TestFilePickerService_SelectFiles(t *testing.T) {
+	files := pickerFiles(t, map[string]int{"a-invoice.pdf": 30, "b-report.pdf": 10, "c-report-draft.pdf": 20})
+	prompt := utils.Style("Select files (such as 1,3,5-7 or all), or /text to filter: ", utils.RolePrompt)
+	tests := []struct {
+		name    string
+		sort    string
+		answers []string
+		want    []string
+	}{
+		{"list", "", []string{"3,1"}, []string{"c-report-draft.pdf", "a-invoice.pdf"}},
+		{"range", "", []string{"2-3"}, []string{"b-report.pdf", "c-report-draft.pdf"}},
+		{"all", "", []string{"all"}, []string{"a-invoice.pdf", "b-report.pdf", "c-report-draft.pdf"}},
+		{"out of range", "", []string{"4", "1-1"}, []string{"a-invoice.pdf"}},
+		{"filter", "", []string{"REPORT", "all"}, []string{"b-report.pdf", "c-report-draft.pdf"}},
+		{"no match keeps the list", "", []string{"zzz", "1"}, []string{"a-invoice.pdf"}},
+		{"filter prefix", "", []string{"/draft", "1"}, []string{"c-report-draft.pdf"}},
+		{"clear filter", "", []string{"draft", "", "2"}, []string{"b-report.pdf"}},
+		{"sort by date", file.SortDate, []string{"1"}, []string{"b-report.pdf"}},
+		{"sort by size", file.SortSize, []string{"1"}, []string{"a-invoice.pdf"}},
+		{"sort command", "", []string{"sort size", "3"}, []string{"b-report.pdf"}},
+		{"sort filtered", "", []string{"report", "sort size", "1"}, []string{"c-report-draft.pdf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockPrompter := mocks.NewMockPrompter(ctrl)
+			var calls []*gomock.Call
+			for _, answer := range tt.answers {
+				calls = append(calls, mockPrompter.EXPECT().PromptUser(prompt).Return(answer, nil))
+			}
+			gomock.InOrder(calls...)
+
+			fps := &file.FilePickerService{Prompter: mockPrompter, Sort: tt.sort}
+			got, err := fps.SelectFiles(files)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFilePickerService_SelectFiles_Numbers(t *testing.T) {
+	// Numbers that are not in the list filter names containing them, so
+	// that years can be searched for.
+	dir := t.TempDir()
+	for _, name := range []string{"budget-2023.pdf", "budget-2024.pdf", "plan.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	files, err := (&file.FilePickerService{}).ListFiles(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	prompt := utils.Style("Select files (such as 1,3,5-7 or all), or /text to filter: ", utils.RolePrompt)
+	tests := []struct {
+		name    string
+		answers []string
+		want    []string
+	}{
+		{"year", []string{"2024", "1"}, []string{"budget-2024.pdf"}},
+		{"index", []string{"3"}, []string{"plan.pdf"}},
+		{"prefix", []string{"/2023", "all"}, []string{"budget-2023.pdf"}},
+		{"no match is out of range", []string{"7", "2"}, []string{"budget-2024.pdf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockPrompter := mocks.NewMockPrompter(ctrl)
+			var calls []*gomock.Call
+			for _, answer := range tt.answers {
+				calls = append(calls, mockPrompter.EXPECT().PromptUser(prompt).Return(answer, nil))
+			}
+			gomock.InOrder(calls...)
+
+			got, err := (&file.FilePickerService{Prompter: mockPrompter}).SelectFiles(files)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFilePickerService_SelectFile_SingleAndInputClosed(t *testing.T) {
+	files := pickerFiles(t, nil)
+	prompt := utils.Style("Select a file number, or /text to filter: ", utils.RolePrompt)
+	ctrl := gomock.NewController(t)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	gomock.InOrder(
+		mockPrompter.EXPECT().PromptUser(prompt).Return("1,2", nil),
+		mockPrompter.EXPECT().PromptUser(prompt).Return("", utils.ErrInputClosed),
+	)
+
+	fps := &file.FilePickerService{Prompter: mockPrompter}
+	if _, err := fps.SelectFile(files); !errors.Is(err, utils.ErrInputClosed) {
+		t.Fatalf("Expected ErrInputClosed, got %v", err)
+	}
+}
+
 func TestFilePickerService_RenameFile_Success(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir := t.TempDir()
@@ -120,3 +272,88 @@ func TestFilePickerService_RenameFile_Failure(t *testing.T) {
 		t.Errorf("Expected a file does not exist error, got %v", err)
 	}
 }
+
+func TestFilePickerService_RenameFile_Collision(t *testing.T) {
+	tests := []struct {
+		policy   string
+		wantName string
+		wantErr  bool
+	}{
+		{file.CollisionOverwrite, "taken.pdf", false},
+		{file.CollisionError, "", true},
+		{file.CollisionSuffix, "taken (3).pdf", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, name := range []string{"source.pdf", "taken.pdf", "taken (2).pdf"} {
+				if err := os.WriteFile(filepath.Join(tempDir, name), []byte(name), 0644); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+
+			fps := &file.FilePickerService{Collision: tt.policy}
+			newFilePath, err := fps.RenameFile(filepath.Join(tempDir, "source.pdf"), "taken")
+			if tt.wantErr {
+				if !errors.Is(err, fs.ErrExist) {
+					t.Fatalf("Expected fs.ErrExist for an existing file, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if want := filepath.Join(tempDir, tt.wantName); newFilePath != want {
+				t.Errorf("Expected new file path to be '%s', got '%s'", want, newFilePath)
+			}
+			if data, _ := os.ReadFile(newFilePath); string(data) != "source.pdf" {
+				t.Errorf("Expected the renamed file at '%s', found %q", newFilePath, data)
+			}
+		})
+	}
+}
+
+func TestFilePickerService_MoveFile(t *testing.T) {
+	tests := []struct {
+		policy   string
+		wantName string
+		wantErr  bool
+	}{
+		{file.CollisionOverwrite, "scan.pdf", false},
+		{file.CollisionError, "", true},
+		{file.CollisionSuffix, "scan (2).pdf", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			srcDir, outDir := t.TempDir(), t.TempDir()
+			source := filepath.Join(srcDir, "scan.pdf")
+			if err := os.WriteFile(source, []byte("new"), 0644); err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(outDir, "scan.pdf"), []byte("old"), 0644); err != nil {
+				t.Fatalf("Failed to create existing file: %v", err)
+			}
+
+			fps := &file.FilePickerService{Collision: tt.policy}
+			newFilePath, err := fps.MoveFile(source, outDir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error for an existing file, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if want := filepath.Join(outDir, tt.wantName); newFilePath != want {
+				t.Errorf("Expected new file path to be '%s', got '%s'", want, newFilePath)
+			}
+			if data, _ := os.ReadFile(newFilePath); string(data) != "new" {
+				t.Errorf("Expected the moved file at '%s', found %q", newFilePath, data)
+			}
+			if _, err := os.Stat(source); !os.IsNotExist(err) {
+				t.Errorf("Expected the source to be gone, got %v", err)
+			}
+		})
+	}
+}
@@ -6,5 +6,7 @@ import "os"
 type FileHandler interface {
 	ListFiles(dir string) ([]os.FileInfo, error)
 	SelectFile(files []os.FileInfo) (string, error)
+	SelectFiles(files []os.FileInfo) ([]string, error)
 	RenameFile(filePath, newName string) (string, error)
+	MoveFile(filePath, dir string) (string, error)
 }
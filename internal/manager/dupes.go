@@ -0,0 +1,218 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// How a duplicate matches the file kept in its group.
+const (
+	// MatchBytes means the files are identical byte for byte.
+	MatchBytes = "bytes"
+	// MatchContent means the files differ only in their metadata or in how
+	// the file is laid out, such as after an incremental update.
+	MatchContent = "content"
+)
+
+// DuplicateFile is a file of a DuplicateGroup.
+type DuplicateFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	// Match says how the file matches the keeper of its group, and is empty
+	// for the keeper.
+	Match   string    `json:"match,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	// Fields counts the metadata fields of the file.
+	Fields int `json:"fields"`
+}
+
+// DuplicateGroup is a set of files with the same content. Keeper is the
+// file suggested to keep, for the reason given, and Duplicates are the
+// others.
+type DuplicateGroup struct {
+	// ContentHash is empty when the files are not readable PDFs and were
+	// only compared byte for byte.
+	ContentHash string          `json:"contentHash,omitempty"`
+	Keeper      DuplicateFile   `json:"keeper"`
+	Reason      string          `json:"reason"`
+	Duplicates  []DuplicateFile `json:"duplicates"`
+}
+
+// ErrSameFile is returned by RemoveDuplicate for a duplicate that is the
+// keeper itself.
+var ErrSameFile = errors.New("duplicate is the same file as the keeper")
+
+// DuplicateError is a file that could not be compared.
+type DuplicateError struct {
+	Path string
+	Err  error
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return e.Err
+}
+
+// DuplicateFinder groups PDF files with the same content.
+type DuplicateFinder struct {
+	Hasher   pdf.ContentHasher
+	Metadata pdf.PDFMetadataHandler
+	// BytesOnly groups files only when they are identical byte for byte.
+	BytesOnly bool
+}
+
+func NewDuplicateFinder(hasher pdf.ContentHasher, metadata pdf.PDFMetadataHandler) *DuplicateFinder {
+	return &DuplicateFinder{
+		Hasher:   hasher,
+		Metadata: metadata,
+	}
+}
+
+// Find groups the files of paths that have the same content hash, or the
+// same bytes with BytesOnly or when they cannot be read as PDFs. Paths that
+// name a file already seen, such as another hard link to it, are skipped.
+// Groups are ordered by the path of their keeper; files without duplicates
+// are left out. Files that cannot be read are returned as errors.
+func (f *DuplicateFinder) Find(paths []string) ([]DuplicateGroup, []*DuplicateError) {
+	type entry struct {
+		file DuplicateFile
+		hash string
+	}
+	byKey := map[string][]entry{}
+	var keys []string
+	var errs []*DuplicateError
+	var seen []os.FileInfo
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		info, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, &DuplicateError{path, err})
+			continue
+		}
+		// A file given twice, or under another of its hard links, would
+		// otherwise be a duplicate of itself.
+		if slices.ContainsFunc(seen, func(s os.FileInfo) bool { return os.SameFile(s, info) }) {
+			continue
+		}
+		seen = append(seen, info)
+		sum, err := fileSHA256(path)
+		if err != nil {
+			errs = append(errs, &DuplicateError{path, err})
+			continue
+		}
+		e := entry{file: DuplicateFile{Path: path, SHA256: sum, Size: info.Size(), ModTime: info.ModTime()}}
+		key := "sha256:" + sum
+		if !f.BytesOnly {
+			if hash, err := f.Hasher.ContentHash(path); err == nil {
+				e.hash, key = hash, "content:"+hash
+			}
+		}
+		if fields, err := f.Metadata.ReadMetadata(path); err == nil {
+			e.file.Fields = len(fields)
+		}
+		if byKey[key] == nil {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], e)
+	}
+
+	var groups []DuplicateGroup
+	for _, key := range keys {
+		entries := byKey[key]
+		if len(entries) < 2 {
+			continue
+		}
+		files := make([]DuplicateFile, len(entries))
+		for i, e := range entries {
+			files[i] = e.file
+		}
+		sort.Slice(files, func(i, j int) bool { return keepBefore(files[i], files[j]) })
+		group := DuplicateGroup{
+			ContentHash: entries[0].hash,
+			Keeper:      files[0],
+			Reason:      keepReason(files[0], files[1]),
+		}
+		for _, file := range files[1:] {
+			file.Match = MatchContent
+			if file.SHA256 == group.Keeper.SHA256 {
+				file.Match = MatchBytes
+			}
+			group.Duplicates = append(group.Duplicates, file)
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Keeper.Path < groups[j].Keeper.Path })
+	return groups, errs
+}
+
+// keepBefore orders the files of a group by how well they suit being kept:
+// the most metadata first, then the oldest, then the shortest path.
+func keepBefore(a, b DuplicateFile) bool {
+	switch {
+	case a.Fields != b.Fields:
+		return a.Fields > b.Fields
+	case !a.ModTime.Equal(b.ModTime):
+		return a.ModTime.Before(b.ModTime)
+	case len(a.Path) != len(b.Path):
+		return len(a.Path) < len(b.Path)
+	}
+	return a.Path < b.Path
+}
+
+// keepReason says why keeper was ordered before next.
+func keepReason(keeper, next DuplicateFile) string {
+	switch {
+	case keeper.Fields != next.Fields:
+		return "most metadata"
+	case !keeper.ModTime.Equal(next.ModTime):
+		return "oldest"
+	case len(keeper.Path) != len(next.Path):
+		return "shortest path"
+	}
+	return "first by path"
+}
+
+// RemoveDuplicate deletes duplicate, or with link replaces it with a hard
+// link to keeper, so that both paths keep working. The link is created
+// next to duplicate first and renamed over it, so duplicate is never
+// missing. A duplicate that already is a link to keeper is left alone with
+// link, and without it fails with ErrSameFile, since deleting it could
+// delete the keeper.
+func RemoveDuplicate(keeper, duplicate string, link bool) error {
+	keeperInfo, err := os.Stat(keeper)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(duplicate); err == nil && os.SameFile(keeperInfo, info) {
+		if link {
+			return nil
+		}
+		return fmt.Errorf("could not delete %s: %w", duplicate, ErrSameFile)
+	}
+	if !link {
+		if err := os.Remove(duplicate); err != nil {
+			return fmt.Errorf("could not delete %s: %w", duplicate, err)
+		}
+		return nil
+	}
+	tmp := filepath.Join(filepath.Dir(duplicate), fmt.Sprintf(".pdfmod-link-%d", os.Getpid()))
+	if err := os.Link(keeper, tmp); err != nil {
+		return fmt.Errorf("could not link %s to %s: %w", duplicate, keeper, err)
+	}
+	if err := os.Rename(tmp, duplicate); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("could not replace %s: %w", duplicate, err)
+	}
+	return nil
+}
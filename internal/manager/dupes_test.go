@@ -0,0 +1,183 @@
+package manager_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+// writeDupes writes files with the given content and modification times,
+// in order, to a temporary directory and returns their paths.
+func writeDupes(t *testing.T, contents map[string]string) (string, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	paths := map[string]string{}
+	mtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"report.pdf", "report copy.pdf", "Q3 final.pdf", "other.pdf", "notes.pdf", "notes (2).pdf"} {
+		content, ok := contents[name]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		mtime = mtime.Add(time.Hour)
+		paths[name] = path
+	}
+	return dir, paths
+}
+
+func TestDuplicateFinder_Find(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hasher := mocks.NewMockContentHasher(ctrl)
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+
+	_, paths := writeDupes(t, map[string]string{
+		"report.pdf":      "report v1",
+		"report copy.pdf": "report v1",
+		"Q3 final.pdf":    "report v1 with a title",
+		"other.pdf":       "other",
+		"notes.pdf":       "not a PDF",
+		"notes (2).pdf":   "not a PDF",
+	})
+	contentHashes := map[string]string{"report.pdf": "r", "report copy.pdf": "r", "Q3 final.pdf": "r", "other.pdf": "o"}
+	for name, path := range paths {
+		if hash, ok := contentHashes[name]; ok {
+			hasher.EXPECT().ContentHash(path).Return(hash, nil)
+		} else {
+			hasher.EXPECT().ContentHash(path).Return("", pdf.ErrNotPDF)
+		}
+	}
+	metadata.EXPECT().ReadMetadata(paths["Q3 final.pdf"]).Return(map[string]string{"Title": "Q3", "Author": "Ada"}, nil)
+	metadata.EXPECT().ReadMetadata(gomock.Any()).Return(map[string]string{}, nil).AnyTimes()
+
+	groups, errs := manager.NewDuplicateFinder(hasher, metadata).Find([]string{
+		paths["report.pdf"], paths["report copy.pdf"], paths["Q3 final.pdf"],
+		paths["other.pdf"], paths["notes.pdf"], paths["notes (2).pdf"],
+	})
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", groups)
+	}
+
+	// The copy with the most metadata is kept, and the others match it by
+	// content even though two of them are identical to each other.
+	report := groups[0]
+	if report.Keeper.Path != paths["Q3 final.pdf"] || report.Reason != "most metadata" || report.ContentHash != "r" {
+		t.Errorf("Expected Q3 final.pdf kept for its metadata, got %+v", report)
+	}
+	if len(report.Duplicates) != 2 || report.Duplicates[0].Path != paths["report.pdf"] || report.Duplicates[0].Match != manager.MatchContent {
+		t.Errorf("Expected the older report.pdf first, matched by content, got %+v", report.Duplicates)
+	}
+
+	// Files that are not PDFs are compared by their bytes, and the oldest
+	// is kept.
+	notes := groups[1]
+	if notes.Keeper.Path != paths["notes.pdf"] || notes.Reason != "oldest" || notes.ContentHash != "" {
+		t.Errorf("Expected notes.pdf kept as the oldest, got %+v", notes)
+	}
+	if len(notes.Duplicates) != 1 || notes.Duplicates[0].Match != manager.MatchBytes {
+		t.Errorf("Expected a byte for byte duplicate, got %+v", notes.Duplicates)
+	}
+}
+
+func TestDuplicateFinder_Find_BytesOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hasher := mocks.NewMockContentHasher(ctrl)
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	metadata.EXPECT().ReadMetadata(gomock.Any()).Return(map[string]string{}, nil).AnyTimes()
+
+	dir, paths := writeDupes(t, map[string]string{"report.pdf": "v1", "report copy.pdf": "v1", "Q3 final.pdf": "v2"})
+	finder := manager.NewDuplicateFinder(hasher, metadata)
+	finder.BytesOnly = true
+	groups, errs := finder.Find([]string{paths["report.pdf"], paths["report copy.pdf"], paths["Q3 final.pdf"], filepath.Join(dir, "missing.pdf")})
+	if len(errs) != 1 || errs[0].Path != filepath.Join(dir, "missing.pdf") || !os.IsNotExist(errs[0].Err) {
+		t.Errorf("Expected an error for the missing file, got %v", errs)
+	}
+	if len(groups) != 1 || len(groups[0].Duplicates) != 1 || groups[0].Duplicates[0].Path != paths["report copy.pdf"] {
+		t.Errorf("Expected the identical copy as the only duplicate, got %+v", groups)
+	}
+}
+
+func TestDuplicateFinder_Find_SameFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hasher := mocks.NewMockContentHasher(ctrl)
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+
+	dir, paths := writeDupes(t, map[string]string{"report.pdf": "v1", "other.pdf": "v2"})
+	link := filepath.Join(dir, "report link.pdf")
+	if err := os.Link(paths["report.pdf"], link); err != nil {
+		t.Fatal(err)
+	}
+	// Each file is read once, however it is named.
+	hasher.EXPECT().ContentHash(paths["report.pdf"]).Return("r", nil)
+	hasher.EXPECT().ContentHash(paths["other.pdf"]).Return("o", nil)
+	metadata.EXPECT().ReadMetadata(gomock.Any()).Return(map[string]string{}, nil).Times(2)
+
+	groups, errs := manager.NewDuplicateFinder(hasher, metadata).Find([]string{
+		paths["report.pdf"], paths["other.pdf"],
+		filepath.Join(dir, ".", "report.pdf"), paths["report.pdf"], link,
+	})
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if len(groups) != 0 {
+		t.Errorf("Expected a repeated path and a hard link not to be duplicates, got %+v", groups)
+	}
+}
+
+func TestRemoveDuplicate_SameFile(t *testing.T) {
+	dir, paths := writeDupes(t, map[string]string{"report.pdf": "v1"})
+	link := filepath.Join(dir, "report link.pdf")
+	if err := os.Link(paths["report.pdf"], link); err != nil {
+		t.Fatal(err)
+	}
+	for _, duplicate := range []string{paths["report.pdf"], link} {
+		if err := manager.RemoveDuplicate(paths["report.pdf"], duplicate, false); !errors.Is(err, manager.ErrSameFile) {
+			t.Errorf("Expected ErrSameFile deleting %s, got %v", duplicate, err)
+		}
+		if _, err := os.Stat(duplicate); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", duplicate, err)
+		}
+	}
+}
+
+func TestRemoveDuplicate(t *testing.T) {
+	_, paths := writeDupes(t, map[string]string{"report.pdf": "v1", "report copy.pdf": "v1", "Q3 final.pdf": "v1"})
+
+	if err := manager.RemoveDuplicate(paths["report.pdf"], paths["report copy.pdf"], false); err != nil {
+		t.Fatalf("RemoveDuplicate failed: %v", err)
+	}
+	if _, err := os.Stat(paths["report copy.pdf"]); !os.IsNotExist(err) {
+		t.Errorf("Expected the duplicate to be deleted, got %v", err)
+	}
+
+	for range 2 {
+		// Linking twice leaves the link in place.
+		if err := manager.RemoveDuplicate(paths["report.pdf"], paths["Q3 final.pdf"], true); err != nil {
+			t.Fatalf("RemoveDuplicate with link failed: %v", err)
+		}
+	}
+	keeper, _ := os.Stat(paths["report.pdf"])
+	linked, err := os.Stat(paths["Q3 final.pdf"])
+	if err != nil || !os.SameFile(keeper, linked) {
+		t.Errorf("Expected the duplicate to be a link to the keeper, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(paths["report.pdf"]))
+	if len(entries) != 2 {
+		t.Errorf("Expected no temporary files to be left, got %d entries", len(entries))
+	}
+}
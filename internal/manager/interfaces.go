@@ -5,7 +5,8 @@ type PDFManagerInterface interface {
 	Execute() error
 }
 
-// Prompter is an interface for prompting the user for input.
+// Prompter is an interface for prompting the user for input. It returns an
+// error when no answer can be read, such as at the end of input.
 type Prompter interface {
-	PromptUser(prompt string) string
+	PromptUser(prompt string) (string, error)
 }
@@ -0,0 +1,329 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// Manifest columns that identify a file rather than hold metadata.
+const (
+	ColumnPath   = "path"
+	ColumnSHA256 = "sha256"
+)
+
+// Ways an imported row was matched to a file.
+const (
+	MatchPath   = "path"
+	MatchSHA256 = "sha256"
+)
+
+// infoFieldOrder puts the common Info entries first in exported manifests.
+var infoFieldOrder = []string{"Title", "Author", "Subject", "Keywords", "Creator", "Producer", "CreationDate", "ModDate"}
+
+// ManifestRow holds the metadata of one file, keyed as
+// PDFMetadataHandler.ReadMetadata returns it.
+type ManifestRow struct {
+	Path   string
+	SHA256 string
+	Fields map[string]string
+}
+
+// Manifest is a table of file metadata. Fields lists the metadata columns
+// in order. An empty value means the field is unset; a row without the
+// field leaves it unchanged on import.
+type Manifest struct {
+	Fields []string
+	Rows   []ManifestRow
+}
+
+// ImportResult describes how one manifest row was applied.
+type ImportResult struct {
+	Row     int      `json:"row"`
+	Path    string   `json:"path"`
+	Match   string   `json:"match,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// MetadataManifest exports the metadata of a directory of PDFs to a
+// manifest and applies edited manifests back.
+type MetadataManifest struct {
+	Files    file.FileHandler
+	Metadata pdf.PDFMetadataHandler
+}
+
+func NewMetadataManifest(files file.FileHandler, metadata pdf.PDFMetadataHandler) *MetadataManifest {
+	return &MetadataManifest{
+		Files:    files,
+		Metadata: metadata,
+	}
+}
+
+// Export reads the Info and XMP metadata of every PDF in dir.
+func (m *MetadataManifest) Export(dir string) (*Manifest, error) {
+	paths, err := m.pdfPaths(dir)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	seen := map[string]bool{}
+	for _, path := range paths {
+		fields, err := m.Metadata.ReadMetadata(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return nil, err
+		}
+		for name := range fields {
+			seen[name] = true
+		}
+		manifest.Rows = append(manifest.Rows, ManifestRow{Path: path, SHA256: sum, Fields: fields})
+	}
+	manifest.Fields = orderFields(seen)
+	return manifest, nil
+}
+
+// orderFields returns the common Info entries first, then the other Info
+// entries and finally the XMP properties, each sorted by name.
+func orderFields(names map[string]bool) []string {
+	var fields, rest []string
+	for _, name := range infoFieldOrder {
+		if names[name] {
+			fields = append(fields, name)
+			delete(names, name)
+		}
+	}
+	for name := range names {
+		rest = append(rest, name)
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		xmpI, xmpJ := strings.Contains(rest[i], ":"), strings.Contains(rest[j], ":")
+		if xmpI != xmpJ {
+			return xmpJ
+		}
+		return rest[i] < rest[j]
+	})
+	return append(fields, rest...)
+}
+
+// Import applies the manifest. Each row is matched to a file by its path,
+// or else by the content hash among the PDFs in dir, or in the directory of
+// the row's path when dir is empty. Only fields whose value differs from the
+// file are written, and an empty value removes the field. Rows that fail
+// are reported in their result and do not stop the import.
+func (m *MetadataManifest) Import(manifest *Manifest, dir string) []ImportResult {
+	byHash := map[string]map[string]string{}
+	results := make([]ImportResult, 0, len(manifest.Rows))
+	for i, row := range manifest.Rows {
+		result := ImportResult{Row: i + 1, Path: row.Path}
+		path, match, err := m.matchRow(row, dir, byHash)
+		if err == nil {
+			result.Path, result.Match = path, match
+			result.Changed, err = m.applyRow(manifest.Fields, row, path)
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (m *MetadataManifest) matchRow(row ManifestRow, dir string, byHash map[string]map[string]string) (string, string, error) {
+	if row.Path != "" {
+		if info, err := os.Stat(row.Path); err == nil && !info.IsDir() {
+			return row.Path, MatchPath, nil
+		}
+	}
+	if row.SHA256 == "" {
+		return "", "", fmt.Errorf("file %s not found", row.Path)
+	}
+	if dir == "" {
+		dir = filepath.Dir(row.Path)
+	}
+	hashes, ok := byHash[dir]
+	if !ok {
+		hashes = map[string]string{}
+		paths, err := m.pdfPaths(dir)
+		if err != nil {
+			return "", "", err
+		}
+		for _, path := range paths {
+			sum, err := fileSHA256(path)
+			if err != nil {
+				return "", "", err
+			}
+			hashes[sum] = path
+		}
+		byHash[dir] = hashes
+	}
+	if path, ok := hashes[strings.ToLower(row.SHA256)]; ok {
+		return path, MatchSHA256, nil
+	}
+	return "", "", fmt.Errorf("no file in %s matches %s or its hash", dir, row.Path)
+}
+
+// applyRow writes the fields of row that differ from the file and returns
+// their names.
+func (m *MetadataManifest) applyRow(fields []string, row ManifestRow, path string) ([]string, error) {
+	current, err := m.Metadata.ReadMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]string{}
+	var changed []string
+	for _, name := range fields {
+		if value, ok := row.Fields[name]; ok && value != current[name] {
+			changes[name] = value
+			changed = append(changed, name)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := m.Metadata.WriteMetadata(path, changes); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+func (m *MetadataManifest) pdfPaths(dir string) ([]string, error) {
+	files, err := m.Files.ListFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not list %s: %w", dir, err)
+	}
+	var paths []string
+	for _, f := range files {
+		if !f.IsDir() && strings.EqualFold(filepath.Ext(f.Name()), ".pdf") {
+			paths = append(paths, filepath.Join(dir, f.Name()))
+		}
+	}
+	return paths, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("could not hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteCSV writes the manifest as CSV with a header row of the path, hash
+// and field columns.
+func (m *Manifest) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{ColumnPath, ColumnSHA256}, m.Fields...)); err != nil {
+		return err
+	}
+	for _, row := range m.Rows {
+		record := []string{row.Path, row.SHA256}
+		for _, name := range m.Fields {
+			record = append(record, row.Fields[name])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads a manifest written by WriteCSV. The path or sha256 column
+// may be missing, but not both.
+func ReadCSV(r io.Reader) (*Manifest, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV manifest: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("manifest is empty")
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, value := range record {
+			row[header[i]] = value
+		}
+		rows = append(rows, row)
+	}
+	return newManifest(header, rows)
+}
+
+// WriteJSON writes the manifest as an array of objects holding the path,
+// hash and set fields of each file.
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	objects := make([]map[string]string, 0, len(m.Rows))
+	for _, row := range m.Rows {
+		object := map[string]string{ColumnPath: row.Path, ColumnSHA256: row.SHA256}
+		for name, value := range row.Fields {
+			object[name] = value
+		}
+		objects = append(objects, object)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(objects)
+}
+
+// ReadJSON reads a manifest written by WriteJSON. A field missing from an
+// object is left unchanged on import.
+func ReadJSON(r io.Reader) (*Manifest, error) {
+	var objects []map[string]string
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("could not read JSON manifest: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, object := range objects {
+		for name := range object {
+			seen[name] = true
+		}
+	}
+	return newManifest(orderFields(seen), objects)
+}
+
+func newManifest(columns []string, rows []map[string]string) (*Manifest, error) {
+	manifest := &Manifest{}
+	hasKey := false
+	for _, column := range columns {
+		switch column {
+		case ColumnPath, ColumnSHA256:
+			hasKey = true
+		default:
+			manifest.Fields = append(manifest.Fields, column)
+		}
+	}
+	if !hasKey {
+		return nil, fmt.Errorf("manifest has neither a %s nor a %s column", ColumnPath, ColumnSHA256)
+	}
+	for _, row := range rows {
+		fields := map[string]string{}
+		for _, name := range manifest.Fields {
+			if value, ok := row[name]; ok {
+				fields[name] = value
+			}
+		}
+		manifest.Rows = append(manifest.Rows, ManifestRow{Path: row[ColumnPath], SHA256: row[ColumnSHA256], Fields: fields})
+	}
+	return manifest, nil
+}
@@ -0,0 +1,129 @@
+package manager_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+// manifestDir creates files with the given contents in a temporary
+// directory and returns it with their infos.
+func manifestDir(t *testing.T, files map[string]string) (string, []os.FileInfo) {
+	t.Helper()
+	dir := t.TempDir()
+	var infos []os.FileInfo
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed to stat %s: %v", name, err)
+		}
+		infos = append(infos, info)
+	}
+	return dir, infos
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestMetadataManifest_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, infos := manifestDir(t, map[string]string{"a.pdf": "first", "notes.txt": "skip"})
+	path := filepath.Join(dir, "a.pdf")
+	files := mocks.NewMockFileHandler(ctrl)
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	files.EXPECT().ListFiles(dir).Return(infos, nil)
+	metadata.EXPECT().ReadMetadata(path).Return(map[string]string{
+		"dc:title": "Report, final",
+		"Custom":   "x",
+		"Title":    "Report, final",
+		"Producer": "pdfmod",
+	}, nil)
+
+	manifest, err := manager.NewMetadataManifest(files, metadata).Export(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"Title", "Producer", "Custom", "dc:title"}; !reflect.DeepEqual(manifest.Fields, want) {
+		t.Errorf("expected fields %v, got %v", want, manifest.Fields)
+	}
+
+	var buf bytes.Buffer
+	if err := manifest.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "path,sha256,Title,Producer,Custom,dc:title\n" +
+		path + "," + sha256Hex("first") + `,"Report, final",pdfmod,x,"Report, final"` + "\n"
+	if buf.String() != want {
+		t.Errorf("expected CSV\n%s\ngot\n%s", want, buf.String())
+	}
+}
+
+func TestMetadataManifest_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, infos := manifestDir(t, map[string]string{"a.pdf": "first", "renamed.pdf": "second"})
+	a, renamed := filepath.Join(dir, "a.pdf"), filepath.Join(dir, "renamed.pdf")
+	csv := "path,sha256,Title,Author,dc:title\n" +
+		a + ",,New title,Jo,New title\n" +
+		filepath.Join(dir, "b.pdf") + "," + strings.ToUpper(sha256Hex("second")) + ",Same,,Same\n" +
+		filepath.Join(dir, "gone.pdf") + "," + sha256Hex("gone") + ",X,,X\n"
+	manifest, err := manager.ReadCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ReadCSV failed: %v", err)
+	}
+
+	files := mocks.NewMockFileHandler(ctrl)
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	files.EXPECT().ListFiles(dir).Return(infos, nil).Times(1)
+	metadata.EXPECT().ReadMetadata(a).Return(map[string]string{"Title": "Old", "Author": "Jo", "dc:title": "Old"}, nil)
+	metadata.EXPECT().ReadMetadata(renamed).Return(map[string]string{"Title": "Same", "dc:title": "Same"}, nil)
+	metadata.EXPECT().WriteMetadata(a, map[string]string{"Title": "New title", "dc:title": "New title"}).Return(nil)
+
+	results := manager.NewMetadataManifest(files, metadata).Import(manifest, "")
+	want := []manager.ImportResult{
+		{Row: 1, Path: a, Match: manager.MatchPath, Changed: []string{"Title", "dc:title"}},
+		{Row: 2, Path: renamed, Match: manager.MatchSHA256},
+		{Row: 3, Path: filepath.Join(dir, "gone.pdf"), Error: "no file in " + dir + " matches " + filepath.Join(dir, "gone.pdf") + " or its hash"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("expected results\n%+v\ngot\n%+v", want, results)
+	}
+}
+
+func TestReadJSON_MissingFieldsAreUnchanged(t *testing.T) {
+	manifest, err := manager.ReadJSON(strings.NewReader(`[{"path": "a.pdf", "Title": "A"}, {"sha256": "ab", "Author": ""}]`))
+	if err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+	if want := []string{"Title", "Author"}; !reflect.DeepEqual(manifest.Fields, want) {
+		t.Errorf("expected fields %v, got %v", want, manifest.Fields)
+	}
+	want := []manager.ManifestRow{
+		{Path: "a.pdf", Fields: map[string]string{"Title": "A"}},
+		{SHA256: "ab", Fields: map[string]string{"Author": ""}},
+	}
+	if !reflect.DeepEqual(manifest.Rows, want) {
+		t.Errorf("expected rows %+v, got %+v", want, manifest.Rows)
+	}
+	if _, err := manager.ReadCSV(strings.NewReader("Title\nA\n")); err == nil {
+		t.Error("expected an error for a manifest without path or sha256")
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/sidshirsat/pdfmod/internal/file"
 	"github.com/sidshirsat/pdfmod/internal/pdf"
@@ -15,6 +18,24 @@ type PDFManager struct {
 	FileHandler        file.FileHandler
 	PDFMetadataHandler pdf.PDFMetadataHandler
 	Prompter           Prompter
+	// TitleSuggester, when set, offers a title derived from the document as
+	// the default when its current title is missing or a placeholder.
+	TitleSuggester *TitleSuggester
+	Defaults       Defaults
+}
+
+// Defaults are configured values the interactive flow uses.
+type Defaults struct {
+	// WorkDir is the directory whose files are listed, relative to the
+	// working directory unless absolute. It defaults to "pdf_files".
+	WorkDir string
+	// Producer is offered when prompting for the producer.
+	Producer string
+	// Author, when set, is written along with metadata edits.
+	Author string
+	// RenameTemplate proposes a new file name built from the placeholders
+	// {title}, {author}, {subject}, {producer}, {name} and {date}.
+	RenameTemplate string
 }
 
 func NewPDFManager(fh file.FileHandler, pmh pdf.PDFMetadataHandler, prompter Prompter) *PDFManager {
@@ -28,15 +49,9 @@ func NewPDFManager(fh file.FileHandler, pmh pdf.PDFMetadataHandler, prompter Pro
 var _ PDFManagerInterface = &PDFManager{}
 
 func (pm *PDFManager) Execute() error {
-	// Get the directory and list files.
-	dir, err := os.Getwd()
+	dir, err := pm.WorkDir()
 	if err != nil {
-		return fmt.Errorf("could not get current directory: %w", err)
-	}
-
-	dir = filepath.Join(dir, "pdf_files")
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return fmt.Errorf("directory %q does not exist. Please create it and add some PDF files", dir)
+		return err
 	}
 
 	files, err := pm.FileHandler.ListFiles(dir)
@@ -44,38 +59,157 @@ func (pm *PDFManager) Execute() error {
 		return err
 	}
 
-	// Select a file
-	selectedFile, err := pm.FileHandler.SelectFile(files)
+	// Select the files
+	selectedFiles, err := pm.FileHandler.SelectFiles(files)
 	if err != nil {
 		return err
 	}
-	filePath := filepath.Join(dir, selectedFile)
 
 	// Ask user choice
 	fmt.Println("What would you like to do with the PDF:")
-	fmt.Println("1. Rename the PDF")
-	fmt.Println("2. Modify PDF metadata fields")
-	choice := pm.Prompter.PromptUser("Enter the number of your choice: ")
-
-	switch choice {
-	case "1":
-		newName := pm.Prompter.PromptUser("Enter the new name for the PDF (without extension): ")
-		_, err = pm.FileHandler.RenameFile(filePath, newName)
-		if err != nil {
-			return err
+	choice, err := utils.Choose(pm.Prompter, "Enter the number of your choice", []string{
+		"Rename the PDF",
+		"Modify PDF metadata fields",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, selectedFile := range selectedFiles {
+		filePath := filepath.Join(dir, selectedFile)
+		if len(selectedFiles) > 1 {
+			fmt.Println(utils.Style(selectedFile, utils.RoleInfo))
+		}
+		switch choice {
+		case 0:
+			err = pm.rename(filePath)
+		case 1:
+			err = pm.updateMetadata(filePath)
 		}
-		fmt.Println(utils.Colorize("File renamed successfully.", utils.Green))
-	case "2":
-		title := pm.Prompter.PromptUser("Enter the new title for the PDF: ")
-		producer := pm.Prompter.PromptUser("Enter the new producer name for the PDF: ")
-		err := pm.PDFMetadataHandler.UpdateMetadata(filePath, title, producer)
 		if err != nil {
 			return err
 		}
-		fmt.Println(utils.Colorize("PDF metadata updated successfully.", utils.Green))
-	default:
-		fmt.Println(utils.Colorize("Invalid choice. Please restart and select '1' or '2'.", utils.Red))
-		return fmt.Errorf("invalid choice: %s", choice) // Return an error for invalid choice
 	}
 	return nil
 }
+
+// WorkDir returns the absolute path of the directory whose files are
+// listed, failing when it does not exist.
+func (pm *PDFManager) WorkDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("could not get current directory: %w", err)
+	}
+
+	workDir := pm.Defaults.WorkDir
+	if workDir == "" {
+		workDir = "pdf_files"
+	}
+	if filepath.IsAbs(workDir) {
+		dir = workDir
+	} else {
+		dir = filepath.Join(dir, workDir)
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "", fmt.Errorf("directory %q does not exist. Please create it and add some PDF files", dir)
+	}
+	return dir, nil
+}
+
+// rename asks for the new name of the file and renames it.
+func (pm *PDFManager) rename(filePath string) error {
+	newName, err := utils.Ask(pm.Prompter, "Enter the new name for the PDF (without extension)", pm.renameDefault(filePath),
+		utils.NonEmpty, utils.MaxBytes(utils.MaxNameBytes), utils.FilenameChars)
+	if err != nil {
+		return err
+	}
+	_, err = pm.FileHandler.RenameFile(filePath, newName)
+	if err != nil {
+		return err
+	}
+	fmt.Println(utils.Style("File renamed successfully.", utils.RoleSuccess))
+	return nil
+}
+
+// updateMetadata asks for the new title and producer of the file and writes
+// them, along with the configured author.
+func (pm *PDFManager) updateMetadata(filePath string) error {
+	title, err := pm.promptTitle(filePath)
+	if err != nil {
+		return err
+	}
+	producer, err := utils.Ask(pm.Prompter, "Enter the new producer name for the PDF", pm.Defaults.Producer)
+	if err != nil {
+		return err
+	}
+	fields := map[string]string{"Title": title, "Producer": producer}
+	if pm.Defaults.Author != "" {
+		fields["Author"] = pm.Defaults.Author
+	}
+	if err := pm.PDFMetadataHandler.UpdateMetadata(filePath, fields); err != nil {
+		return err
+	}
+	fmt.Println(utils.Style("PDF metadata updated successfully.", utils.RoleSuccess))
+	return nil
+}
+
+// promptTitle asks for the new title. When a suggestion is available it is
+// offered as the default, taken when the user enters nothing.
+func (pm *PDFManager) promptTitle(filePath string) (string, error) {
+	if pm.TitleSuggester == nil {
+		return utils.Ask(pm.Prompter, "Enter the new title for the PDF", "")
+	}
+	suggestion, err := pm.TitleSuggester.Suggest(filePath)
+	if err != nil || !suggestion.NeedsTitle || len(suggestion.Candidates) == 0 {
+		return utils.Ask(pm.Prompter, "Enter the new title for the PDF", "")
+	}
+
+	fmt.Println("Suggested titles:")
+	for i, c := range suggestion.Candidates {
+		fmt.Printf("  %d. %s (%s, %.0f%%)\n", i+1, c.Title, c.Source, c.Confidence*100)
+	}
+	return utils.Ask(pm.Prompter, "Enter the new title for the PDF", suggestion.Candidates[0].Title)
+}
+
+var (
+	templatePlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+	unsafeNameChars     = regexp.MustCompile(`[/\\:*?"<>|\x00-\x1f]+`)
+)
+
+// renameDefault expands the rename template for the file, or returns an
+// empty string when there is no template or it expands to nothing.
+func (pm *PDFManager) renameDefault(filePath string) string {
+	if pm.Defaults.RenameTemplate == "" {
+		return ""
+	}
+	fields, err := pm.PDFMetadataHandler.ReadMetadata(filePath)
+	if err != nil {
+		fields = map[string]string{}
+	}
+	return ExpandTemplate(pm.Defaults.RenameTemplate, filePath, fields, time.Now())
+}
+
+// ExpandTemplate builds a file name, without extension, from a rename
+// template with the placeholders {title}, {author}, {subject}, {producer},
+// {name} and {date}, filled in from the metadata fields and name of the
+// file. Unknown placeholders are kept and characters that are unsafe in
+// file names are replaced, so the result is empty only when the template
+// expands to nothing.
+func ExpandTemplate(template, filePath string, fields map[string]string, now time.Time) string {
+	values := map[string]string{
+		"title":    fields["Title"],
+		"author":   fields["Author"],
+		"subject":  fields["Subject"],
+		"producer": fields["Producer"],
+		"name":     strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)),
+		"date":     now.Format("2006-01-02"),
+	}
+	name := templatePlaceholder.ReplaceAllStringFunc(template, func(m string) string {
+		if value, ok := values[strings.ToLower(m[1:len(m)-1])]; ok {
+			return value
+		}
+		return m
+	})
+	name = unsafeNameChars.ReplaceAllString(name, "-")
+	return strings.Trim(strings.Join(strings.Fields(name), " "), " -")
+}
@@ -1,12 +1,16 @@
 package manager_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/utils"
 	"github.com/sidshirsat/pdfmod/mocks"
 )
 
@@ -37,10 +41,10 @@ func TestPDFManager_Execute_RenameFile(t *testing.T) {
 
 	// Set expectations for FileHandler and Prompter interactions
 	mockFileHandler.EXPECT().ListFiles(absPDFDir).Return([]os.FileInfo{mockFileInfo}, nil).Times(1)
-	mockFileHandler.EXPECT().SelectFile([]os.FileInfo{mockFileInfo}).Return("sample.pdf", nil).Times(1)
+	mockFileHandler.EXPECT().SelectFiles([]os.FileInfo{mockFileInfo}).Return([]string{"sample.pdf"}, nil).Times(1)
 
-	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("1").Times(1)
-	mockPrompter.EXPECT().PromptUser("Enter the new name for the PDF (without extension): ").Return("new_sample").Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("1", nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new name for the PDF (without extension): ").Return("new_sample", nil).Times(1)
 	mockFileHandler.EXPECT().RenameFile(filepath.Join(absPDFDir, "sample.pdf"), "new_sample").Return("new_sample.pdf", nil).Times(1)
 
 	// Create PDFManager instance with mocks
@@ -55,6 +59,36 @@ func TestPDFManager_Execute_RenameFile(t *testing.T) {
 	_ = os.RemoveAll(absPDFDir)
 }
 
+func TestPDFManager_Execute_RenameFile_LongName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	workDir := t.TempDir()
+	filePath := filepath.Join(workDir, "scan.pdf")
+
+	mockFileHandler := mocks.NewMockFileHandler(ctrl)
+	mockPDFMetadataHandler := mocks.NewMockPDFMetadataHandler(ctrl)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	mockFileInfo := mocks.NewMockFileInfo(ctrl)
+	mockFileInfo.EXPECT().Name().Return("scan.pdf").AnyTimes()
+	mockFileHandler.EXPECT().ListFiles(workDir).Return([]os.FileInfo{mockFileInfo}, nil)
+	mockFileHandler.EXPECT().SelectFiles([]os.FileInfo{mockFileInfo}).Return([]string{"scan.pdf"}, nil)
+
+	// 200 characters of two bytes each exceed the 255 byte limit on names,
+	// so the name is asked again.
+	prompt := "Enter the new name for the PDF (without extension): "
+	gomock.InOrder(
+		mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("1", nil),
+		mockPrompter.EXPECT().PromptUser(prompt).Return(strings.Repeat("ä", 200), nil),
+		mockPrompter.EXPECT().PromptUser(prompt).Return("Prüfbericht", nil),
+	)
+	mockFileHandler.EXPECT().RenameFile(filePath, "Prüfbericht").Return(filepath.Join(workDir, "Prüfbericht.pdf"), nil)
+
+	pdfManager := manager.NewPDFManager(mockFileHandler, mockPDFMetadataHandler, mockPrompter)
+	pdfManager.Defaults = manager.Defaults{WorkDir: workDir}
+	if err := pdfManager.Execute(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
 func TestPDFManager_Execute_UpdateMetadata(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -78,13 +112,13 @@ func TestPDFManager_Execute_UpdateMetadata(t *testing.T) {
 	mockFileInfo.EXPECT().IsDir().Return(false).AnyTimes()
 
 	mockFileHandler.EXPECT().ListFiles(absPDFDir).Return([]os.FileInfo{mockFileInfo}, nil).Times(1)
-	mockFileHandler.EXPECT().SelectFile([]os.FileInfo{mockFileInfo}).Return("sample.pdf", nil).Times(1)
+	mockFileHandler.EXPECT().SelectFiles([]os.FileInfo{mockFileInfo}).Return([]string{"sample.pdf"}, nil).Times(1)
 
-	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("2").Times(1)
-	mockPrompter.EXPECT().PromptUser("Enter the new title for the PDF: ").Return("New Title").Times(1)
-	mockPrompter.EXPECT().PromptUser("Enter the new producer name for the PDF: ").Return("New Producer").Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("2", nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new title for the PDF: ").Return("New Title", nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new producer name for the PDF: ").Return("New Producer", nil).Times(1)
 
-	mockPDFMetadataHandler.EXPECT().UpdateMetadata(filepath.Join(absPDFDir, "sample.pdf"), "New Title", "New Producer").Return(nil).Times(1)
+	mockPDFMetadataHandler.EXPECT().UpdateMetadata(filepath.Join(absPDFDir, "sample.pdf"), map[string]string{"Title": "New Title", "Producer": "New Producer"}).Return(nil).Times(1)
 
 	pdfManager := manager.NewPDFManager(mockFileHandler, mockPDFMetadataHandler, mockPrompter)
 
@@ -122,18 +156,146 @@ func TestPDFManager_Execute_InvalidChoice(t *testing.T) {
 
 	// Set expectations for FileHandler and Prompter interactions
 	mockFileHandler.EXPECT().ListFiles(absPDFDir).Return([]os.FileInfo{mockFileInfo}, nil).Times(1)
-	mockFileHandler.EXPECT().SelectFile([]os.FileInfo{mockFileInfo}).Return("sample.pdf", nil).Times(1)
+	mockFileHandler.EXPECT().SelectFiles([]os.FileInfo{mockFileInfo}).Return([]string{"sample.pdf"}, nil).Times(1)
 
-	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("3").Times(1)
+	// An invalid choice is asked again until the input ends
+	gomock.InOrder(
+		mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("3", nil),
+		mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("", utils.ErrInputClosed),
+	)
 
 	// Create PDFManager instance with mocks
 	pdfManager := manager.NewPDFManager(mockFileHandler, mockPDFMetadataHandler, mockPrompter)
 
 	// Execute and assert error
-	if err := pdfManager.Execute(); err == nil {
-		t.Fatalf("expected an error, got none")
+	if err := pdfManager.Execute(); !errors.Is(err, utils.ErrInputClosed) {
+		t.Fatalf("expected ErrInputClosed, got %v", err)
 	}
 
 	// Clean up
 	_ = os.RemoveAll(absPDFDir)
 }
+
+func TestPDFManager_Execute_UpdateMetadata_SuggestedTitle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	absPDFDir, err := filepath.Abs("pdf_files")
+	if err != nil {
+		t.Fatalf("failed to resolve absolute path for pdf_files: %v", err)
+	}
+
+	err = os.MkdirAll(absPDFDir, os.ModePerm)
+	if err != nil {
+		t.Fatalf("failed to create pdf_files directory: %v", err)
+	}
+
+	mockFileHandler := mocks.NewMockFileHandler(ctrl)
+	mockPDFMetadataHandler := mocks.NewMockPDFMetadataHandler(ctrl)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	mockFileInfo := mocks.NewMockFileInfo(ctrl)
+	mockInfoHandler := mocks.NewMockInfoHandler(ctrl)
+	mockOutlineHandler := mocks.NewMockOutlineHandler(ctrl)
+	mockContentReader := mocks.NewMockContentReader(ctrl)
+
+	mockFileInfo.EXPECT().Name().Return("sample.pdf").AnyTimes()
+	mockFileInfo.EXPECT().IsDir().Return(false).AnyTimes()
+
+	filePath := filepath.Join(absPDFDir, "sample.pdf")
+	mockFileHandler.EXPECT().ListFiles(absPDFDir).Return([]os.FileInfo{mockFileInfo}, nil).Times(1)
+	mockFileHandler.EXPECT().SelectFiles([]os.FileInfo{mockFileInfo}).Return([]string{"sample.pdf"}, nil).Times(1)
+
+	// The document has no title, but its bookmarks suggest one.
+	mockInfoHandler.EXPECT().ReadInfo(filePath).Return(map[string]string{}, nil).Times(1)
+	mockContentReader.EXPECT().ReadPageTextSpans(filePath, 1).Return(nil, nil).Times(1)
+	mockOutlineHandler.EXPECT().ReadOutline(filePath).Return([]*pdf.OutlineItem{{Title: "Field Guide", Page: 1}}, nil).Times(1)
+
+	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("2", nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new title for the PDF [Field Guide]: ").Return("", nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new producer name for the PDF: ").Return("New Producer", nil).Times(1)
+
+	mockPDFMetadataHandler.EXPECT().UpdateMetadata(filePath, map[string]string{"Title": "Field Guide", "Producer": "New Producer"}).Return(nil).Times(1)
+
+	pdfManager := manager.NewPDFManager(mockFileHandler, mockPDFMetadataHandler, mockPrompter)
+	pdfManager.TitleSuggester = manager.NewTitleSuggester(mockInfoHandler, mockOutlineHandler, mockContentReader)
+
+	if err := pdfManager.Execute(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_ = os.RemoveAll(absPDFDir)
+}
+
+func TestPDFManager_Execute_Defaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	workDir := t.TempDir()
+	filePath := filepath.Join(workDir, "scan.pdf")
+
+	mockFileHandler := mocks.NewMockFileHandler(ctrl)
+	mockPDFMetadataHandler := mocks.NewMockPDFMetadataHandler(ctrl)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	mockFileInfo := mocks.NewMockFileInfo(ctrl)
+
+	mockFileInfo.EXPECT().Name().Return("scan.pdf").AnyTimes()
+	mockFileHandler.EXPECT().ListFiles(workDir).Return([]os.FileInfo{mockFileInfo}, nil).Times(2)
+	mockFileHandler.EXPECT().SelectFiles([]os.FileInfo{mockFileInfo}).Return([]string{"scan.pdf"}, nil).Times(2)
+
+	// Metadata edit: the configured producer is the default and the author
+	// is written in the same update.
+	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("2", nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new title for the PDF: ").Return("Q1: Plan", nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new producer name for the PDF [Team Corp]: ").Return("", nil).Times(1)
+	mockPDFMetadataHandler.EXPECT().UpdateMetadata(filePath, map[string]string{"Title": "Q1: Plan", "Producer": "Team Corp", "Author": "Ada"}).Return(nil).Times(1)
+
+	// Rename: the template expands to the default name.
+	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("1", nil).Times(1)
+	mockPDFMetadataHandler.EXPECT().ReadMetadata(filePath).Return(map[string]string{"Title": "Q1: Plan", "Author": "Ada"}, nil).Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the new name for the PDF (without extension) [Q1- Plan - Ada (scan)]: ").Return("", nil).Times(1)
+	mockFileHandler.EXPECT().RenameFile(filePath, "Q1- Plan - Ada (scan)").Return(filepath.Join(workDir, "Q1- Plan - Ada (scan).pdf"), nil).Times(1)
+
+	pdfManager := manager.NewPDFManager(mockFileHandler, mockPDFMetadataHandler, mockPrompter)
+	pdfManager.Defaults = manager.Defaults{
+		WorkDir:        workDir,
+		Producer:       "Team Corp",
+		Author:         "Ada",
+		RenameTemplate: "{title} - {author} ({name})",
+	}
+	for i := 0; i < 2; i++ {
+		if err := pdfManager.Execute(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+func TestPDFManager_Execute_RenameMultipleFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	workDir := t.TempDir()
+
+	mockFileHandler := mocks.NewMockFileHandler(ctrl)
+	mockPDFMetadataHandler := mocks.NewMockPDFMetadataHandler(ctrl)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+
+	mockFileHandler.EXPECT().ListFiles(workDir).Return(nil, nil).Times(1)
+	mockFileHandler.EXPECT().SelectFiles(gomock.Any()).Return([]string{"a.pdf", "b.pdf"}, nil).Times(1)
+
+	// The action is chosen once and the name asked for each file, again
+	// when it is not a valid file name.
+	gomock.InOrder(
+		mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("1", nil),
+		mockPrompter.EXPECT().PromptUser("Enter the new name for the PDF (without extension): ").Return("first", nil),
+		mockPrompter.EXPECT().PromptUser("Enter the new name for the PDF (without extension): ").Return("a/b", nil),
+		mockPrompter.EXPECT().PromptUser("Enter the new name for the PDF (without extension): ").Return("second", nil),
+	)
+	mockFileHandler.EXPECT().RenameFile(filepath.Join(workDir, "a.pdf"), "first").Return(filepath.Join(workDir, "first.pdf"), nil).Times(1)
+	mockFileHandler.EXPECT().RenameFile(filepath.Join(workDir, "b.pdf"), "second").Return(filepath.Join(workDir, "second.pdf"), nil).Times(1)
+
+	pdfManager := manager.NewPDFManager(mockFileHandler, mockPDFMetadataHandler, mockPrompter)
+	pdfManager.Defaults.WorkDir = workDir
+	if err := pdfManager.Execute(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// Rule is a declarative metadata edit. A rule applies to a file when its
+// name matches Filename and every field in Match has a matching value; a
+// rule without conditions applies to every file. Fields are keyed as
+// PDFMetadataHandler.ReadMetadata returns them, such as "Title" or
+// "dc:title".
+type Rule struct {
+	Name string `json:"name,omitempty"`
+	// Filename is a regular expression matched against the file name
+	// without its directory.
+	Filename string            `json:"filename,omitempty"`
+	Match    map[string]string `json:"match,omitempty"`
+	// Strip removes the matches of a regular expression from a field, such
+	// as "^Microsoft Word - " from the Title.
+	Strip map[string]string `json:"strip,omitempty"`
+	// Set assigns fields after stripping. Values may refer to groups of the
+	// Filename expression as $1 or ${name}; an empty value removes the
+	// field.
+	Set map[string]string `json:"set,omitempty"`
+}
+
+// RuleSet is the content of a rules file.
+type RuleSet struct {
+	Rules []Rule `json:"rules"`
+}
+
+// ReadRules reads a JSON rules file.
+func ReadRules(r io.Reader) (*RuleSet, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var set RuleSet
+	if err := dec.Decode(&set); err != nil {
+		return nil, fmt.Errorf("could not read rules: %w", err)
+	}
+	return &set, nil
+}
+
+// compiledRule is a Rule with its expressions compiled.
+type compiledRule struct {
+	Rule
+	filename *regexp.Regexp
+	match    map[string]*regexp.Regexp
+	strip    map[string]*regexp.Regexp
+}
+
+// RuleStats counts the files a rule matched and the files it changed.
+type RuleStats struct {
+	Rule    string `json:"rule"`
+	Matched int    `json:"matched"`
+	Changed int    `json:"changed"`
+}
+
+// RuleResult lists the edits the rules made to one file.
+type RuleResult struct {
+	Path string `json:"path"`
+	// Changes holds the new value of each changed field, empty when the
+	// field is removed.
+	Changes map[string]string `json:"changes,omitempty"`
+	// Rules names the rules that changed the file, in order.
+	Rules []string `json:"rules,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// RulesReport is the outcome of applying rules to a set of files.
+type RulesReport struct {
+	DryRun bool         `json:"dryRun"`
+	Files  []RuleResult `json:"files"`
+	Stats  []RuleStats  `json:"stats"`
+}
+
+// RulesEngine evaluates rules against the metadata and name of files and
+// writes the resulting edits.
+type RulesEngine struct {
+	Metadata pdf.PDFMetadataHandler
+	rules    []*compiledRule
+}
+
+// NewRulesEngine compiles the rules of set, which are evaluated in order.
+func NewRulesEngine(metadata pdf.PDFMetadataHandler, set *RuleSet) (*RulesEngine, error) {
+	engine := &RulesEngine{Metadata: metadata}
+	for i, rule := range set.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, err)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+func compileRule(rule Rule) (*compiledRule, error) {
+	if len(rule.Set) == 0 && len(rule.Strip) == 0 {
+		return nil, errors.New("rule has neither set nor strip")
+	}
+	c := &compiledRule{Rule: rule, match: map[string]*regexp.Regexp{}, strip: map[string]*regexp.Regexp{}}
+	var err error
+	if rule.Filename != "" {
+		if c.filename, err = regexp.Compile(rule.Filename); err != nil {
+			return nil, fmt.Errorf("invalid filename pattern: %w", err)
+		}
+	}
+	for field, pattern := range rule.Match {
+		if c.match[field], err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern for %s: %w", field, err)
+		}
+	}
+	for field, pattern := range rule.Strip {
+		if c.strip[field], err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid strip pattern for %s: %w", field, err)
+		}
+	}
+	return c, nil
+}
+
+// Apply evaluates the rules for each file and writes the changed fields,
+// unless dryRun is set. Each rule sees the edits of the rules before it.
+// Files that cannot be read or written are reported in their result.
+func (e *RulesEngine) Apply(paths []string, dryRun bool) *RulesReport {
+	report := &RulesReport{DryRun: dryRun, Files: []RuleResult{}, Stats: make([]RuleStats, len(e.rules))}
+	for i, rule := range e.rules {
+		report.Stats[i].Rule = rule.Name
+	}
+	for _, path := range paths {
+		result := RuleResult{Path: path}
+		if err := e.applyFile(path, dryRun, &result, report.Stats); err != nil {
+			result.Error = err.Error()
+		}
+		report.Files = append(report.Files, result)
+	}
+	return report
+}
+
+func (e *RulesEngine) applyFile(path string, dryRun bool, result *RuleResult, stats []RuleStats) error {
+	current, err := e.Metadata.ReadMetadata(path)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]string, len(current))
+	for k, v := range current {
+		fields[k] = v
+	}
+	name := filepath.Base(path)
+	for i, rule := range e.rules {
+		submatches, ok := rule.matches(name, fields)
+		if !ok {
+			continue
+		}
+		stats[i].Matched++
+		if rule.apply(name, submatches, fields) {
+			stats[i].Changed++
+			result.Rules = append(result.Rules, rule.Name)
+		}
+	}
+
+	changes := map[string]string{}
+	for k, v := range fields {
+		if v != current[k] {
+			changes[k] = v
+		}
+	}
+	for k := range current {
+		if _, ok := fields[k]; !ok {
+			changes[k] = ""
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	result.Changes = changes
+	if dryRun {
+		return nil
+	}
+	return e.Metadata.WriteMetadata(path, changes)
+}
+
+// matches reports whether the rule applies, returning the submatch indexes
+// of the filename expression.
+func (r *compiledRule) matches(name string, fields map[string]string) ([]int, bool) {
+	var submatches []int
+	if r.filename != nil {
+		if submatches = r.filename.FindStringSubmatchIndex(name); submatches == nil {
+			return nil, false
+		}
+	}
+	for field, re := range r.match {
+		if !re.MatchString(fields[field]) {
+			return nil, false
+		}
+	}
+	return submatches, true
+}
+
+// apply edits fields and reports whether any value changed.
+func (r *compiledRule) apply(name string, submatches []int, fields map[string]string) bool {
+	changed := false
+	update := func(field, value string) {
+		old, ok := fields[field]
+		switch {
+		case value == "" && ok:
+			delete(fields, field)
+			changed = true
+		case value != "" && value != old:
+			fields[field] = value
+			changed = true
+		}
+	}
+	for _, field := range sortedKeys(r.strip) {
+		if value, ok := fields[field]; ok {
+			update(field, strings.TrimSpace(r.strip[field].ReplaceAllString(value, "")))
+		}
+	}
+	for _, field := range sortedKeys(r.Set) {
+		value := r.Set[field]
+		if r.filename != nil {
+			value = string(r.filename.ExpandString(nil, value, name, submatches))
+		}
+		update(field, value)
+	}
+	return changed
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,101 @@
+package manager_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+const testRules = `{"rules": [
+	{"name": "invoices", "filename": "^INV-(?P<num>\\d+)", "set": {"Title": "Invoice ${num}", "Subject": "Billing"}},
+	{"name": "word prefix", "strip": {"Title": "^Microsoft Word - "}},
+	{"name": "drafts", "match": {"Title": "(?i)draft"}, "set": {"Keywords": "draft"}},
+	{"name": "producer", "set": {"Producer": "Example Corp", "Creator": ""}}
+]}`
+
+func newRulesEngine(t *testing.T, metadata *mocks.MockPDFMetadataHandler) *manager.RulesEngine {
+	t.Helper()
+	set, err := manager.ReadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("ReadRules failed: %v", err)
+	}
+	engine, err := manager.NewRulesEngine(metadata, set)
+	if err != nil {
+		t.Fatalf("NewRulesEngine failed: %v", err)
+	}
+	return engine
+}
+
+func TestRulesEngine_Apply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	metadata.EXPECT().ReadMetadata("/in/INV-0042.pdf").Return(map[string]string{"Title": "scan"}, nil)
+	metadata.EXPECT().ReadMetadata("/in/notes.pdf").Return(map[string]string{"Title": "Microsoft Word - Draft notes", "Creator": "Word"}, nil)
+	metadata.EXPECT().ReadMetadata("/in/done.pdf").Return(map[string]string{"Title": "Done", "Producer": "Example Corp"}, nil)
+	metadata.EXPECT().ReadMetadata("/in/broken.pdf").Return(nil, errors.New("could not open PDF file"))
+	metadata.EXPECT().WriteMetadata("/in/INV-0042.pdf", map[string]string{"Title": "Invoice 0042", "Subject": "Billing", "Producer": "Example Corp"}).Return(nil)
+	metadata.EXPECT().WriteMetadata("/in/notes.pdf", map[string]string{"Title": "Draft notes", "Keywords": "draft", "Producer": "Example Corp", "Creator": ""}).Return(nil)
+
+	report := newRulesEngine(t, metadata).Apply([]string{"/in/INV-0042.pdf", "/in/notes.pdf", "/in/done.pdf", "/in/broken.pdf"}, false)
+	wantFiles := []manager.RuleResult{
+		{Path: "/in/INV-0042.pdf", Changes: map[string]string{"Title": "Invoice 0042", "Subject": "Billing", "Producer": "Example Corp"}, Rules: []string{"invoices", "producer"}},
+		{Path: "/in/notes.pdf", Changes: map[string]string{"Title": "Draft notes", "Keywords": "draft", "Producer": "Example Corp", "Creator": ""}, Rules: []string{"word prefix", "drafts", "producer"}},
+		{Path: "/in/done.pdf"},
+		{Path: "/in/broken.pdf", Error: "could not open PDF file"},
+	}
+	if !reflect.DeepEqual(report.Files, wantFiles) {
+		t.Errorf("expected files\n%+v\ngot\n%+v", wantFiles, report.Files)
+	}
+	wantStats := []manager.RuleStats{
+		{Rule: "invoices", Matched: 1, Changed: 1},
+		{Rule: "word prefix", Matched: 3, Changed: 1},
+		{Rule: "drafts", Matched: 1, Changed: 1},
+		{Rule: "producer", Matched: 3, Changed: 2},
+	}
+	if !reflect.DeepEqual(report.Stats, wantStats) {
+		t.Errorf("expected stats %+v, got %+v", wantStats, report.Stats)
+	}
+}
+
+func TestRulesEngine_Apply_DryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	metadata.EXPECT().ReadMetadata("INV-7.pdf").Return(map[string]string{}, nil)
+	metadata.EXPECT().WriteMetadata(gomock.Any(), gomock.Any()).Times(0)
+
+	report := newRulesEngine(t, metadata).Apply([]string{"INV-7.pdf"}, true)
+	if !report.DryRun || report.Files[0].Changes["Title"] != "Invoice 7" {
+		t.Errorf("expected a dry-run report with the new title, got %+v", report)
+	}
+}
+
+func TestNewRulesEngine_InvalidRules(t *testing.T) {
+	tests := map[string]string{
+		`{"rules": [{"filename": "("}]}`:                           "rule 1: rule has neither set nor strip",
+		`{"rules": [{"name": "x", "filename": "(", "set": {}}]}`:   "x: rule has neither set nor strip",
+		`{"rules": [{"filename": "(", "set": {"Title": "t"}}]}`:    "rule 1: invalid filename pattern",
+		`{"rules": [{"strip": {"Title": "["}}]}`:                   "rule 1: invalid strip pattern for Title",
+		`{"rules": [{"match": {"Title": "["}, "set": {"a": ""}}]}`: "rule 1: invalid pattern for Title",
+	}
+	for rules, want := range tests {
+		set, err := manager.ReadRules(strings.NewReader(rules))
+		if err != nil {
+			t.Fatalf("ReadRules(%s) failed: %v", rules, err)
+		}
+		if _, err := manager.NewRulesEngine(nil, set); err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("expected error %q for %s, got %v", want, rules, err)
+		}
+	}
+	if _, err := manager.ReadRules(strings.NewReader(`{"rules": [{"title": "x"}]}`)); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
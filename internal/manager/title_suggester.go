@@ -0,0 +1,301 @@
+package manager
+
+import (
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// Sources a title candidate can come from.
+const (
+	SourceHeading  = "heading"
+	SourceOutline  = "outline"
+	SourceFilename = "filename"
+	SourceMetadata = "metadata"
+)
+
+// TitleCandidate is a proposed document title.
+type TitleCandidate struct {
+	Title  string `json:"title"`
+	Source string `json:"source"`
+	// Confidence ranges from 0 to 1.
+	Confidence float64 `json:"confidence"`
+}
+
+// TitleSuggestion holds the current title of a document and ranked
+// replacement candidates, best first.
+type TitleSuggestion struct {
+	Current    string           `json:"current"`
+	NeedsTitle bool             `json:"needsTitle"`
+	Candidates []TitleCandidate `json:"candidates"`
+}
+
+// Best returns the highest ranked candidate.
+func (s *TitleSuggestion) Best() (TitleCandidate, bool) {
+	if len(s.Candidates) == 0 {
+		return TitleCandidate{}, false
+	}
+	return s.Candidates[0], true
+}
+
+// TitleSuggester proposes titles from document content, bookmarks and the
+// file name.
+type TitleSuggester struct {
+	Info     pdf.InfoHandler
+	Outlines pdf.OutlineHandler
+	Content  pdf.ContentReader
+}
+
+func NewTitleSuggester(info pdf.InfoHandler, outlines pdf.OutlineHandler, content pdf.ContentReader) *TitleSuggester {
+	return &TitleSuggester{
+		Info:     info,
+		Outlines: outlines,
+		Content:  content,
+	}
+}
+
+// toolPrefix matches the application name Office puts in front of titles.
+var toolPrefix = regexp.MustCompile(`(?i)^microsoft (word|powerpoint|excel) - `)
+
+// placeholderTitles match titles that authoring tools fill in by default.
+var placeholderTitles = []*regexp.Regexp{
+	toolPrefix,
+	regexp.MustCompile(`(?i)\.(docx?|odt|rtf|txt|xlsx?|pptx?|indd|tex|dvi|pdf)$`),
+	regexp.MustCompile(`(?i)^(untitled|document\d*|title|no title|slide \d+|presentation\d*|book\d*)$`),
+}
+
+// NeedsTitle reports whether title is missing or an authoring tool default.
+func NeedsTitle(title string) bool {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return true
+	}
+	for _, re := range placeholderTitles {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest collects title candidates for the PDF file. Sources that cannot
+// be read are skipped, so only reading the Info dictionary can fail.
+func (ts *TitleSuggester) Suggest(filePath string) (*TitleSuggestion, error) {
+	info, err := ts.Info.ReadInfo(filePath)
+	if err != nil {
+		return nil, err
+	}
+	suggestion := &TitleSuggestion{Current: info["Title"], NeedsTitle: NeedsTitle(info["Title"])}
+
+	var candidates []TitleCandidate
+	if spans, err := ts.Content.ReadPageTextSpans(filePath, 1); err == nil {
+		if c, ok := headingCandidate(spans); ok {
+			candidates = append(candidates, c)
+		}
+	}
+	if items, err := ts.Outlines.ReadOutline(filePath); err == nil {
+		if c, ok := outlineCandidate(items); ok {
+			candidates = append(candidates, c)
+		}
+	}
+	if c, ok := metadataCandidate(suggestion.Current); ok {
+		candidates = append(candidates, c)
+	}
+	if c, ok := filenameCandidate(filePath); ok {
+		candidates = append(candidates, c)
+	}
+	suggestion.Candidates = rankCandidates(candidates)
+	return suggestion, nil
+}
+
+// ApplyBest writes the best candidate as the title when the current title
+// needs replacing and the candidate reaches minConfidence. It reports the
+// candidate and whether it was written.
+func (ts *TitleSuggester) ApplyBest(filePath string, minConfidence float64) (TitleCandidate, bool, error) {
+	suggestion, err := ts.Suggest(filePath)
+	if err != nil {
+		return TitleCandidate{}, false, err
+	}
+	best, ok := suggestion.Best()
+	if !ok || !suggestion.NeedsTitle || best.Confidence < minConfidence {
+		return best, false, nil
+	}
+	if err := ts.Info.WriteInfo(filePath, map[string]string{"Title": best.Title}); err != nil {
+		return best, false, err
+	}
+	return best, true, nil
+}
+
+// headingCandidate uses the text set in the largest font on the page. Lines
+// in that size that directly follow each other form a multi-line title.
+func headingCandidate(spans []pdf.TextSpan) (TitleCandidate, bool) {
+	var largest float64
+	var sizes []float64
+	for _, s := range spans {
+		if strings.TrimSpace(s.Text) == "" {
+			continue
+		}
+		largest = math.Max(largest, s.FontSize)
+		for range []rune(s.Text) {
+			sizes = append(sizes, s.FontSize)
+		}
+	}
+	if largest == 0 {
+		return TitleCandidate{}, false
+	}
+
+	var parts []string
+	var lastY float64
+	for _, s := range spans {
+		if strings.TrimSpace(s.Text) == "" {
+			continue
+		}
+		if s.FontSize < largest-0.5 {
+			if len(parts) > 0 {
+				break
+			}
+			continue
+		}
+		if len(parts) > 0 && math.Abs(lastY-s.Y) > 2*largest {
+			break
+		}
+		parts = append(parts, s.Text)
+		lastY = s.Y
+	}
+	title := cleanTitle(strings.Join(parts, " "))
+
+	sort.Float64s(sizes)
+	ratio := largest / sizes[len(sizes)/2]
+	confidence := 0.3
+	if ratio >= 1.15 {
+		confidence = 0.5 + math.Min(0.4, (ratio-1)*0.4)
+	}
+	return TitleCandidate{Title: title, Source: SourceHeading, Confidence: plausibility(title) * confidence}, title != ""
+}
+
+// genericOutlineTitles are bookmarks that do not describe the document.
+var genericOutlineTitles = regexp.MustCompile(`(?i)^(cover( page)?|title page|(table of )?contents|toc|front matter|copyright)$`)
+
+// outlineCandidate uses the first descriptive top-level bookmark.
+func outlineCandidate(items []*pdf.OutlineItem) (TitleCandidate, bool) {
+	for i, item := range items {
+		title := cleanTitle(item.Title)
+		if title == "" || genericOutlineTitles.MatchString(title) {
+			continue
+		}
+		confidence := 0.6
+		if i > 0 {
+			confidence = 0.5
+		}
+		return TitleCandidate{Title: title, Source: SourceOutline, Confidence: plausibility(title) * confidence}, true
+	}
+	return TitleCandidate{}, false
+}
+
+var fileExtension = regexp.MustCompile(`(?i)\.[a-z]{2,4}$`)
+
+// metadataCandidate salvages a placeholder title such as "Microsoft Word -
+// draft3.docx" by stripping the tool prefix and file extension.
+func metadataCandidate(current string) (TitleCandidate, bool) {
+	if !NeedsTitle(current) {
+		return TitleCandidate{Title: current, Source: SourceMetadata, Confidence: 1}, true
+	}
+	title := toolPrefix.ReplaceAllString(strings.TrimSpace(current), "")
+	title = cleanTitle(humanizeName(fileExtension.ReplaceAllString(title, "")))
+	if title == "" || NeedsTitle(title) {
+		return TitleCandidate{}, false
+	}
+	return TitleCandidate{Title: title, Source: SourceMetadata, Confidence: 0.25 * plausibility(title)}, true
+}
+
+var scannerNames = regexp.MustCompile(`(?i)^(scan|img|image|doc|document|file|untitled|page)[ _-]*\d*$`)
+
+// filenameCandidate derives a title from the file name.
+func filenameCandidate(filePath string) (TitleCandidate, bool) {
+	base := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	title := cleanTitle(humanizeName(base))
+	if title == "" {
+		return TitleCandidate{}, false
+	}
+	confidence := 0.3
+	if scannerNames.MatchString(base) {
+		confidence = 0.05
+	}
+	return TitleCandidate{Title: title, Source: SourceFilename, Confidence: plausibility(title) * confidence}, true
+}
+
+// humanizeName turns separators in file names into spaces and capitalizes
+// names written entirely in lower case.
+func humanizeName(name string) string {
+	name = strings.NewReplacer("_", " ", "-", " ", ".", " ").Replace(name)
+	if strings.ToLower(name) != name {
+		return name
+	}
+	words := strings.Fields(name)
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
+
+// cleanTitle collapses whitespace and trims punctuation left over from
+// layout, such as trailing colons or leading bullets.
+func cleanTitle(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("•·-–—:;,|", r)
+	})
+}
+
+// plausibility scores how much text looks like a title, from 0 to 1.
+func plausibility(title string) float64 {
+	runes := []rune(title)
+	if len(runes) < 3 {
+		return 0.2
+	}
+	letters := 0
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	score := 1.0
+	if float64(letters)/float64(len(runes)) < 0.5 {
+		score *= 0.4
+	}
+	if len(runes) > 150 {
+		score *= 0.5
+	}
+	return score
+}
+
+// rankCandidates merges candidates with the same text, raising the
+// confidence when sources agree, and sorts them best first.
+func rankCandidates(candidates []TitleCandidate) []TitleCandidate {
+	var ranked []TitleCandidate
+	index := map[string]int{}
+	for _, c := range candidates {
+		key := strings.ToLower(c.Title)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(ranked)
+			ranked = append(ranked, c)
+			continue
+		}
+		existing := &ranked[i]
+		if c.Confidence > existing.Confidence {
+			existing.Title = c.Title
+		}
+		existing.Confidence = math.Min(0.99, math.Max(existing.Confidence, c.Confidence)+0.15)
+		existing.Source += "+" + c.Source
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Confidence > ranked[j].Confidence })
+	return ranked
+}
@@ -0,0 +1,136 @@
+package manager_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+func TestNeedsTitle(t *testing.T) {
+	tests := map[string]bool{
+		"":                             true,
+		"  ":                           true,
+		"Untitled":                     true,
+		"Document1":                    true,
+		"Microsoft Word - report.docx": true,
+		"draft.doc":                    true,
+		"Annual Report 2023":           false,
+		"A Study of PDF Internals":     false,
+	}
+	for title, want := range tests {
+		if got := manager.NeedsTitle(title); got != want {
+			t.Errorf("NeedsTitle(%q) = %v, want %v", title, got, want)
+		}
+	}
+}
+
+// newSuggester returns a suggester whose sources report the given title,
+// first page spans and outline for every file.
+func newSuggester(ctrl *gomock.Controller, title string, spans []pdf.TextSpan, outline []*pdf.OutlineItem) (*manager.TitleSuggester, *mocks.MockInfoHandler) {
+	info := mocks.NewMockInfoHandler(ctrl)
+	outlines := mocks.NewMockOutlineHandler(ctrl)
+	content := mocks.NewMockContentReader(ctrl)
+	info.EXPECT().ReadInfo(gomock.Any()).Return(map[string]string{"Title": title}, nil).AnyTimes()
+	content.EXPECT().ReadPageTextSpans(gomock.Any(), 1).Return(spans, nil).AnyTimes()
+	outlines.EXPECT().ReadOutline(gomock.Any()).Return(outline, nil).AnyTimes()
+	return manager.NewTitleSuggester(info, outlines, content), info
+}
+
+var headingSpans = []pdf.TextSpan{
+	{Text: "Annual Report", Y: 700, FontSize: 24},
+	{Text: "2023", Y: 672, FontSize: 24},
+	{Text: "This report covers the activities of the year.", Y: 600, FontSize: 10},
+	{Text: "Revenue grew in every quarter.", Y: 588, FontSize: 10},
+}
+
+func TestTitleSuggester_Suggest_RanksAgreeingSources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	outline := []*pdf.OutlineItem{{Title: "Contents", Page: 2}, {Title: "Annual Report 2023", Page: 3}}
+	suggester, _ := newSuggester(ctrl, "Microsoft Word - ar_final.docx", headingSpans, outline)
+
+	suggestion, err := suggester.Suggest("/docs/scan_0001.pdf")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !suggestion.NeedsTitle {
+		t.Error("expected placeholder title to need replacing")
+	}
+	best, ok := suggestion.Best()
+	if !ok {
+		t.Fatal("expected a suggestion")
+	}
+	if best.Title != "Annual Report 2023" || best.Source != "heading+outline" {
+		t.Errorf("unexpected best candidate %+v", best)
+	}
+	if len(suggestion.Candidates) != 3 {
+		t.Errorf("expected heading, metadata and file name candidates, got %+v", suggestion.Candidates)
+	}
+	last := suggestion.Candidates[len(suggestion.Candidates)-1]
+	if last.Source != manager.SourceFilename || last.Title != "Scan 0001" {
+		t.Errorf("expected file name candidate last, got %+v", last)
+	}
+}
+
+func TestTitleSuggester_ApplyBest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	suggester, info := newSuggester(ctrl, "", headingSpans, nil)
+	info.EXPECT().WriteInfo("report.pdf", map[string]string{"Title": "Annual Report 2023"}).Return(nil).Times(1)
+
+	best, written, err := suggester.ApplyBest("report.pdf", 0.6)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !written || best.Title != "Annual Report 2023" {
+		t.Errorf("expected title to be written, got %+v written=%v", best, written)
+	}
+}
+
+func TestTitleSuggester_ApplyBest_BelowThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Only the file name is available, which is not trusted enough.
+	suggester, info := newSuggester(ctrl, "", nil, nil)
+	info.EXPECT().WriteInfo(gomock.Any(), gomock.Any()).Times(0)
+
+	best, written, err := suggester.ApplyBest("quarterly_results.pdf", 0.6)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if written || best.Title != "Quarterly Results" {
+		t.Errorf("expected file name suggestion not to be written, got %+v written=%v", best, written)
+	}
+}
+
+func TestTitleSuggester_ApplyBest_KeepsGoodTitle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	suggester, info := newSuggester(ctrl, "Existing Title", headingSpans, nil)
+	info.EXPECT().WriteInfo(gomock.Any(), gomock.Any()).Times(0)
+
+	if _, written, err := suggester.ApplyBest("report.pdf", 0); err != nil || written {
+		t.Errorf("expected existing title to be kept, got written=%v err=%v", written, err)
+	}
+}
+
+func TestTitleSuggester_Suggest_InfoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	info := mocks.NewMockInfoHandler(ctrl)
+	info.EXPECT().ReadInfo("broken.pdf").Return(nil, errors.New("could not open PDF file")).Times(1)
+	suggester := manager.NewTitleSuggester(info, mocks.NewMockOutlineHandler(ctrl), mocks.NewMockContentReader(ctrl))
+
+	if _, err := suggester.Suggest("broken.pdf"); err == nil {
+		t.Error("expected an error")
+	}
+}
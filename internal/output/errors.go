@@ -0,0 +1,80 @@
+package output
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// Error codes. The serve API reports the same codes.
+const (
+	// CodeUsage means the command was called with invalid arguments.
+	CodeUsage = "usage"
+	// CodeFailed means the command failed for some of its files, which
+	// have error records of their own.
+	CodeFailed = "failed"
+	// CodeInvalid means a file did not pass validation.
+	CodeInvalid               = "invalid"
+	CodeNotFound              = "not_found"
+	CodeExists                = "exists"
+	CodePermission            = "permission_denied"
+	CodeNotPDF                = "not_pdf"
+	CodeMalformed             = "malformed_pdf"
+	CodeEncrypted             = "encrypted_pdf"
+	CodeUnsupportedFilter     = "unsupported_filter"
+	CodeUnsupportedColorSpace = "unsupported_color_space"
+	CodePageOutOfRange        = "page_out_of_range"
+	CodeInternal              = "internal"
+)
+
+// codedError is an error with the code to report it with.
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// WithCode returns err reported with code.
+func WithCode(code string, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// pdfErrors maps the errors of the pdf package to a code.
+var pdfErrors = []struct {
+	err  error
+	code string
+}{
+	{pdf.ErrNotPDF, CodeNotPDF},
+	{pdf.ErrEncrypted, CodeEncrypted},
+	{pdf.ErrMalformed, CodeMalformed},
+	{pdf.ErrUnsupportedFilter, CodeUnsupportedFilter},
+	{pdf.ErrUnsupportedColorSpace, CodeUnsupportedColorSpace},
+	{pdf.ErrPageOutOfRange, CodePageOutOfRange},
+}
+
+// Code returns the code err is reported with: the code given by WithCode,
+// or else the code of the pdf or file system error it wraps, or
+// CodeInternal.
+func Code(err error) string {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	for _, e := range pdfErrors {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return CodeNotFound
+	case errors.Is(err, fs.ErrExist):
+		return CodeExists
+	case errors.Is(err, fs.ErrPermission):
+		return CodePermission
+	}
+	return CodeInternal
+}
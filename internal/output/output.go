@@ -0,0 +1,75 @@
+// Package output writes the results of commands as JSON lines for scripts.
+// Each line is a record: a result, an error with a stable code, or a
+// summary of the command.
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Record types.
+const (
+	TypeResult  = "result"
+	TypeError   = "error"
+	TypeSummary = "summary"
+)
+
+// Record is one line of output. File names the file a result or error is
+// about, when there is one.
+type Record struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	File    string `json:"file,omitempty"`
+	Data    any    `json:"data,omitempty"`
+	Error   *Error `json:"error,omitempty"`
+}
+
+// Error describes a failure. Code is one of the Code constants and does not
+// change between releases; Message is meant for people.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Writer writes the records of a command. It is safe for concurrent use.
+type Writer struct {
+	command string
+	mu      sync.Mutex
+	enc     *json.Encoder
+}
+
+// NewWriter returns a Writer of the records of command to w.
+func NewWriter(w io.Writer, command string) *Writer {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &Writer{command: command, enc: enc}
+}
+
+// Result writes a result about file, which may be empty.
+func (w *Writer) Result(file string, data any) error {
+	return w.write(Record{Type: TypeResult, Command: w.command, File: file, Data: data})
+}
+
+// Summary writes the totals of a command that wrote a result per file.
+func (w *Writer) Summary(data any) error {
+	return w.write(Record{Type: TypeSummary, Command: w.command, Data: data})
+}
+
+// Error writes err, with the code from Code, as an error about file, which
+// may be empty.
+func (w *Writer) Error(file string, err error) error {
+	return w.write(Record{
+		Type:    TypeError,
+		Command: w.command,
+		File:    file,
+		Error:   &Error{Code: Code(err), Message: err.Error()},
+	})
+}
+
+func (w *Writer) write(r Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(r)
+}
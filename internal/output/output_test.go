@@ -0,0 +1,49 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/output"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{output.WithCode(output.CodeUsage, errors.New("missing file")), output.CodeUsage},
+		{fmt.Errorf("open: %w", output.WithCode(output.CodeFailed, fs.ErrNotExist)), output.CodeFailed},
+		{fmt.Errorf("could not open PDF file: %w", pdf.ErrNotPDF), output.CodeNotPDF},
+		{fmt.Errorf("read: %w", pdf.ErrEncrypted), output.CodeEncrypted},
+		{&fs.PathError{Op: "open", Path: "a.pdf", Err: fs.ErrNotExist}, output.CodeNotFound},
+		{fmt.Errorf("%w: b.pdf", fs.ErrExist), output.CodeExists},
+		{fs.ErrPermission, output.CodePermission},
+		{errors.New("something else"), output.CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := output.Code(tt.err); got != tt.want {
+			t.Errorf("Code(%v) = %q; want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := output.NewWriter(&buf, "rules")
+	w.Result("a.pdf", map[string]string{"Title": "<Draft>"})
+	w.Error("b.pdf", fmt.Errorf("could not open PDF file: %w", pdf.ErrNotPDF))
+	w.Summary(map[string]int{"files": 2})
+
+	want := `{"type":"result","command":"rules","file":"a.pdf","data":{"Title":"<Draft>"}}
+{"type":"error","command":"rules","file":"b.pdf","error":{"code":"not_pdf","message":"could not open PDF file: not a PDF file"}}
+{"type":"summary","command":"rules","data":{"files":2}}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected records:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Attachment is a file embedded in the document through the EmbeddedFiles
+// name tree. Files attached to page annotations are not included.
+type Attachment struct {
+	Name string `json:"name"`
+	// Size is the length of the decoded file, or of the stored data when it
+	// cannot be decoded.
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Attachments lists the embedded files of the document in the order of the
+// name tree.
+func (d *Document) Attachments() ([]Attachment, error) {
+	catalog, _, err := d.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	names := d.GetDict(catalog["Names"])
+	if names == nil {
+		return nil, nil
+	}
+	var attachments []Attachment
+	d.walkNameTree(names["EmbeddedFiles"], func(key String, value Object) bool {
+		spec := d.GetDict(value)
+		name := DecodeTextString(key)
+		if s, ok := d.GetString(spec["UF"]); ok {
+			name = DecodeTextString(s)
+		} else if s, ok := d.GetString(spec["F"]); ok && name == "" {
+			name = DecodeTextString(s)
+		}
+		a := Attachment{Name: name}
+		if s := d.GetStream(d.GetDict(spec["EF"])["F"]); s != nil {
+			data, err := d.DecodeStream(s)
+			if err != nil {
+				data = s.Data
+			}
+			sum := sha256.Sum256(data)
+			a.Size, a.SHA256 = len(data), hex.EncodeToString(sum[:])
+		}
+		attachments = append(attachments, a)
+		return true
+	})
+	return attachments, nil
+}
@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"unicode/utf16"
+)
+
+// codespaceRange is a range of character codes of a fixed byte length.
+type codespaceRange struct {
+	low, high []byte
+}
+
+func (r codespaceRange) contains(code []byte) bool {
+	if len(code) != len(r.low) {
+		return false
+	}
+	for i, c := range code {
+		if c < r.low[i] || c > r.high[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toUnicodeCMap maps character codes to text, as read from a /ToUnicode
+// stream.
+type toUnicodeCMap struct {
+	codespaces []codespaceRange
+	mapping    map[string]string
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a CMap program.
+func parseToUnicode(data []byte) *toUnicodeCMap {
+	cm := &toUnicodeCMap{mapping: map[string]string{}}
+	p := newParser(data)
+	var operands []Object
+	for !p.eof() {
+		obj, err := p.parseObject()
+		if err != nil {
+			// Keep whatever was mapped before the damage.
+			break
+		}
+		kw, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				low, _ := operands[i].(String)
+				high, _ := operands[i+1].(String)
+				if len(low) > 0 && len(low) == len(high) {
+					cm.codespaces = append(cm.codespaces, codespaceRange{[]byte(low), []byte(high)})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, _ := operands[i].(String)
+				dst, _ := operands[i+1].(String)
+				cm.mapping[string(src)] = utf16BEString(dst)
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				cm.addRange(operands[i], operands[i+1], operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+	return cm
+}
+
+// addRange adds a bfrange entry. The destination is either the text of the
+// first code, incremented for each following code, or an array with the
+// text of every code.
+func (cm *toUnicodeCMap) addRange(lowObj, highObj, dst Object) {
+	low, _ := lowObj.(String)
+	high, _ := highObj.(String)
+	if len(low) == 0 || len(low) != len(high) {
+		return
+	}
+	lo, hi := beUint([]byte(low)), beUint([]byte(high))
+	if hi < lo || hi-lo > 0xFFFF {
+		return
+	}
+	for i := uint64(0); i <= hi-lo; i++ {
+		code := make([]byte, len(low))
+		v := lo + i
+		for j := len(code) - 1; j >= 0; j-- {
+			code[j] = byte(v)
+			v >>= 8
+		}
+		switch d := dst.(type) {
+		case String:
+			if len(d) == 0 {
+				continue
+			}
+			text := []byte(d)
+			text = append([]byte{}, text...)
+			text[len(text)-1] += byte(i)
+			cm.mapping[string(code)] = utf16BEString(String(text))
+		case Array:
+			if int(i) < len(d) {
+				if s, ok := d[i].(String); ok {
+					cm.mapping[string(code)] = utf16BEString(s)
+				}
+			}
+		}
+	}
+}
+
+// codeLength returns the byte length of the code starting at b.
+func (cm *toUnicodeCMap) codeLength(b []byte) int {
+	for n := 1; n <= 4 && n <= len(b); n++ {
+		for _, r := range cm.codespaces {
+			if r.contains(b[:n]) {
+				return n
+			}
+		}
+	}
+	if len(cm.codespaces) > 0 {
+		return len(cm.codespaces[0].low)
+	}
+	for n := 1; n <= 4 && n <= len(b); n++ {
+		if _, ok := cm.mapping[string(b[:n])]; ok {
+			return n
+		}
+	}
+	return 1
+}
+
+func utf16BEString(s String) string {
+	b := []byte(s)
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
@@ -0,0 +1,263 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"math"
+	"sort"
+)
+
+// DefaultJPEGQuality is the JPEG quality used when none is given.
+const DefaultJPEGQuality = 75
+
+// ImageCompression configures CompressImages.
+type ImageCompression struct {
+	// DPI is the highest resolution kept: images shown at a higher
+	// resolution are downsampled to it. Zero keeps the resolution.
+	DPI float64
+	// Quality is the JPEG quality from 1 to 100.
+	Quality int
+}
+
+// PageSavings reports the images CompressImages replaced on a page. An
+// image used on several pages is counted on the first.
+type PageSavings struct {
+	Page       int `json:"page"`
+	Images     int `json:"images"`
+	SizeBefore int `json:"sizeBefore"`
+	SizeAfter  int `json:"sizeAfter"`
+}
+
+// imagePlacement records the first page an image is drawn on and the lowest
+// resolution it is shown at, in pixels per inch.
+type imagePlacement struct {
+	page int
+	dpi  float64
+}
+
+// CompressImages re-encodes the images drawn on the pages as JPEG at the
+// given quality, first downsampling those shown above the target DPI. The
+// image XObjects are replaced in place, and only when the result is
+// smaller. Bilevel images, stencil masks, images masked by color, images in
+// color spaces other than gray and RGB, and encodings that cannot be decoded
+// are left alone.
+func (d *Document) CompressImages(opts ImageCompression) ([]PageSavings, error) {
+	if d.Encrypted() {
+		return nil, ErrEncrypted
+	}
+	if opts.Quality < 1 || opts.Quality > 100 {
+		return nil, fmt.Errorf("invalid JPEG quality %d", opts.Quality)
+	}
+	placements, order, err := d.imagePlacements()
+	if err != nil {
+		return nil, err
+	}
+
+	byPage := map[int]*PageSavings{}
+	for _, ref := range order {
+		s := d.GetStream(ref)
+		placement := placements[ref]
+		replacement, ok := d.compressImage(s, placement.dpi, opts)
+		if !ok {
+			continue
+		}
+		d.Set(ref, replacement)
+		page := byPage[placement.page]
+		if page == nil {
+			page = &PageSavings{Page: placement.page}
+			byPage[placement.page] = page
+		}
+		page.Images++
+		page.SizeBefore += len(s.Data)
+		page.SizeAfter += len(replacement.Data)
+	}
+
+	savings := make([]PageSavings, 0, len(byPage))
+	for _, page := range byPage {
+		savings = append(savings, *page)
+	}
+	sort.Slice(savings, func(i, j int) bool { return savings[i].Page < savings[j].Page })
+	return savings, nil
+}
+
+// imagePlacements finds the image XObjects drawn by the page contents, in
+// the order they are first drawn. Pages whose content cannot be decoded are
+// skipped.
+func (d *Document) imagePlacements() (map[Ref]*imagePlacement, []Ref, error) {
+	pages, err := d.Pages()
+	if err != nil {
+		return nil, nil, err
+	}
+	placements := map[Ref]*imagePlacement{}
+	var order []Ref
+	w := newContentWalker(d)
+	for i, page := range pages {
+		w.onImage = func(ref Ref, s *Stream, ctm matrix) {
+			width, _ := d.GetNumber(s.Dict["Width"])
+			height, _ := d.GetNumber(s.Dict["Height"])
+			// The image fills the unit square, so the matrix gives its size
+			// on the page in points.
+			shownWidth := math.Hypot(ctm[0], ctm[1]) / 72
+			shownHeight := math.Hypot(ctm[2], ctm[3]) / 72
+			if ref.Num == 0 || shownWidth == 0 || shownHeight == 0 {
+				return
+			}
+			dpi := math.Min(width/shownWidth, height/shownHeight)
+			if p, ok := placements[ref]; ok {
+				p.dpi = math.Min(p.dpi, dpi)
+				return
+			}
+			placements[ref] = &imagePlacement{page: i + 1, dpi: dpi}
+			order = append(order, ref)
+		}
+		if err := w.walkPage(page); err != nil {
+			continue
+		}
+	}
+	return placements, order, nil
+}
+
+// compressImage returns s re-encoded as JPEG, downsampled when it is shown
+// above the target resolution, or false if it should be kept.
+func (d *Document) compressImage(s *Stream, dpi float64, opts ImageCompression) (*Stream, bool) {
+	bpc, _ := d.GetInt(s.Dict["BitsPerComponent"])
+	if d.resolveQuiet(s.Dict["ImageMask"]) == true || bpc == 1 || d.GetArray(s.Dict["Mask"]) != nil {
+		return nil, false
+	}
+	colorSpace, gray, ok := d.jpegColorSpace(s.Dict["ColorSpace"], false)
+	if !ok {
+		return nil, false
+	}
+	data, codec, err := d.imageData(s)
+	if err != nil {
+		return nil, false
+	}
+	switch codec {
+	case "":
+	case "DCTDecode", "DCT":
+		// The JPEG decoder ignores /Decode, so such images are left
+		// unchanged.
+		if s.Dict["Decode"] != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	img, err := d.rasterize(s, data, codec)
+	if err != nil {
+		return nil, false
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if opts.DPI > 0 && dpi > opts.DPI && d.GetDict(s.Dict["SMask"])["Matte"] == nil {
+		scale := opts.DPI / dpi
+		width = max(1, int(math.Round(float64(width)*scale)))
+		height = max(1, int(math.Round(float64(height)*scale)))
+		img = downsample(img, width, height)
+	}
+
+	var buf bytes.Buffer
+	var encoded image.Image = img
+	if gray {
+		encoded = grayImage(img)
+	}
+	if err := jpeg.Encode(&buf, encoded, &jpeg.Options{Quality: opts.Quality}); err != nil {
+		return nil, false
+	}
+	if buf.Len() >= len(s.Data) {
+		return nil, false
+	}
+
+	dict := s.Dict.Clone()
+	for _, key := range []Name{"DecodeParms", "Decode", "DL", "Length"} {
+		delete(dict, key)
+	}
+	dict["Width"] = int64(width)
+	dict["Height"] = int64(height)
+	dict["BitsPerComponent"] = int64(8)
+	dict["ColorSpace"] = colorSpace
+	dict["Filter"] = Name("DCTDecode")
+	return &Stream{Dict: dict, Data: buf.Bytes()}, true
+}
+
+// jpegColorSpace returns the color space of an image re-encoded from one in
+// the color space obj, and whether it is gray. Only gray and RGB spaces,
+// which the decoded samples are exact in, can be re-encoded: device spaces
+// become DeviceGray or DeviceRGB, calibrated and ICC-based spaces are kept,
+// and Indexed spaces are replaced by their base. CMYK, spot colors and
+// other spaces are not, since the decoded image only approximates them.
+func (d *Document) jpegColorSpace(obj Object, indexed bool) (Object, bool, bool) {
+	var family Name
+	var args Array
+	switch cs := d.resolveQuiet(obj).(type) {
+	case Name:
+		family = cs
+	case Array:
+		if len(cs) > 0 {
+			family, args = d.GetName(cs[0]), cs[1:]
+		}
+	}
+	switch family {
+	case "DeviceGray", "G":
+		return Name("DeviceGray"), true, true
+	case "DeviceRGB", "RGB":
+		return Name("DeviceRGB"), false, true
+	case "CalGray":
+		return obj, true, true
+	case "CalRGB":
+		return obj, false, true
+	case "ICCBased":
+		if len(args) > 0 {
+			switch n, _ := d.GetInt(d.GetDict(args[0])["N"]); n {
+			case 1:
+				return obj, true, true
+			case 3:
+				return obj, false, true
+			}
+		}
+	case "Indexed", "I":
+		if len(args) == 3 && !indexed {
+			return d.jpegColorSpace(args[0], true)
+		}
+	}
+	return nil, false, false
+}
+
+// downsample scales src down to width×height, averaging the source pixels
+// each target pixel covers.
+func downsample(src *image.NRGBA, width, height int) *image.NRGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy):]
+				for sx := 0; sx < x1-x0; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			i := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// grayImage keeps the red channel of an image whose channels are equal.
+func grayImage(src *image.NRGBA) *image.Gray {
+	gray := image.NewGray(src.Bounds())
+	for i := range gray.Pix {
+		gray.Pix[i] = src.Pix[i*4]
+	}
+	return gray
+}
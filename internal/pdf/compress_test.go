@@ -0,0 +1,132 @@
+package pdf_test
+
+import (
+	"bytes"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// scanPDF returns two pages showing the same 200×200 RGB image, one inch
+// wide on page 1 and two inches wide on page 2, which also shows a bilevel
+// image.
+func scanPDF() []byte {
+	pixels := make([]byte, 0, 200*200*3)
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			pixels = append(pixels, byte(x), byte(y), byte(x+y))
+		}
+	}
+	page1 := "q 72 0 0 72 100 100 cm /Im1 Do Q"
+	page2 := "q 144 0 0 144 100 100 cm /Im1 Do Q q 72 0 0 72 300 300 cm /Im2 Do Q"
+	return pdftest.Build(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /XObject << /Im1 7 0 R /Im2 8 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 6 0 R >>",
+		streamObject("", []byte(page1)),
+		streamObject("", []byte(page2)),
+		streamObject("/Type /XObject /Subtype /Image /Width 200 /Height 200 /ColorSpace /DeviceRGB /BitsPerComponent 8", pixels),
+		streamObject("/Type /XObject /Subtype /Image /Width 16 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 1", []byte{0xF0, 0x0F}),
+	)
+}
+
+func TestDocument_CompressImages(t *testing.T) {
+	doc := openPDF(t, scanPDF())
+	savings, err := doc.CompressImages(pdf.ImageCompression{DPI: 50, Quality: 60})
+	if err != nil {
+		t.Fatalf("CompressImages failed: %v", err)
+	}
+	if len(savings) != 1 || savings[0].Page != 1 || savings[0].Images != 1 || savings[0].SizeBefore != 200*200*3 {
+		t.Fatalf("Unexpected savings %+v", savings)
+	}
+	if savings[0].SizeAfter >= savings[0].SizeBefore {
+		t.Errorf("Expected the image to shrink, got %+v", savings[0])
+	}
+
+	doc = rewrite(t, doc)
+	images, err := doc.Images("")
+	if err != nil {
+		t.Fatalf("Images failed: %v", err)
+	}
+	// Shown at 100 DPI on page 2, the image is halved to reach 50 DPI.
+	im1 := images[0]
+	if im1.Width != 100 || im1.Height != 100 || im1.Filter != "DCTDecode" || im1.ColorSpace != "DeviceRGB" {
+		t.Errorf("Unexpected compressed image %+v", im1)
+	}
+	data, ext, err := doc.ExtractImage(im1.Object)
+	if err != nil || ext != ".jpg" {
+		t.Fatalf("ExtractImage failed: %v (%s)", err, ext)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("Invalid JPEG data: %v", err)
+	}
+	if im2 := images[1]; im2.Filter != "" || im2.BitsPerComponent != 1 {
+		t.Errorf("Expected the bilevel image to be kept, got %+v", im2)
+	}
+}
+
+func TestDocument_CompressImages_InvalidQuality(t *testing.T) {
+	doc := openPDF(t, scanPDF())
+	if _, err := doc.CompressImages(pdf.ImageCompression{Quality: 0}); err == nil {
+		t.Error("Expected an error for quality 0")
+	}
+}
+
+func TestDocument_CompressImages_ColorSpaces(t *testing.T) {
+	tests := []struct {
+		colorSpace string
+		comps      int
+		want       string
+	}{
+		{"/DeviceGray", 1, "DeviceGray"},
+		{"[/CalRGB << /WhitePoint [0.9505 1 1.089] >>]", 3, "CalRGB"},
+		{"[/ICCBased 5 0 R]", 3, "ICCBased"},
+		{"[/Indexed /DeviceRGB 1 <000000ffffff>]", 1, "DeviceRGB"},
+		// Only approximated when decoded, so kept as they are.
+		{"/DeviceCMYK", 4, ""},
+		{"[/ICCBased 6 0 R]", 4, ""},
+		{"[/Separation /Gold /DeviceCMYK 5 0 R]", 1, ""},
+		{"[/DeviceN [/Cyan /Gold] /DeviceCMYK 5 0 R]", 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.colorSpace, func(t *testing.T) {
+			pixels := make([]byte, 200*200*tt.comps)
+			for i := range pixels {
+				pixels[i] = byte(i % 7 % 2)
+				if !strings.Contains(tt.colorSpace, "Indexed") {
+					pixels[i] = byte(i * 13)
+				}
+			}
+			doc := openPDF(t, pdftest.Build(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /XObject << /Im1 7 0 R >> >> >>",
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+				streamObject("", []byte("q 72 0 0 72 100 100 cm /Im1 Do Q")),
+				streamObject("/N 3", []byte("profile")),
+				streamObject("/N 4", []byte("profile")),
+				streamObject("/Type /XObject /Subtype /Image /Width 200 /Height 200 /ColorSpace "+tt.colorSpace+" /BitsPerComponent 8", pixels),
+			))
+			if _, err := doc.CompressImages(pdf.ImageCompression{Quality: 60}); err != nil {
+				t.Fatalf("CompressImages failed: %v", err)
+			}
+			images, err := rewrite(t, doc).Images("")
+			if err != nil || len(images) != 1 {
+				t.Fatalf("Images failed: %v (%d images)", err, len(images))
+			}
+			im := images[0]
+			if tt.want == "" {
+				if im.Filter != "" {
+					t.Errorf("Expected the image to be kept, got %+v", im)
+				}
+				return
+			}
+			if im.Filter != "DCTDecode" || im.ColorSpace != tt.want {
+				t.Errorf("Expected a JPEG in %s, got %+v", tt.want, im)
+			}
+		})
+	}
+}
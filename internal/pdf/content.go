@@ -0,0 +1,361 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+)
+
+// maxFormDepth limits how deeply nested form XObjects are followed.
+const maxFormDepth = 12
+
+// matrix is a PDF transformation matrix [a b c d e f].
+type matrix [6]float64
+
+var identityMatrix = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n, that is m applied first and then n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// apply transforms the point (x, y).
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+func translate(tx, ty float64) matrix {
+	return matrix{1, 0, 0, 1, tx, ty}
+}
+
+// matrixFrom converts six numeric operands or array items into a matrix.
+func matrixFrom(d *Document, objs []Object) (matrix, bool) {
+	if len(objs) != 6 {
+		return identityMatrix, false
+	}
+	var m matrix
+	for i, obj := range objs {
+		v, ok := d.GetNumber(obj)
+		if !ok {
+			return identityMatrix, false
+		}
+		m[i] = v
+	}
+	return m, true
+}
+
+// pageContent returns the decoded content of a page. When /Contents is an
+// array, the streams are joined as the specification requires.
+func (d *Document) pageContent(page Ref) ([]byte, error) {
+	contents := d.resolveQuiet(d.GetDict(page)["Contents"])
+	var streams []*Stream
+	switch v := contents.(type) {
+	case *Stream:
+		streams = []*Stream{v}
+	case Array:
+		for _, item := range v {
+			if s := d.GetStream(item); s != nil {
+				streams = append(streams, s)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	for _, s := range streams {
+		data, err := d.DecodeStream(s)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// parseContent calls fn for each operator of a content stream with its
+// operands. Inline image data is skipped. Parsing stops silently at damaged
+// content, keeping the operators read so far.
+func parseContent(data []byte, fn func(op string, args []Object)) {
+	p := newParser(data)
+	var args []Object
+	for !p.eof() {
+		obj, err := p.parseObject()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			args = append(args, obj)
+			continue
+		}
+		if op == "BI" {
+			skipInlineImage(p)
+			args = args[:0]
+			continue
+		}
+		fn(string(op), args)
+		args = args[:0]
+	}
+}
+
+// skipInlineImage advances past the dictionary and data of an inline image,
+// up to and including the EI operator.
+func skipInlineImage(p *parser) {
+	for !p.eof() {
+		obj, err := p.parseObject()
+		if err != nil {
+			p.pos = len(p.buf)
+			return
+		}
+		if obj == keyword("ID") {
+			break
+		}
+	}
+	p.pos++ // the single whitespace byte after ID
+	for p.pos < len(p.buf) {
+		i := bytes.Index(p.buf[p.pos:], []byte("EI"))
+		if i < 0 {
+			break
+		}
+		end := p.pos + i
+		before := end == 0 || isWhitespace(p.buf[end-1])
+		after := end+2 >= len(p.buf) || isWhitespace(p.buf[end+2]) || isDelimiter(p.buf[end+2])
+		p.pos = end + 2
+		if before && after {
+			return
+		}
+	}
+	p.pos = len(p.buf)
+}
+
+// graphicsState is the part of the PDF graphics state that content
+// processing tracks, including the text state parameters.
+type graphicsState struct {
+	ctm       matrix
+	font      *font
+	fontSize  float64
+	charSpace float64
+	wordSpace float64
+	hScale    float64
+	leading   float64
+	rise      float64
+}
+
+// contentWalker interprets page content streams, following form XObjects,
+// and reports the text and image XObjects it shows.
+type contentWalker struct {
+	doc    *Document
+	onText func(TextSpan)
+	// onImage is called for each image XObject drawn, with the current
+	// transformation matrix that maps the unit square to its placement.
+	onImage func(ref Ref, image *Stream, ctm matrix)
+
+	fonts map[Ref]*font
+	gs    graphicsState
+	stack []graphicsState
+	tm    matrix
+	tlm   matrix
+	// forms holds the form XObjects being interpreted, to break cycles.
+	forms map[Ref]bool
+}
+
+func newContentWalker(d *Document) *contentWalker {
+	return &contentWalker{
+		doc:   d,
+		fonts: map[Ref]*font{},
+		forms: map[Ref]bool{},
+	}
+}
+
+// walkPage interprets the content of page.
+func (w *contentWalker) walkPage(page Ref) error {
+	data, err := w.doc.pageContent(page)
+	if err != nil {
+		return err
+	}
+	resources := w.doc.GetDict(w.doc.inheritedPageAttr(page, "Resources"))
+	w.gs = graphicsState{ctm: identityMatrix, hScale: 1}
+	w.stack = w.stack[:0]
+	w.run(data, resources, 0)
+	return nil
+}
+
+func (w *contentWalker) run(data []byte, resources Dict, depth int) {
+	d := w.doc
+	parseContent(data, func(op string, args []Object) {
+		num := func(i int) float64 {
+			if i >= len(args) {
+				return 0
+			}
+			v, _ := d.GetNumber(args[i])
+			return v
+		}
+		switch op {
+		case "q":
+			w.stack = append(w.stack, w.gs)
+		case "Q":
+			if n := len(w.stack); n > 0 {
+				w.gs = w.stack[n-1]
+				w.stack = w.stack[:n-1]
+			}
+		case "cm":
+			if m, ok := matrixFrom(d, args); ok {
+				w.gs.ctm = m.mul(w.gs.ctm)
+			}
+		case "BT":
+			w.tm, w.tlm = identityMatrix, identityMatrix
+		case "Tf":
+			if len(args) == 2 {
+				name, _ := args[0].(Name)
+				w.gs.font = w.font(d.GetDict(resources["Font"])[name])
+				w.gs.fontSize = num(1)
+			}
+		case "Tc":
+			w.gs.charSpace = num(0)
+		case "Tw":
+			w.gs.wordSpace = num(0)
+		case "Tz":
+			w.gs.hScale = num(0) / 100
+		case "TL":
+			w.gs.leading = num(0)
+		case "Ts":
+			w.gs.rise = num(0)
+		case "Td":
+			w.moveText(num(0), num(1))
+		case "TD":
+			w.gs.leading = -num(1)
+			w.moveText(num(0), num(1))
+		case "Tm":
+			if m, ok := matrixFrom(d, args); ok {
+				w.tm, w.tlm = m, m
+			}
+		case "T*":
+			w.moveText(0, -w.gs.leading)
+		case "Tj":
+			if len(args) == 1 {
+				w.show(args[0])
+			}
+		case "'":
+			w.moveText(0, -w.gs.leading)
+			if len(args) == 1 {
+				w.show(args[0])
+			}
+		case "\"":
+			if len(args) == 3 {
+				w.gs.wordSpace = num(0)
+				w.gs.charSpace = num(1)
+				w.moveText(0, -w.gs.leading)
+				w.show(args[2])
+			}
+		case "TJ":
+			if len(args) == 1 {
+				for _, item := range d.GetArray(args[0]) {
+					if adjust, ok := d.GetNumber(item); ok {
+						tx := -adjust / 1000 * w.gs.fontSize * w.gs.hScale
+						w.tm = translate(tx, 0).mul(w.tm)
+						continue
+					}
+					w.show(item)
+				}
+			}
+		case "Do":
+			if len(args) == 1 {
+				name, _ := args[0].(Name)
+				w.doXObject(d.GetDict(resources["XObject"])[name], resources, depth)
+			}
+		}
+	})
+}
+
+func (w *contentWalker) font(obj Object) *font {
+	ref, isRef := obj.(Ref)
+	if isRef {
+		if f, ok := w.fonts[ref]; ok {
+			return f
+		}
+	}
+	f := w.doc.loadFont(obj)
+	if isRef {
+		w.fonts[ref] = f
+	}
+	return f
+}
+
+func (w *contentWalker) moveText(tx, ty float64) {
+	w.tlm = translate(tx, ty).mul(w.tlm)
+	w.tm = w.tlm
+}
+
+// show renders a string operand, advancing the text matrix by the width of
+// each glyph, and reports it as a span.
+func (w *contentWalker) show(obj Object) {
+	s, ok := obj.(String)
+	if !ok || w.gs.font == nil {
+		return
+	}
+	trm := w.tm.mul(w.gs.ctm)
+	x, y := trm.apply(0, w.gs.rise)
+	size := w.gs.fontSize * math.Hypot(trm[2], trm[3])
+
+	var text []byte
+	for _, g := range w.gs.font.decode(s) {
+		text = append(text, g.text...)
+		advance := g.width/1000*w.gs.fontSize + w.gs.charSpace
+		if g.wordSpace {
+			advance += w.gs.wordSpace
+		}
+		w.tm = translate(advance*w.gs.hScale, 0).mul(w.tm)
+	}
+	if w.onText == nil || len(text) == 0 {
+		return
+	}
+	endX, _ := w.tm.mul(w.gs.ctm).apply(0, w.gs.rise)
+	w.onText(TextSpan{
+		Text:     string(text),
+		X:        x,
+		Y:        y,
+		EndX:     endX,
+		FontSize: size,
+		Font:     w.gs.font.baseFont,
+	})
+}
+
+// doXObject reports an image XObject or interprets a form XObject with its
+// own resources and matrix.
+func (w *contentWalker) doXObject(obj Object, resources Dict, depth int) {
+	ref, _ := obj.(Ref)
+	s := w.doc.GetStream(obj)
+	if s == nil {
+		return
+	}
+	if w.doc.GetName(s.Dict["Subtype"]) == "Image" {
+		if w.onImage != nil {
+			w.onImage(ref, s, w.gs.ctm)
+		}
+		return
+	}
+	if w.doc.GetName(s.Dict["Subtype"]) != "Form" || depth >= maxFormDepth || w.forms[ref] {
+		return
+	}
+	data, err := w.doc.DecodeStream(s)
+	if err != nil {
+		return
+	}
+	if formResources := w.doc.GetDict(s.Dict["Resources"]); formResources != nil {
+		resources = formResources
+	}
+
+	w.forms[ref] = true
+	saved, savedStack := w.gs, len(w.stack)
+	if m, ok := matrixFrom(w.doc, w.doc.GetArray(s.Dict["Matrix"])); ok {
+		w.gs.ctm = m.mul(w.gs.ctm)
+	}
+	w.run(data, resources, depth+1)
+	w.gs, w.stack = saved, w.stack[:savedStack]
+	delete(w.forms, ref)
+}
@@ -0,0 +1,91 @@
+package pdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDate parses a PDF date string such as "D:20240131120000+01'00'".
+// Every part after the year is optional; a missing time zone means UTC.
+func ParseDate(s string) (time.Time, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	fields := []int{0, 1, 1, 0, 0, 0}
+	widths := []int{4, 2, 2, 2, 2, 2}
+	for i, w := range widths {
+		if len(s) < w || !isDigits(s[:w]) {
+			if i == 0 {
+				return time.Time{}, false
+			}
+			break
+		}
+		fields[i], _ = strconv.Atoi(s[:w])
+		s = s[w:]
+	}
+	loc := time.UTC
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		zone := strings.NewReplacer("'", "", ":", "").Replace(s[1:])
+		if len(zone) >= 2 && isDigits(zone[:2]) {
+			hours, _ := strconv.Atoi(zone[:2])
+			minutes := 0
+			if len(zone) >= 4 && isDigits(zone[2:4]) {
+				minutes, _ = strconv.Atoi(zone[2:4])
+			}
+			offset := hours*3600 + minutes*60
+			if s[0] == '-' {
+				offset = -offset
+			}
+			loc = time.FixedZone("", offset)
+		}
+	}
+	return time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], 0, loc), true
+}
+
+// FormatDate formats t as a PDF date string, such as
+// "D:20240131120000+01'00'".
+func FormatDate(t time.Time) string {
+	_, offset := t.Zone()
+	if offset == 0 {
+		return t.Format("D:20060102150405Z")
+	}
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%s%c%02d'%02d'", t.Format("D:20060102150405"), sign, offset/3600, offset/60%60)
+}
+
+// formatXMPDate formats t as an XMP date.
+func formatXMPDate(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// parseXMPDate parses an XMP date, which uses the ISO 8601 subset of the
+// W3C date and time format, such as "2024-01-31T12:00:00+01:00".
+func parseXMPDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04",
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
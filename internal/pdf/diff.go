@@ -0,0 +1,233 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sections of a Summary that changes are reported for.
+const (
+	SectionInfo        = "info"
+	SectionXMP         = "xmp"
+	SectionPages       = "pages"
+	SectionOutline     = "outline"
+	SectionAttachments = "attachments"
+)
+
+// Kinds of Change.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// PageGeometry is the size of a page in points and its rotation in degrees.
+type PageGeometry struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Rotate int     `json:"rotate"`
+}
+
+func (g PageGeometry) String() string {
+	s := formatReal(g.Width) + " x " + formatReal(g.Height)
+	if g.Rotate != 0 {
+		s += fmt.Sprintf(", rotated %d", g.Rotate)
+	}
+	return s
+}
+
+// Summary is the part of a document that Diff compares.
+type Summary struct {
+	Info        map[string]string `json:"info"`
+	XMP         map[string]string `json:"xmp"`
+	Pages       []PageGeometry    `json:"pages"`
+	Outline     []*OutlineItem    `json:"outline"`
+	Attachments []Attachment      `json:"attachments"`
+}
+
+// Summary reads the metadata, page sizes, outline and attachments of the
+// document.
+func (d *Document) Summary() (*Summary, error) {
+	if d.Encrypted() {
+		return nil, ErrEncrypted
+	}
+	var s Summary
+	var err error
+	if s.Info, err = d.Info(); err != nil {
+		return nil, err
+	}
+	if s.XMP, err = d.XMPMetadata(); err != nil {
+		return nil, err
+	}
+	pages, err := d.Pages()
+	if err != nil {
+		return nil, err
+	}
+	for _, page := range pages {
+		w, h, rotate := d.PageSize(page)
+		s.Pages = append(s.Pages, PageGeometry{w, h, rotate})
+	}
+	if s.Outline, err = d.Outline(); err != nil {
+		return nil, err
+	}
+	if s.Attachments, err = d.Attachments(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Change is a difference between two summaries. Key names what changed
+// within its section: an Info entry, an XMP property, "count" or a 1-based
+// page number for pages, the titles leading to an outline item joined by
+// " > ", or an attachment name. Old is empty for added and New for removed
+// keys.
+type Change struct {
+	Section string `json:"section"`
+	Key     string `json:"key"`
+	Kind    string `json:"kind"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// Diff returns the changes from a to b, by section in the order of Summary
+// and by key within a section.
+func Diff(a, b *Summary) []Change {
+	var changes []Change
+	changes = append(changes, diffMaps(SectionInfo, a.Info, b.Info)...)
+	changes = append(changes, diffMaps(SectionXMP, a.XMP, b.XMP)...)
+	changes = append(changes, diffPages(a.Pages, b.Pages)...)
+	changes = append(changes, diffMaps(SectionOutline, outlineEntries(a.Outline), outlineEntries(b.Outline))...)
+	changes = append(changes, diffMaps(SectionAttachments, attachmentEntries(a.Attachments), attachmentEntries(b.Attachments))...)
+	return changes
+}
+
+// diffMaps compares the values of a and b, reporting changes ordered by key.
+func diffMaps(section string, a, b map[string]string) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, key := range keys {
+		old, inA := a[key]
+		cur, inB := b[key]
+		switch {
+		case !inA:
+			changes = append(changes, Change{section, key, ChangeAdded, "", cur})
+		case !inB:
+			changes = append(changes, Change{section, key, ChangeRemoved, old, ""})
+		case old != cur:
+			changes = append(changes, Change{section, key, ChangeChanged, old, cur})
+		}
+	}
+	return changes
+}
+
+// diffPages reports a change of the page count, then pages whose size or
+// rotation changed, and the pages added or removed at the end.
+func diffPages(a, b []PageGeometry) []Change {
+	var changes []Change
+	if len(a) != len(b) {
+		changes = append(changes, Change{SectionPages, "count", ChangeChanged, fmt.Sprint(len(a)), fmt.Sprint(len(b))})
+	}
+	for i := 0; i < max(len(a), len(b)); i++ {
+		key := fmt.Sprint(i + 1)
+		switch {
+		case i >= len(a):
+			changes = append(changes, Change{SectionPages, key, ChangeAdded, "", b[i].String()})
+		case i >= len(b):
+			changes = append(changes, Change{SectionPages, key, ChangeRemoved, a[i].String(), ""})
+		case a[i] != b[i]:
+			changes = append(changes, Change{SectionPages, key, ChangeChanged, a[i].String(), b[i].String()})
+		}
+	}
+	return changes
+}
+
+// outlineEntries maps the title path of each outline item to its target
+// page. Items with the same path are numbered from the second on.
+func outlineEntries(items []*OutlineItem) map[string]string {
+	entries := map[string]string{}
+	var walk func(items []*OutlineItem, prefix string)
+	walk = func(items []*OutlineItem, prefix string) {
+		for _, item := range items {
+			path := prefix + item.Title
+			for n := 2; ; n++ {
+				if _, ok := entries[path]; !ok {
+					break
+				}
+				path = fmt.Sprintf("%s%s (%d)", prefix, item.Title, n)
+			}
+			target := "no page"
+			if item.Page > 0 {
+				target = fmt.Sprintf("page %d", item.Page)
+			}
+			entries[path] = target
+			walk(item.Children, path+" > ")
+		}
+	}
+	walk(items, "")
+	return entries
+}
+
+// attachmentEntries maps each attachment name to its size and hash, so that
+// a changed file shows as a changed entry.
+func attachmentEntries(attachments []Attachment) map[string]string {
+	entries := map[string]string{}
+	for _, a := range attachments {
+		name := a.Name
+		for n := 2; ; n++ {
+			if _, ok := entries[name]; !ok {
+				break
+			}
+			name = fmt.Sprintf("%s (%d)", a.Name, n)
+		}
+		hash := a.SHA256
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		entries[name] = strings.TrimSpace(fmt.Sprintf("%d bytes %s", a.Size, hash))
+	}
+	return entries
+}
+
+// RevisionDiff is the changes made by one incremental update: from the
+// revision numbered From to the one numbered To, counting from 1 for the
+// original document.
+type RevisionDiff struct {
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Size    int64    `json:"size"`
+	Changes []Change `json:"changes"`
+}
+
+// DiffRevisions compares each revision with the one before it. Size is the
+// length of the file at revision To.
+func DiffRevisions(revisions []Revision) ([]RevisionDiff, error) {
+	summaries := make([]*Summary, len(revisions))
+	for i, rev := range revisions {
+		s, err := rev.Document.Summary()
+		if err != nil {
+			return nil, fmt.Errorf("revision %d: %w", i+1, err)
+		}
+		summaries[i] = s
+	}
+	diffs := []RevisionDiff{}
+	for i := 1; i < len(revisions); i++ {
+		diffs = append(diffs, RevisionDiff{
+			From:    i,
+			To:      i + 1,
+			Size:    revisions[i].Size,
+			Changes: Diff(summaries[i-1], summaries[i]),
+		})
+	}
+	return diffs, nil
+}
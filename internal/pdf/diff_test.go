@@ -0,0 +1,167 @@
+package pdf_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// diffObjects returns the objects of a two-page document with an Info
+// dictionary, an outline item and an attachment. Object 5 is the Info
+// dictionary.
+func diffObjects(info, mediaBox, bookmark string, attachment []byte) []string {
+	objects := samplePages(2, "/Outlines 6 0 R /Names << /EmbeddedFiles << /Names [(data.csv) 8 0 R] >> >> ")
+	objects[3] = "<< /Type /Page /Parent 2 0 R /MediaBox " + mediaBox + " >>"
+	return append(objects,
+		info,
+		"<< /Type /Outlines /First 7 0 R /Last 7 0 R /Count 1 >>",
+		"<< /Title ("+bookmark+") /Parent 6 0 R /Dest [4 0 R /Fit] >>",
+		"<< /Type /Filespec /F (data.csv) /EF << /F 9 0 R >> >>",
+		streamObject("/Type /EmbeddedFile", attachment),
+	)
+}
+
+func summarize(t *testing.T, data []byte) *pdf.Summary {
+	t.Helper()
+	summary, err := openPDF(t, data).Summary()
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	return summary
+}
+
+func TestDocument_Summary(t *testing.T) {
+	got := summarize(t, pdftest.BuildWithTrailer("/Info 5 0 R ", diffObjects("<< /Title (Report) >>", "[0 0 595 842]", "Results", []byte("a,b\n"))...))
+	if got.Info["Title"] != "Report" {
+		t.Errorf("Expected the Info title, got %v", got.Info)
+	}
+	wantPages := []pdf.PageGeometry{{Width: 612, Height: 792}, {Width: 595, Height: 842}}
+	if !reflect.DeepEqual(got.Pages, wantPages) {
+		t.Errorf("Expected pages %v, got %v", wantPages, got.Pages)
+	}
+	if len(got.Outline) != 1 || got.Outline[0].Title != "Results" || got.Outline[0].Page != 2 {
+		t.Errorf("Expected one bookmark to page 2, got %+v", got.Outline)
+	}
+	if len(got.Attachments) != 1 || got.Attachments[0].Name != "data.csv" || got.Attachments[0].Size != 4 {
+		t.Errorf("Expected the 4-byte attachment data.csv, got %+v", got.Attachments)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := summarize(t, pdftest.BuildWithTrailer("/Info 5 0 R ", diffObjects("<< /Title (Draft) /Author (Ada) >>", "[0 0 612 792]", "Results", []byte("a,b\n"))...))
+	same := summarize(t, pdftest.BuildWithTrailer("/Info 5 0 R ", diffObjects("<< /Title (Draft) /Author (Ada) >>", "[0 0 612 792]", "Results", []byte("a,b\n"))...))
+	if changes := pdf.Diff(a, same); len(changes) != 0 {
+		t.Errorf("Expected no changes between equal documents, got %+v", changes)
+	}
+
+	b := summarize(t, pdftest.BuildWithTrailer("/Info 5 0 R ", diffObjects("<< /Title (Final) /Subject (Q3) >>", "[0 0 792 612]", "Summary", []byte("a,b,c\n"))...))
+	b.XMP = map[string]string{"dc:title": "Final"}
+	b.Pages = append(b.Pages, pdf.PageGeometry{Width: 612, Height: 792, Rotate: 90})
+
+	oldSum, newSum := sha256.Sum256([]byte("a,b\n")), sha256.Sum256([]byte("a,b,c\n"))
+	var got []string
+	for _, c := range pdf.Diff(a, b) {
+		got = append(got, fmt.Sprintf("%s %s %s: %q -> %q", c.Section, c.Kind, c.Key, c.Old, c.New))
+	}
+	want := []string{
+		`info removed Author: "Ada" -> ""`,
+		`info added Subject: "" -> "Q3"`,
+		`info changed Title: "Draft" -> "Final"`,
+		`xmp added dc:title: "" -> "Final"`,
+		`pages changed count: "2" -> "3"`,
+		`pages changed 2: "612 x 792" -> "792 x 612"`,
+		`pages added 3: "" -> "612 x 792, rotated 90"`,
+		`outline removed Results: "page 2" -> ""`,
+		`outline added Summary: "" -> "page 2"`,
+		fmt.Sprintf(`attachments changed data.csv: "4 bytes %x" -> "6 bytes %x"`, oldSum[:6], newSum[:6]),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected changes\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+// incrementalUpdates returns a document saved once and then updated with
+// each of the metadata edits in turn.
+func incrementalUpdates(t *testing.T, edits ...map[string]string) []byte {
+	t.Helper()
+	data := pdftest.BuildWithTrailer("/Info 4 0 R ", append(samplePages(1, ""), "<< /Title (Original) >>")...)
+	for _, fields := range edits {
+		doc := openPDF(t, data)
+		if err := doc.SetMetadata(fields, time.Unix(0, 0)); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
+		data = serialize(t, doc, pdf.WriteOptions{Incremental: true})
+	}
+	return data
+}
+
+func TestRevisions(t *testing.T) {
+	data := incrementalUpdates(t, map[string]string{"Title": "Second"}, map[string]string{"Author": "Ada"})
+	revisions, err := pdf.Revisions(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Revisions failed: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("Expected 3 revisions, got %d", len(revisions))
+	}
+	for i, want := range []string{"Original", "Second", "Second"} {
+		info, _ := revisions[i].Document.Info()
+		if info["Title"] != want {
+			t.Errorf("Expected revision %d to have title %q, got %q", i+1, want, info["Title"])
+		}
+	}
+	if last := revisions[2]; last.Size != int64(len(data)) {
+		t.Errorf("Expected the last revision to span the file, got %d of %d bytes", last.Size, len(data))
+	}
+
+	// A file without updates, even without a line break after %%EOF.
+	single := bytes.TrimRight(pdftest.Build(samplePages(1, "")...), "\n")
+	revisions, err = pdf.Revisions(bytes.NewReader(single), int64(len(single)))
+	if err != nil {
+		t.Fatalf("Revisions failed: %v", err)
+	}
+	if len(revisions) != 1 {
+		t.Errorf("Expected 1 revision, got %d", len(revisions))
+	}
+}
+
+func TestPDFService_DiffRevisions(t *testing.T) {
+	path := writeTempPDF(t, incrementalUpdates(t, map[string]string{"Title": "Second"}))
+	diffs, err := pdf.NewPDFService().DiffRevisions(path)
+	if err != nil {
+		t.Fatalf("DiffRevisions failed: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].From != 1 || diffs[0].To != 2 {
+		t.Fatalf("Expected one diff from revision 1 to 2, got %+v", diffs)
+	}
+	var title *pdf.Change
+	for i, c := range diffs[0].Changes {
+		if c.Section == pdf.SectionInfo && c.Key == "Title" {
+			title = &diffs[0].Changes[i]
+		}
+	}
+	if title == nil || title.Old != "Original" || title.New != "Second" {
+		t.Errorf("Expected the title change, got %+v", diffs[0].Changes)
+	}
+
+	other := writeTempPDF(t, incrementalUpdates(t))
+	changes, err := pdf.NewPDFService().DiffFiles(other, path)
+	if err != nil {
+		t.Fatalf("DiffFiles failed: %v", err)
+	}
+	if len(changes) == 0 {
+		t.Error("Expected the updated file to differ from the original")
+	}
+	if _, err := pdf.NewPDFService().DiffFiles(other, path+".missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
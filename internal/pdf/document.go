@@ -0,0 +1,834 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// initialWindow is the number of bytes read when parsing an object at a
+	// file offset. The window doubles until the object fits.
+	initialWindow = 4096
+	// tailSize is how much of the end of the file is searched for startxref.
+	tailSize = 2048
+)
+
+// xref entry types, as used in cross-reference streams.
+const (
+	xrefFree       = 0
+	xrefInUse      = 1
+	xrefCompressed = 2
+)
+
+// xrefEntry locates an object. For in-use entries offset is the byte offset
+// and gen the generation; for compressed entries offset is the number of the
+// object stream and gen the index within it.
+type xrefEntry struct {
+	typ    int
+	offset int64
+	gen    int
+}
+
+// objectStream is a decoded /Type /ObjStm stream.
+type objectStream struct {
+	data    []byte
+	first   int
+	offsets []int
+}
+
+// Document is a parsed PDF file. Objects are loaded lazily from the
+// underlying reader as they are requested.
+type Document struct {
+	r       io.ReaderAt
+	size    int64
+	closer  io.Closer
+	version string
+	trailer Dict
+	xref    map[int]xrefEntry
+	objects map[int]Object
+	// streams holds the dictionary and location of the streams read so far
+	// instead of objects, so that their data is read again when needed
+	// rather than held for the life of the document.
+	streams map[int]*cachedStream
+	objStms map[int]*objectStream
+	changed map[int]Object
+	maxNum  int
+	// startxref is the offset of the newest cross-reference section.
+	startxref int64
+}
+
+// Open opens and parses the PDF file at path. The caller must Close it.
+func Open(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	doc, err := NewDocument(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	doc.closer = f
+	return doc, nil
+}
+
+// NewDocument parses a PDF from r, which holds size bytes.
+func NewDocument(r io.ReaderAt, size int64) (*Document, error) {
+	d := newDocument(r, size)
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+	start, err := d.findStartXref()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.readXrefChain(start); err != nil {
+		return nil, err
+	}
+	d.startxref = start
+	if _, ok := d.trailer["Root"].(Ref); !ok {
+		return nil, fmt.Errorf("%w: trailer has no /Root", ErrMalformed)
+	}
+	return d, nil
+}
+
+func newDocument(r io.ReaderAt, size int64) *Document {
+	return &Document{
+		r:       r,
+		size:    size,
+		xref:    map[int]xrefEntry{},
+		objects: map[int]Object{},
+		streams: map[int]*cachedStream{},
+		objStms: map[int]*objectStream{},
+		changed: map[int]Object{},
+	}
+}
+
+// Close releases the underlying file, if the document was opened from a path.
+func (d *Document) Close() error {
+	if d.closer == nil {
+		return nil
+	}
+	return d.closer.Close()
+}
+
+// Version returns the version from the file header, such as "1.7".
+func (d *Document) Version() string {
+	return d.version
+}
+
+// Trailer returns the trailer dictionary of the most recent revision.
+func (d *Document) Trailer() Dict {
+	return d.trailer
+}
+
+// Encrypted reports whether the document has an /Encrypt dictionary.
+func (d *Document) Encrypted() bool {
+	_, ok := d.trailer["Encrypt"]
+	return ok
+}
+
+func (d *Document) readAt(off int64, n int) ([]byte, error) {
+	if off < 0 || off >= d.size {
+		return nil, fmt.Errorf("%w: offset %d outside file", ErrMalformed, off)
+	}
+	if rem := d.size - off; int64(n) > rem {
+		n = int(rem)
+	}
+	buf := make([]byte, n)
+	read, err := d.r.ReadAt(buf, off)
+	if err != nil && !(errors.Is(err, io.EOF) && read == n) {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// parseAt runs fn on a parser positioned at off, growing the window read
+// from the file until fn no longer runs out of data.
+func (d *Document) parseAt(off int64, fn func(p *parser) error) error {
+	for n := initialWindow; ; n *= 2 {
+		buf, err := d.readAt(off, n)
+		if err != nil {
+			return err
+		}
+		p := newParser(buf)
+		p.partial = off+int64(len(buf)) < d.size
+		err = fn(p)
+		if err != errTruncated {
+			return err
+		}
+		if !p.partial {
+			return fmt.Errorf("%w: unexpected end of file at offset %d", ErrMalformed, off)
+		}
+	}
+}
+
+func (d *Document) readHeader() error {
+	buf, err := d.readAt(0, 1024)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotPDF, err)
+	}
+	i := bytes.Index(buf, []byte("%PDF-"))
+	if i < 0 {
+		return ErrNotPDF
+	}
+	v := buf[i+5:]
+	end := 0
+	for end < len(v) && (v[end] == '.' || (v[end] >= '0' && v[end] <= '9')) {
+		end++
+	}
+	d.version = string(v[:end])
+	return nil
+}
+
+func (d *Document) findStartXref() (int64, error) {
+	n := min(d.size, tailSize)
+	buf, err := d.readAt(d.size-n, int(n))
+	if err != nil {
+		return 0, err
+	}
+	i := bytes.LastIndex(buf, []byte("startxref"))
+	if i < 0 {
+		return 0, fmt.Errorf("%w: startxref not found", ErrMalformed)
+	}
+	p := newParser(buf[i+len("startxref"):])
+	off, err := p.parseInt()
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid startxref", ErrMalformed)
+	}
+	return off, nil
+}
+
+// readXrefChain reads the cross-reference section at off and every earlier
+// section reachable through /Prev. Entries from newer sections win. Within
+// the section of a hybrid-reference file, the entries of the /XRefStm stream
+// win over the table, which lists objects in object streams as free for
+// readers that only know tables.
+func (d *Document) readXrefChain(off int64) error {
+	seen := map[int64]bool{}
+	for first := true; ; first = false {
+		if seen[off] {
+			return fmt.Errorf("%w: cross-reference loop at offset %d", ErrMalformed, off)
+		}
+		seen[off] = true
+
+		trailer, entries, err := d.readXrefSection(off)
+		if err != nil {
+			return err
+		}
+		if first {
+			d.trailer = trailer
+		}
+		if stmOff, ok := trailer["XRefStm"].(int64); ok && !seen[stmOff] {
+			seen[stmOff] = true
+			_, stmEntries, err := d.readXrefSection(stmOff)
+			if err != nil {
+				return err
+			}
+			d.addXrefEntries(stmEntries)
+		}
+		d.addXrefEntries(entries)
+		prev, ok := trailer["Prev"].(int64)
+		if !ok {
+			return nil
+		}
+		off = prev
+	}
+}
+
+// readXrefSection reads a classic table or a cross-reference stream at off
+// and returns its trailer dictionary and entries.
+func (d *Document) readXrefSection(off int64) (Dict, map[int]xrefEntry, error) {
+	var trailer Dict
+	var entries map[int]xrefEntry
+	var isStream bool
+	err := d.parseAt(off, func(p *parser) error {
+		p.skipSpace()
+		if !bytes.HasPrefix(p.buf[p.pos:], []byte("xref")) {
+			isStream = true
+			return nil
+		}
+		var err error
+		trailer, entries, err = parseXrefTable(p)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading cross-reference at offset %d: %w", off, err)
+	}
+	if isStream {
+		return d.readXrefStream(off)
+	}
+	return trailer, entries, nil
+}
+
+func parseXrefTable(p *parser) (Dict, map[int]xrefEntry, error) {
+	if err := p.expectKeyword("xref"); err != nil {
+		return nil, nil, err
+	}
+	entries := map[int]xrefEntry{}
+	for {
+		obj, err := p.parseObject()
+		if err != nil {
+			return nil, nil, err
+		}
+		if kw, ok := obj.(keyword); ok && kw == "trailer" {
+			break
+		}
+		start, ok := obj.(int64)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: invalid cross-reference subsection %v", ErrMalformed, obj)
+		}
+		count, err := p.parseInt()
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := int64(0); i < count; i++ {
+			offset, err := p.parseInt()
+			if err != nil {
+				return nil, nil, err
+			}
+			gen, err := p.parseInt()
+			if err != nil {
+				return nil, nil, err
+			}
+			kind, err := p.parseObject()
+			if err != nil {
+				return nil, nil, err
+			}
+			e := xrefEntry{typ: xrefFree, offset: offset, gen: int(gen)}
+			if kind == keyword("n") {
+				e.typ = xrefInUse
+			}
+			entries[int(start+i)] = e
+		}
+	}
+	obj, err := p.parseObject()
+	if err != nil {
+		return nil, nil, err
+	}
+	trailer, ok := obj.(Dict)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: trailer is not a dictionary", ErrMalformed)
+	}
+	return trailer, entries, nil
+}
+
+func (d *Document) readXrefStream(off int64) (Dict, map[int]xrefEntry, error) {
+	obj, _, err := d.readObjectAt(off)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, ok := obj.(*Stream)
+	if !ok || s.Dict["Type"] != Name("XRef") {
+		return nil, nil, fmt.Errorf("%w: no cross-reference at offset %d", ErrMalformed, off)
+	}
+	data, err := d.DecodeStream(s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding cross-reference stream: %w", err)
+	}
+
+	var widths [3]int
+	w, _ := s.Dict["W"].(Array)
+	if len(w) != 3 {
+		return nil, nil, fmt.Errorf("%w: invalid /W in cross-reference stream", ErrMalformed)
+	}
+	for i := range widths {
+		v, _ := w[i].(int64)
+		widths[i] = int(v)
+	}
+	rowLen := widths[0] + widths[1] + widths[2]
+	if rowLen == 0 {
+		return nil, nil, fmt.Errorf("%w: invalid /W in cross-reference stream", ErrMalformed)
+	}
+
+	size, _ := s.Dict["Size"].(int64)
+	index := Array{int64(0), size}
+	if idx, ok := s.Dict["Index"].(Array); ok {
+		index = idx
+	}
+	entries := map[int]xrefEntry{}
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		start, _ := index[i].(int64)
+		count, _ := index[i+1].(int64)
+		for j := int64(0); j < count && pos+rowLen <= len(data); j++ {
+			row := data[pos : pos+rowLen]
+			pos += rowLen
+			typ := xrefInUse
+			if widths[0] > 0 {
+				typ = int(beUint(row[:widths[0]]))
+			}
+			field2 := beUint(row[widths[0] : widths[0]+widths[1]])
+			field3 := beUint(row[widths[0]+widths[1]:])
+			entries[int(start+j)] = xrefEntry{typ: typ, offset: int64(field2), gen: int(field3)}
+		}
+	}
+	return s.Dict, entries, nil
+}
+
+func beUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// addXrefEntries records the entries of objects that no newer section
+// defined.
+func (d *Document) addXrefEntries(entries map[int]xrefEntry) {
+	for num, e := range entries {
+		if _, ok := d.xref[num]; ok {
+			continue
+		}
+		d.xref[num] = e
+		if num > d.maxNum {
+			d.maxNum = num
+		}
+	}
+}
+
+// readObjectAt parses the indirect object "N G obj ... endobj" at off.
+func (d *Document) readObjectAt(off int64) (Object, Ref, error) {
+	obj, ref, dataStart, err := d.readObjectHeader(off)
+	if err != nil || dataStart < 0 {
+		return obj, ref, err
+	}
+	dict := obj.(Dict)
+	data, err := d.readStreamData(dict, dataStart)
+	if err != nil {
+		return nil, ref, fmt.Errorf("reading stream data of object %d: %w", ref.Num, err)
+	}
+	return &Stream{Dict: dict, Data: data}, ref, nil
+}
+
+// readObjectHeader parses the indirect object at off like readObjectAt, but
+// stops at the data of streams: it returns their dictionary and the offset
+// the data starts at, or -1 for other objects.
+func (d *Document) readObjectHeader(off int64) (Object, Ref, int64, error) {
+	var obj Object
+	var ref Ref
+	var dataStart int64 = -1
+	err := d.parseAt(off, func(p *parser) error {
+		num, err := p.parseInt()
+		if err != nil {
+			return err
+		}
+		gen, err := p.parseInt()
+		if err != nil {
+			return err
+		}
+		if err := p.expectKeyword("obj"); err != nil {
+			return err
+		}
+		ref = Ref{Num: int(num), Gen: int(gen)}
+		obj, err = p.parseObject()
+		if err != nil {
+			return err
+		}
+		if _, ok := obj.(Dict); !ok {
+			return nil
+		}
+		p.skipSpace()
+		if !bytes.HasPrefix(p.buf[p.pos:], []byte("stream")) {
+			if p.atWindowEnd() {
+				return errTruncated
+			}
+			return nil
+		}
+		p.pos += len("stream")
+		if p.pos < len(p.buf) && p.buf[p.pos] == '\r' {
+			p.pos++
+		}
+		if p.pos < len(p.buf) && p.buf[p.pos] == '\n' {
+			p.pos++
+		}
+		dataStart = off + int64(p.pos)
+		return nil
+	})
+	if err != nil {
+		return nil, ref, -1, fmt.Errorf("reading object at offset %d: %w", off, err)
+	}
+	return obj, ref, dataStart, nil
+}
+
+// readStreamData reads the data of a stream starting at off.
+func (d *Document) readStreamData(dict Dict, off int64) ([]byte, error) {
+	length, err := d.streamLength(dict, off)
+	if err != nil || length == 0 {
+		return nil, err
+	}
+	return d.readAt(off, int(length))
+}
+
+// streamLength returns the length of the data of a stream starting at off.
+// When /Length is missing or wrong, the data is delimited by the endstream
+// keyword.
+func (d *Document) streamLength(dict Dict, off int64) (int64, error) {
+	if length, ok := d.GetInt(dict["Length"]); ok && length >= 0 && off+length <= d.size {
+		tail, _ := d.readAt(off+length, 32)
+		if bytes.HasPrefix(bytes.TrimLeft(tail, "\r\n \t"), []byte("endstream")) {
+			return length, nil
+		}
+	}
+	return d.scanStreamLength(off)
+}
+
+// scanStreamLength returns the number of bytes from off to the first
+// endstream keyword, less the end-of-line marker before it. The file is
+// searched in chunks, so long streams are not held in memory.
+func (d *Document) scanStreamLength(off int64) (int64, error) {
+	const chunk = 64 << 10
+	keyword := []byte("endstream")
+	for pos := off; pos < d.size; pos += chunk {
+		// Overlap the chunks so that a keyword or an end-of-line marker
+		// crossing a chunk boundary is found.
+		start := max(off, pos-int64(len(keyword)+2))
+		buf, err := d.readAt(start, int(pos-start)+chunk)
+		if err != nil {
+			return 0, err
+		}
+		i := bytes.Index(buf, keyword)
+		if i < 0 {
+			continue
+		}
+		data := buf[:i]
+		switch {
+		case bytes.HasSuffix(data, []byte("\r\n")):
+			i -= 2
+		case bytes.HasSuffix(data, []byte("\n")), bytes.HasSuffix(data, []byte("\r")):
+			i--
+		}
+		return start - off + int64(i), nil
+	}
+	return 0, fmt.Errorf("%w: endstream not found", ErrMalformed)
+}
+
+// streamSource locates the undecoded data of a stream in the file.
+type streamSource struct {
+	off, length int64
+}
+
+// cachedStream is a stream read from the file, without its data.
+type cachedStream struct {
+	dict Dict
+	src  streamSource
+}
+
+// shallowObject returns object num like Object, but without reading the
+// data of streams that are unchanged in the file: those are returned with
+// their dictionary only and src locating their data. Objects read here are
+// not cached, so going through all objects needs little memory.
+func (d *Document) shallowObject(num int) (obj Object, src *streamSource, err error) {
+	if obj, ok := d.changed[num]; ok {
+		return obj, nil, nil
+	}
+	if obj, ok := d.objects[num]; ok {
+		return obj, nil, nil
+	}
+	if c, ok := d.streams[num]; ok {
+		return &Stream{Dict: c.dict}, &c.src, nil
+	}
+	e, ok := d.xref[num]
+	if !ok || e.typ != xrefInUse {
+		obj, err := d.Object(num)
+		return obj, nil, err
+	}
+	return d.readShallow(num, e.offset)
+}
+
+// readShallow reads object num at off, returning streams with their
+// dictionary only and src locating their data.
+func (d *Document) readShallow(num int, off int64) (obj Object, src *streamSource, err error) {
+	obj, ref, dataStart, err := d.readObjectHeader(off)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ref.Num != num {
+		return nil, nil, fmt.Errorf("%w: object %d expected at offset %d, found %d", ErrMalformed, num, off, ref.Num)
+	}
+	if dataStart < 0 {
+		return obj, nil, nil
+	}
+	dict := obj.(Dict)
+	length, err := d.streamLength(dict, dataStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading stream data of object %d: %w", num, err)
+	}
+	return &Stream{Dict: dict}, &streamSource{off: dataStart, length: length}, nil
+}
+
+// Object returns the object with the given number, or nil if it is free or
+// does not exist. Objects are cached once read, except for the data of
+// streams, which is read from the file on every call so that memory use does
+// not grow with the size of the streams read.
+func (d *Document) Object(num int) (Object, error) {
+	if obj, ok := d.changed[num]; ok {
+		return obj, nil
+	}
+	if obj, ok := d.objects[num]; ok {
+		return obj, nil
+	}
+	if c, ok := d.streams[num]; ok {
+		return d.loadStream(num, c)
+	}
+	e, ok := d.xref[num]
+	if !ok {
+		return nil, nil
+	}
+	var obj Object
+	switch e.typ {
+	case xrefInUse:
+		o, src, err := d.readShallow(num, e.offset)
+		if err != nil {
+			return nil, err
+		}
+		if src != nil {
+			c := &cachedStream{dict: o.(*Stream).Dict, src: *src}
+			d.streams[num] = c
+			return d.loadStream(num, c)
+		}
+		obj = o
+	case xrefCompressed:
+		o, err := d.compressedObject(int(e.offset), e.gen)
+		if err != nil {
+			return nil, fmt.Errorf("reading object %d: %w", num, err)
+		}
+		obj = o
+	default:
+		return nil, nil
+	}
+	d.objects[num] = obj
+	return obj, nil
+}
+
+// loadStream reads the data of the stream c and returns the stream.
+func (d *Document) loadStream(num int, c *cachedStream) (Object, error) {
+	s := &Stream{Dict: c.dict}
+	if c.src.length > 0 {
+		data, err := d.readAt(c.src.off, int(c.src.length))
+		if err != nil {
+			return nil, fmt.Errorf("reading stream data of object %d: %w", num, err)
+		}
+		s.Data = data
+	}
+	return s, nil
+}
+
+func (d *Document) compressedObject(stmNum, index int) (Object, error) {
+	stm, err := d.objectStream(stmNum)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(stm.offsets) {
+		return nil, fmt.Errorf("%w: index %d outside object stream %d", ErrMalformed, index, stmNum)
+	}
+	start := stm.first + stm.offsets[index]
+	if start < 0 || start > len(stm.data) {
+		return nil, fmt.Errorf("%w: invalid offset in object stream %d", ErrMalformed, stmNum)
+	}
+	p := newParser(stm.data[start:])
+	return p.parseObject()
+}
+
+func (d *Document) objectStream(num int) (*objectStream, error) {
+	if stm, ok := d.objStms[num]; ok {
+		return stm, nil
+	}
+	obj, err := d.Object(num)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(*Stream)
+	if !ok {
+		return nil, fmt.Errorf("%w: object %d is not an object stream", ErrMalformed, num)
+	}
+	data, err := d.DecodeStream(s)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := d.GetInt(s.Dict["N"])
+	first, _ := d.GetInt(s.Dict["First"])
+	p := newParser(data)
+	stm := &objectStream{data: data, first: int(first)}
+	for i := int64(0); i < n; i++ {
+		if _, err := p.parseInt(); err != nil {
+			return nil, fmt.Errorf("object stream %d header: %w", num, err)
+		}
+		off, err := p.parseInt()
+		if err != nil {
+			return nil, fmt.Errorf("object stream %d header: %w", num, err)
+		}
+		stm.offsets = append(stm.offsets, int(off))
+	}
+	d.objStms[num] = stm
+	return stm, nil
+}
+
+// Resolve follows indirect references until it reaches a direct object.
+func (d *Document) Resolve(obj Object) (Object, error) {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(Ref)
+		if !ok {
+			return obj, nil
+		}
+		var err error
+		obj, err = d.Object(ref.Num)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: reference chain too long", ErrMalformed)
+}
+
+// resolveQuiet is Resolve for lenient readers: unreadable objects become null.
+func (d *Document) resolveQuiet(obj Object) Object {
+	obj, err := d.Resolve(obj)
+	if err != nil {
+		return nil
+	}
+	return obj
+}
+
+// GetDict resolves obj and returns it as a dictionary, or nil. The
+// dictionary of a stream is returned for stream objects.
+func (d *Document) GetDict(obj Object) Dict {
+	switch v := d.resolveQuiet(obj).(type) {
+	case Dict:
+		return v
+	case *Stream:
+		return v.Dict
+	}
+	return nil
+}
+
+// GetArray resolves obj and returns it as an array, or nil.
+func (d *Document) GetArray(obj Object) Array {
+	a, _ := d.resolveQuiet(obj).(Array)
+	return a
+}
+
+// GetStream resolves obj and returns it as a stream, or nil.
+func (d *Document) GetStream(obj Object) *Stream {
+	s, _ := d.resolveQuiet(obj).(*Stream)
+	return s
+}
+
+// GetName resolves obj and returns it as a name, or "".
+func (d *Document) GetName(obj Object) Name {
+	n, _ := d.resolveQuiet(obj).(Name)
+	return n
+}
+
+// GetString resolves obj and returns it as a string.
+func (d *Document) GetString(obj Object) (String, bool) {
+	s, ok := d.resolveQuiet(obj).(String)
+	return s, ok
+}
+
+// GetInt resolves obj and returns it as an integer. Reals are truncated.
+func (d *Document) GetInt(obj Object) (int64, bool) {
+	switch v := d.resolveQuiet(obj).(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// GetNumber resolves obj and returns it as a float.
+func (d *Document) GetNumber(obj Object) (float64, bool) {
+	switch v := d.resolveQuiet(obj).(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// Catalog returns the document catalog and its reference.
+func (d *Document) Catalog() (Dict, Ref, error) {
+	ref := d.trailer["Root"].(Ref)
+	catalog := d.GetDict(ref)
+	if catalog == nil {
+		return nil, ref, fmt.Errorf("%w: catalog %v is not a dictionary", ErrMalformed, ref)
+	}
+	return catalog, ref, nil
+}
+
+// Pages returns references to the pages of the document in order.
+func (d *Document) Pages() ([]Ref, error) {
+	catalog, _, err := d.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := catalog["Pages"].(Ref)
+	if !ok {
+		return nil, fmt.Errorf("%w: catalog has no /Pages reference", ErrMalformed)
+	}
+	var pages []Ref
+	seen := map[Ref]bool{}
+	var walk func(ref Ref) error
+	walk = func(ref Ref) error {
+		if seen[ref] {
+			return fmt.Errorf("%w: page tree cycle at object %d", ErrMalformed, ref.Num)
+		}
+		seen[ref] = true
+		node := d.GetDict(ref)
+		if node == nil {
+			return fmt.Errorf("%w: page tree node %v is not a dictionary", ErrMalformed, ref)
+		}
+		kids, hasKids := node["Kids"]
+		if d.GetName(node["Type"]) == "Page" || !hasKids {
+			pages = append(pages, ref)
+			return nil
+		}
+		for _, kid := range d.GetArray(kids) {
+			kidRef, ok := kid.(Ref)
+			if !ok {
+				continue
+			}
+			if err := walk(kidRef); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// Set replaces the object with the given reference.
+func (d *Document) Set(ref Ref, obj Object) {
+	d.changed[ref.Num] = obj
+	if ref.Num > d.maxNum {
+		d.maxNum = ref.Num
+	}
+}
+
+// Add stores obj as a new indirect object and returns its reference.
+func (d *Document) Add(obj Object) Ref {
+	ref := Ref{Num: d.maxNum + 1}
+	d.Set(ref, obj)
+	return ref
+}
+
+// generation returns the generation number of object num.
+func (d *Document) generation(num int) int {
+	if e, ok := d.xref[num]; ok && e.typ == xrefInUse {
+		return e.gen
+	}
+	return 0
+}
@@ -0,0 +1,188 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+func TestNewDocument_ClassicXref(t *testing.T) {
+	objects := append(samplePages(2, ""),
+		`<< /Title (Report \(draft\)) /Count -3 /Ratio 0.5 /Tags [/A /B#20C] /Ref 1 0 R /Hex <48656C6C6F> >>`)
+	doc := openPDF(t, pdftest.Build(objects...))
+
+	if doc.Version() != "1.4" {
+		t.Errorf("Expected version 1.4, got %q", doc.Version())
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatalf("Pages failed: %v", err)
+	}
+	if want := []pdf.Ref{{Num: 3}, {Num: 4}}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("Expected pages %v, got %v", want, pages)
+	}
+
+	obj, err := doc.Object(5)
+	if err != nil {
+		t.Fatalf("Object failed: %v", err)
+	}
+	want := pdf.Dict{
+		"Title": pdf.String("Report (draft)"),
+		"Count": int64(-3),
+		"Ratio": 0.5,
+		"Tags":  pdf.Array{pdf.Name("A"), pdf.Name("B C")},
+		"Ref":   pdf.Ref{Num: 1},
+		"Hex":   pdf.String("Hello"),
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Errorf("Expected %#v, got %#v", want, obj)
+	}
+}
+
+func TestNewDocument_XrefStreamAndObjectStream(t *testing.T) {
+	// Objects 1-3 live in object stream 4; object 5 is the xref stream.
+	bodies := samplePages(1, "")
+	var header, body bytes.Buffer
+	for i, b := range bodies {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(b + "\n")
+	}
+	objStm := header.String() + body.String()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	objStmOffset := buf.Len()
+	compressed := zlibBytes([]byte(objStm))
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Type /ObjStm /N 3 /First %d /Filter /FlateDecode /Length %d >>\nstream\n", header.Len(), len(compressed))
+	buf.Write(compressed)
+	buf.WriteString("\nendstream\nendobj\n")
+
+	xrefOffset := buf.Len()
+	rows := []byte{
+		0, 0, 0, 0xFF,
+		2, 0, 4, 0,
+		2, 0, 4, 1,
+		2, 0, 4, 2,
+		1, byte(objStmOffset >> 8), byte(objStmOffset), 0,
+		1, byte(xrefOffset >> 8), byte(xrefOffset), 0,
+	}
+	fmt.Fprintf(&buf, "5 0 obj\n<< /Type /XRef /Size 6 /W [1 2 1] /Root 1 0 R /Length %d >>\nstream\n", len(rows))
+	buf.Write(rows)
+	fmt.Fprintf(&buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	doc := openPDF(t, buf.Bytes())
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatalf("Pages failed: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Num != 3 {
+		t.Fatalf("Expected page object 3, got %v", pages)
+	}
+
+	// Rewriting expands the object stream into a classic table.
+	out := rewrite(t, doc)
+	page := out.GetDict(pdf.Ref{Num: 3})
+	if out.GetName(page["Type"]) != "Page" {
+		t.Errorf("Expected page object after rewrite, got %v", page)
+	}
+	if obj, _ := out.Object(4); obj != nil {
+		t.Errorf("Expected object stream to be dropped, got %v", obj)
+	}
+}
+
+func TestNewDocument_HybridXref(t *testing.T) {
+	// The table lists object 6 as free for older readers; the /XRefStm
+	// stream of the same section stores it in object stream 4.
+	objects := append(samplePages(1, ""),
+		"<< /Type /ObjStm /N 1 /First 4 /Length 25 >>\nstream\n6 0 << /Title (Hybrid) >>\nendstream",
+		"<< /Type /XRef /Size 7 /Index [6 1] /W [1 2 1] /Length 4 >>\nstream\n\x02\x00\x04\x00\nendstream",
+		"null",
+	)
+	data := pdftest.BuildWithTrailer("/Info 6 0 R /XRefStm 0000000000 ", objects...)
+	xrefStm := bytes.Index(data, []byte("5 0 obj"))
+	data = bytes.Replace(data, []byte("/XRefStm 0000000000"), fmt.Appendf(nil, "/XRefStm %010d", xrefStm), 1)
+	entry := fmt.Appendf(nil, "%010d 00000 n \n", bytes.Index(data, []byte("6 0 obj")))
+	data = bytes.Replace(data, entry, []byte("0000000000 00000 f \n"), 1)
+
+	doc := openPDF(t, data)
+	info := doc.GetDict(doc.Trailer()["Info"])
+	if title, _ := doc.GetString(info["Title"]); title != "Hybrid" {
+		t.Fatalf("Expected the Info dictionary from the object stream, got %v", info)
+	}
+
+	// Rewriting keeps the object.
+	out := rewrite(t, doc)
+	info = out.GetDict(out.Trailer()["Info"])
+	if title, _ := out.GetString(info["Title"]); title != "Hybrid" {
+		t.Errorf("Expected the Info dictionary after rewrite, got %v", info)
+	}
+}
+
+func TestNewDocument_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"not a PDF", "hello world", pdf.ErrNotPDF},
+		{"no startxref", "%PDF-1.4\n1 0 obj\n<< >>\nendobj\n", pdf.ErrMalformed},
+		{"bad xref offset", "%PDF-1.4\nstartxref\n9999\n%%EOF", pdf.ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pdf.NewDocument(bytes.NewReader([]byte(tt.data)), int64(len(tt.data)))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDocument_Write_RoundTrip(t *testing.T) {
+	objects := append(samplePages(1, ""),
+		`<< /Length 5 >>
+stream
+hello
+endstream`,
+		`(line\nbreak \\ and \) paren)`)
+	doc := openPDF(t, pdftest.Build(objects...))
+	doc.Set(pdf.Ref{Num: 1}, pdf.Dict{"Type": pdf.Name("Catalog"), "Pages": pdf.Ref{Num: 2}, "Extra": pdf.Name("Yes")})
+	added := doc.Add(pdf.String("new"))
+
+	out := rewrite(t, doc)
+	if s, _ := out.GetString(added); s != "new" {
+		t.Errorf("Expected added object, got %q", s)
+	}
+	if out.GetName(out.GetDict(pdf.Ref{Num: 1})["Extra"]) != "Yes" {
+		t.Error("Expected replaced catalog to be written")
+	}
+	stream := out.GetStream(pdf.Ref{Num: 4})
+	if stream == nil || string(stream.Data) != "hello" {
+		t.Errorf("Expected stream data to survive, got %v", stream)
+	}
+	if s, _ := out.GetString(pdf.Ref{Num: 5}); s != "line\nbreak \\ and ) paren" {
+		t.Errorf("Expected string to survive, got %q", s)
+	}
+}
+
+func TestTextString_RoundTrip(t *testing.T) {
+	for _, s := range []string{"Plain title", "Café", "Über – 日本語"} {
+		if got := pdf.DecodeTextString(pdf.EncodeTextString(s)); got != s {
+			t.Errorf("Round trip of %q gave %q", s, got)
+		}
+	}
+}
+
+func zlibBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
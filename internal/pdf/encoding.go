@@ -0,0 +1,160 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// asciiGlyphs names the glyphs of codes 0x20 to 0x7E in WinAnsiEncoding.
+var asciiGlyphs = strings.Fields(`space exclam quotedbl numbersign dollar percent ampersand quotesingle
+	parenleft parenright asterisk plus comma hyphen period slash zero one two three four five six seven
+	eight nine colon semicolon less equal greater question at A B C D E F G H I J K L M N O P Q R S T U V
+	W X Y Z bracketleft backslash bracketright asciicircum underscore grave a b c d e f g h i j k l m n o
+	p q r s t u v w x y z braceleft bar braceright asciitilde`)
+
+// latin1Glyphs names the glyphs of codes 0xA0 to 0xFF in WinAnsiEncoding,
+// which match U+00A0 to U+00FF.
+var latin1Glyphs = strings.Fields(`space exclamdown cent sterling currency yen brokenbar section dieresis
+	copyright ordfeminine guillemotleft logicalnot hyphen registered macron degree plusminus twosuperior
+	threesuperior acute mu paragraph periodcentered cedilla onesuperior ordmasculine guillemotright
+	onequarter onehalf threequarters questiondown Agrave Aacute Acircumflex Atilde Adieresis Aring AE
+	Ccedilla Egrave Eacute Ecircumflex Edieresis Igrave Iacute Icircumflex Idieresis Eth Ntilde Ograve
+	Oacute Ocircumflex Otilde Odieresis multiply Oslash Ugrave Uacute Ucircumflex Udieresis Yacute Thorn
+	germandbls agrave aacute acircumflex atilde adieresis aring ae ccedilla egrave eacute ecircumflex
+	edieresis igrave iacute icircumflex idieresis eth ntilde ograve oacute ocircumflex otilde odieresis
+	divide oslash ugrave uacute ucircumflex udieresis yacute thorn ydieresis`)
+
+// winAnsiHigh names the glyphs of codes 0x80 to 0x9F in WinAnsiEncoding.
+var winAnsiHigh = map[byte]string{
+	0x80: "Euro", 0x82: "quotesinglbase", 0x83: "florin", 0x84: "quotedblbase", 0x85: "ellipsis",
+	0x86: "dagger", 0x87: "daggerdbl", 0x88: "circumflex", 0x89: "perthousand", 0x8A: "Scaron",
+	0x8B: "guilsinglleft", 0x8C: "OE", 0x8E: "Zcaron", 0x91: "quoteleft", 0x92: "quoteright",
+	0x93: "quotedblleft", 0x94: "quotedblright", 0x95: "bullet", 0x96: "endash", 0x97: "emdash",
+	0x98: "tilde", 0x99: "trademark", 0x9A: "scaron", 0x9B: "guilsinglright", 0x9C: "oe",
+	0x9E: "zcaron", 0x9F: "Ydieresis",
+}
+
+// standardHigh names the glyphs of StandardEncoding above 0x7E.
+var standardHigh = map[byte]string{
+	0xA1: "exclamdown", 0xA2: "cent", 0xA3: "sterling", 0xA4: "fraction", 0xA5: "yen", 0xA6: "florin",
+	0xA7: "section", 0xA8: "currency", 0xA9: "quotesingle", 0xAA: "quotedblleft", 0xAB: "guillemotleft",
+	0xAC: "guilsinglleft", 0xAD: "guilsinglright", 0xAE: "fi", 0xAF: "fl", 0xB1: "endash", 0xB2: "dagger",
+	0xB3: "daggerdbl", 0xB4: "periodcentered", 0xB6: "paragraph", 0xB7: "bullet", 0xB8: "quotesinglbase",
+	0xB9: "quotedblbase", 0xBA: "quotedblright", 0xBB: "guillemotright", 0xBC: "ellipsis",
+	0xBD: "perthousand", 0xBF: "questiondown", 0xC1: "grave", 0xC2: "acute", 0xC3: "circumflex",
+	0xC4: "tilde", 0xC5: "macron", 0xC6: "breve", 0xC7: "dotaccent", 0xC8: "dieresis", 0xCA: "ring",
+	0xCB: "cedilla", 0xCD: "hungarumlaut", 0xCE: "ogonek", 0xCF: "caron", 0xD0: "emdash", 0xE1: "AE",
+	0xE3: "ordfeminine", 0xE8: "Lslash", 0xE9: "Oslash", 0xEA: "OE", 0xEB: "ordmasculine", 0xF1: "ae",
+	0xF5: "dotlessi", 0xF8: "lslash", 0xF9: "oslash", 0xFA: "oe", 0xFB: "germandbls",
+}
+
+// macRomanHigh names the glyphs of codes 0x80 to 0xFF in MacRomanEncoding.
+var macRomanHigh = strings.Fields(`Adieresis Aring Ccedilla Eacute Ntilde Odieresis Udieresis aacute
+	agrave acircumflex adieresis atilde aring ccedilla eacute egrave ecircumflex edieresis iacute igrave
+	icircumflex idieresis ntilde oacute ograve ocircumflex odieresis otilde uacute ugrave ucircumflex
+	udieresis dagger degree cent sterling section bullet paragraph germandbls registered copyright
+	trademark acute dieresis notequal AE Oslash infinity plusminus lessequal greaterequal yen mu
+	partialdiff summation product pi integral ordfeminine ordmasculine Omega ae oslash questiondown
+	exclamdown logicalnot radical florin approxequal Delta guillemotleft guillemotright ellipsis space
+	Agrave Atilde Otilde OE oe endash emdash quotedblleft quotedblright quoteleft quoteright divide
+	lozenge ydieresis Ydieresis fraction currency guilsinglleft guilsinglright fi fl daggerdbl
+	periodcentered quotesinglbase quotedblbase perthousand Acircumflex Ecircumflex Aacute Edieresis
+	Egrave Iacute Icircumflex Idieresis Igrave Oacute Ocircumflex apple Ograve Uacute Ucircumflex Ugrave
+	dotlessi circumflex tilde macron breve dotaccent ring cedilla hungarumlaut ogonek caron`)
+
+// glyphText maps glyph names that are not derived from the tables above to
+// their text. Ligatures expand to their letters.
+var glyphText = map[string]string{
+	"Euro": "€", "quotesinglbase": "‚", "florin": "ƒ", "quotedblbase": "„", "ellipsis": "…",
+	"dagger": "†", "daggerdbl": "‡", "circumflex": "ˆ", "perthousand": "‰", "Scaron": "Š",
+	"guilsinglleft": "‹", "OE": "Œ", "Zcaron": "Ž", "quoteleft": "‘", "quoteright": "’",
+	"quotedblleft": "“", "quotedblright": "”", "bullet": "•", "endash": "–", "emdash": "—",
+	"tilde": "˜", "trademark": "™", "scaron": "š", "guilsinglright": "›", "oe": "œ", "zcaron": "ž",
+	"Ydieresis": "Ÿ", "fraction": "⁄", "fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl",
+	"dotlessi": "ı", "Lslash": "Ł", "lslash": "ł", "breve": "˘", "dotaccent": "˙", "ring": "˚",
+	"hungarumlaut": "˝", "ogonek": "˛", "caron": "ˇ", "notequal": "≠", "infinity": "∞",
+	"lessequal": "≤", "greaterequal": "≥", "partialdiff": "∂", "summation": "∑", "product": "∏",
+	"pi": "π", "integral": "∫", "Omega": "Ω", "radical": "√", "approxequal": "≈", "Delta": "∆",
+	"lozenge": "◊", "apple": "", "minus": "−", "nbspace": " ", "sfthyphen": "­",
+}
+
+func init() {
+	for i, name := range asciiGlyphs {
+		glyphText[name] = string(rune(0x20 + i))
+	}
+	for i, name := range latin1Glyphs[1:] {
+		if name != "hyphen" {
+			glyphText[name] = string(rune(0xA1 + i))
+		}
+	}
+}
+
+// glyphNameText returns the text for a glyph name, understanding the uniXXXX
+// and uXXXX[XX] conventions and suffixes such as "a.sc" or "f_i".
+func glyphNameText(name string) (string, bool) {
+	if s, ok := glyphText[name]; ok {
+		return s, true
+	}
+	if base, _, ok := strings.Cut(name, "."); ok && base != "" {
+		return glyphNameText(base)
+	}
+	if strings.Contains(name, "_") {
+		var b strings.Builder
+		for _, part := range strings.Split(name, "_") {
+			s, ok := glyphNameText(part)
+			if !ok {
+				return "", false
+			}
+			b.WriteString(s)
+		}
+		return b.String(), true
+	}
+	if hex, ok := strings.CutPrefix(name, "uni"); ok && len(hex) >= 4 && len(hex)%4 == 0 {
+		var b strings.Builder
+		for i := 0; i < len(hex); i += 4 {
+			v, err := strconv.ParseUint(hex[i:i+4], 16, 32)
+			if err != nil {
+				return "", false
+			}
+			b.WriteRune(rune(v))
+		}
+		return b.String(), true
+	}
+	if hex, ok := strings.CutPrefix(name, "u"); ok && len(hex) >= 4 && len(hex) <= 6 {
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return string(rune(v)), true
+		}
+	}
+	return "", false
+}
+
+// baseEncoding returns the glyph names for the 256 codes of a named
+// encoding. Unknown names fall back to StandardEncoding.
+func baseEncoding(name Name) [256]string {
+	var enc [256]string
+	for i, glyph := range asciiGlyphs {
+		enc[0x20+i] = glyph
+	}
+	switch name {
+	case "WinAnsiEncoding":
+		for code, glyph := range winAnsiHigh {
+			enc[code] = glyph
+		}
+		for i, glyph := range latin1Glyphs {
+			enc[0xA0+i] = glyph
+		}
+	case "MacRomanEncoding":
+		enc[0x27] = "quotesingle"
+		enc[0x60] = "grave"
+		for i, glyph := range macRomanHigh {
+			enc[0x80+i] = glyph
+		}
+	default:
+		enc[0x27] = "quoteright"
+		enc[0x60] = "quoteleft"
+		for code, glyph := range standardHigh {
+			enc[code] = glyph
+		}
+	}
+	return enc
+}
@@ -0,0 +1,19 @@
+package pdf
+
+import "errors"
+
+// Errors returned by the PDF parser and writer. Callers can match them with errors.Is.
+var (
+	// ErrNotPDF is returned when the input does not start with a %PDF- header.
+	ErrNotPDF = errors.New("not a PDF file")
+	// ErrMalformed is returned when the file structure cannot be parsed.
+	ErrMalformed = errors.New("malformed PDF")
+	// ErrEncrypted is returned for operations that are not supported on encrypted files.
+	ErrEncrypted = errors.New("encrypted PDFs are not supported")
+	// ErrUnsupportedFilter is returned when a stream uses a filter that cannot be decoded.
+	ErrUnsupportedFilter = errors.New("unsupported stream filter")
+	// ErrUnsupportedColorSpace is returned when image samples use a color space that cannot be converted.
+	ErrUnsupportedColorSpace = errors.New("unsupported color space")
+	// ErrPageOutOfRange is returned when a page number does not exist in the document.
+	ErrPageOutOfRange = errors.New("page number out of range")
+)
@@ -0,0 +1,300 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"fmt"
+	"io"
+)
+
+// streamFilters returns the filter names and their decode parameters.
+func (d *Document) streamFilters(s *Stream) ([]Name, []Dict) {
+	var names []Name
+	var params []Dict
+	switch f := d.resolveQuiet(s.Dict["Filter"]).(type) {
+	case Name:
+		names = []Name{f}
+		params = []Dict{d.GetDict(s.Dict["DecodeParms"])}
+	case Array:
+		parms := d.GetArray(s.Dict["DecodeParms"])
+		for i, item := range f {
+			name, _ := d.resolveQuiet(item).(Name)
+			names = append(names, name)
+			var p Dict
+			if i < len(parms) {
+				p = d.GetDict(parms[i])
+			}
+			params = append(params, p)
+		}
+	}
+	return names, params
+}
+
+// DecodeStream returns the stream data with all filters removed.
+func (d *Document) DecodeStream(s *Stream) ([]byte, error) {
+	names, params := d.streamFilters(s)
+	data := s.Data
+	for i, name := range names {
+		var err error
+		data, err = d.decodeFilter(name, params[i], data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (d *Document) decodeFilter(name Name, params Dict, data []byte) ([]byte, error) {
+	switch name {
+	case "FlateDecode", "Fl":
+		out, err := inflate(data)
+		if err != nil {
+			return nil, err
+		}
+		return d.unpredict(params, out)
+	case "LZWDecode", "LZW":
+		earlyChange := intOr(d, params["EarlyChange"], 1)
+		out, err := lzwDecode(data, earlyChange == 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.unpredict(params, out)
+	case "ASCII85Decode", "A85":
+		return ascii85Decode(data)
+	case "ASCIIHexDecode", "AHx":
+		return asciiHexDecode(data)
+	case "RunLengthDecode", "RL":
+		return runLengthDecode(data), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFilter, name)
+	}
+}
+
+// deflate compresses data with zlib at the best compression level.
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw, _ := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// inflate decompresses zlib data. Truncated streams are common in the wild,
+// so whatever was decoded before the error is returned if there is any.
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: flate: %v", ErrMalformed, err)
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("%w: flate: %v", ErrMalformed, err)
+	}
+	return out, nil
+}
+
+// ascii85Decode decodes ASCII base-85 data terminated by ~>.
+func ascii85Decode(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, 4*len(data)/5+4*bytes.Count(data, []byte("z"))+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ASCII85: %v", ErrMalformed, err)
+	}
+	return out[:n], nil
+}
+
+// asciiHexDecode decodes hexadecimal data terminated by >.
+func asciiHexDecode(data []byte) ([]byte, error) {
+	p := newParser(append([]byte{'<'}, data...))
+	if !bytes.Contains(data, []byte(">")) {
+		p.buf = append(p.buf, '>')
+	}
+	s, err := p.parseHexString()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s.(String)), nil
+}
+
+// runLengthDecode decodes the PackBits-style RunLengthDecode filter.
+func runLengthDecode(data []byte) []byte {
+	var out []byte
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		i++
+		switch {
+		case n == 128:
+			return out
+		case n < 128:
+			end := min(i+n+1, len(data))
+			out = append(out, data[i:end]...)
+			i = end
+		case i < len(data):
+			out = append(out, bytes.Repeat(data[i:i+1], 257-n)...)
+			i++
+		}
+	}
+	return out
+}
+
+// lzwDecode decodes LZW data with variable code widths from 9 to 12 bits.
+// compress/lzw cannot be used because PDF switches code width one code
+// early unless /EarlyChange is 0.
+func lzwDecode(data []byte, earlyChange bool) ([]byte, error) {
+	const (
+		clearCode = 256
+		eodCode   = 257
+	)
+	var out []byte
+	var table [][]byte
+	reset := func() {
+		table = table[:0]
+		for i := 0; i < 256; i++ {
+			table = append(table, []byte{byte(i)})
+		}
+		table = append(table, nil, nil)
+	}
+	reset()
+
+	early := 0
+	if earlyChange {
+		early = 1
+	}
+	width := 9
+	var bitBuf uint32
+	bits := 0
+	var prev []byte
+	for pos := 0; ; {
+		for bits < width && pos < len(data) {
+			bitBuf = bitBuf<<8 | uint32(data[pos])
+			bits += 8
+			pos++
+		}
+		if bits < width {
+			return out, nil
+		}
+		code := int(bitBuf>>(bits-width)) & (1<<width - 1)
+		bits -= width
+
+		switch {
+		case code == clearCode:
+			reset()
+			width = 9
+			prev = nil
+			continue
+		case code == eodCode:
+			return out, nil
+		}
+
+		var entry []byte
+		switch {
+		case code < len(table) && table[code] != nil:
+			entry = table[code]
+		case code == len(table) && prev != nil:
+			entry = append(append([]byte{}, prev...), prev[0])
+		default:
+			return nil, fmt.Errorf("%w: LZW: invalid code %d", ErrMalformed, code)
+		}
+		out = append(out, entry...)
+		if prev != nil && len(table) < 4096 {
+			table = append(table, append(append([]byte{}, prev...), entry[0]))
+		}
+		prev = entry
+		if len(table)+early >= 1<<width && width < 12 {
+			width++
+		}
+	}
+}
+
+// unpredict reverses the PNG and TIFF predictors used by Flate and LZW.
+func (d *Document) unpredict(params Dict, data []byte) ([]byte, error) {
+	predictor, _ := d.GetInt(params["Predictor"])
+	if predictor <= 1 {
+		return data, nil
+	}
+	colors := intOr(d, params["Colors"], 1)
+	bpc := intOr(d, params["BitsPerComponent"], 8)
+	columns := intOr(d, params["Columns"], 1)
+	bpp := max(1, colors*bpc/8)
+	rowLen := (colors*bpc*columns + 7) / 8
+
+	if predictor == 2 {
+		if bpc != 8 {
+			return nil, fmt.Errorf("%w: TIFF predictor with %d bits per component", ErrUnsupportedFilter, bpc)
+		}
+		for row := 0; row+rowLen <= len(data); row += rowLen {
+			for i := bpp; i < rowLen; i++ {
+				data[row+i] += data[row+i-bpp]
+			}
+		}
+		return data, nil
+	}
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for pos := 0; pos < len(data); pos += rowLen + 1 {
+		if pos+1 > len(data) {
+			break
+		}
+		filter := data[pos]
+		end := min(pos+1+rowLen, len(data))
+		row := make([]byte, rowLen)
+		copy(row, data[pos+1:end])
+		for i := 0; i < rowLen; i++ {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+			switch filter {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("%w: invalid PNG predictor row filter %d", ErrMalformed, filter)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func intOr(d *Document, obj Object, def int) int {
+	if v, ok := d.GetInt(obj); ok {
+		return int(v)
+	}
+	return def
+}
@@ -0,0 +1,53 @@
+package pdf_test
+
+import (
+	"encoding/ascii85"
+	"fmt"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// decodeObject builds a single-page document whose object 4 is a stream
+// with the given dictionary entries and data, and decodes that stream.
+func decodeObject(t *testing.T, dict string, data []byte) string {
+	t.Helper()
+	objects := append(samplePages(1, ""), fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data))
+	doc := openPDF(t, pdftest.Build(objects...))
+	out, err := doc.DecodeStream(doc.GetStream(pdf.Ref{Num: 4}))
+	if err != nil {
+		t.Fatalf("DecodeStream failed: %v", err)
+	}
+	return string(out)
+}
+
+func TestDecodeStream_Filters(t *testing.T) {
+	a85 := make([]byte, ascii85.MaxEncodedLen(len("Hello, filters")))
+	a85 = a85[:ascii85.Encode(a85, []byte("Hello, filters"))]
+
+	tests := []struct {
+		name string
+		dict string
+		data []byte
+		want string
+	}{
+		// The example from the LZWDecode section of the PDF specification.
+		{"LZW", "/Filter /LZWDecode", []byte{0x80, 0x0B, 0x60, 0x50, 0x22, 0x0C, 0x0C, 0x85, 0x01}, "-----A---B"},
+		{"ASCII85", "/Filter /ASCII85Decode", append(a85, "~>"...), "Hello, filters"},
+		{"ASCIIHex", "/Filter /ASCIIHexDecode", []byte("48 65 6c 6C 6>"), "Hell`"},
+		{"RunLength", "/Filter /RunLengthDecode", []byte{2, 'a', 'b', 'c', 254, 'z', 128}, "abczzz"},
+		{"Flate", "/Filter /FlateDecode", zlibBytes([]byte("compressed")), "compressed"},
+		{"Chain", "/Filter [/ASCIIHexDecode /RunLengthDecode]", []byte("FF41 80>"), "AA"},
+		// Two rows of three bytes with the PNG Up predictor.
+		{"PNG predictor", "/Filter /FlateDecode /DecodeParms << /Predictor 12 /Columns 3 >>",
+			zlibBytes([]byte{2, 1, 2, 3, 2, 1, 1, 1}), "\x01\x02\x03\x02\x03\x04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeObject(t, tt.dict, tt.data); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
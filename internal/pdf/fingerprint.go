@@ -0,0 +1,126 @@
+package pdf
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ContentHash returns the SHA-256 hash of what the document holds apart
+// from its metadata: the objects reachable from the catalog, without the
+// Info dictionary, XMP metadata streams and the file identifier. Objects are
+// numbered in the order they are reached and streams are hashed decoded, so
+// files that differ only in metadata, object numbering, compression, object
+// streams or earlier revisions have the same hash.
+func (d *Document) ContentHash() (string, error) {
+	if d.Encrypted() {
+		return "", ErrEncrypted
+	}
+	root, ok := d.trailer["Root"].(Ref)
+	if !ok {
+		return "", fmt.Errorf("%w: trailer has no /Root", ErrMalformed)
+	}
+	h := sha256.New()
+	c := &canonicalWriter{d: d, w: bufio.NewWriter(h), numbers: map[Ref]int{}}
+	c.ref(root)
+	for i := 0; i < len(c.queue); i++ {
+		obj, err := d.Object(c.queue[i].Num)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(c.w, "%d obj ", i)
+		c.object(obj)
+		c.w.WriteByte('\n')
+	}
+	if err := c.w.Flush(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalWriter serializes objects independently of their numbering and
+// encoding in the file.
+type canonicalWriter struct {
+	d *Document
+	w *bufio.Writer
+	// numbers holds the position of each reached object in queue.
+	numbers map[Ref]int
+	queue   []Ref
+}
+
+// ref writes the position of the referenced object, queueing the object
+// when it is reached for the first time.
+func (c *canonicalWriter) ref(r Ref) {
+	n, ok := c.numbers[r]
+	if !ok {
+		n = len(c.queue)
+		c.numbers[r] = n
+		c.queue = append(c.queue, r)
+	}
+	fmt.Fprintf(c.w, "%d R", n)
+}
+
+func (c *canonicalWriter) object(obj Object) {
+	switch v := obj.(type) {
+	case nil:
+		c.w.WriteString("null")
+	case bool:
+		c.w.WriteString(strconv.FormatBool(v))
+	case int64:
+		c.w.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		c.w.WriteString(formatReal(v))
+	case Name:
+		writeName(c.w, v)
+	case String:
+		// The length keeps strings from running into what follows.
+		fmt.Fprintf(c.w, "%d:", len(v))
+		c.w.WriteString(string(v))
+	case Array:
+		c.w.WriteByte('[')
+		for _, item := range v {
+			c.w.WriteByte(' ')
+			c.object(item)
+		}
+		c.w.WriteString(" ]")
+	case Dict:
+		c.dict(v, nil)
+	case Ref:
+		c.ref(v)
+	case *Stream:
+		data, err := c.d.DecodeStream(v)
+		skip := map[Name]bool{"Length": true, "Filter": true, "DecodeParms": true}
+		if err != nil {
+			// Streams with filters that cannot be decoded, such as images,
+			// are compared as stored.
+			data = v.Data
+			skip = map[Name]bool{"Length": true}
+		}
+		c.dict(v.Dict, skip)
+		fmt.Fprintf(c.w, " stream %d:", len(data))
+		c.w.Write(data)
+	}
+}
+
+// dict writes the entries of d other than those in skip and the XMP
+// metadata, in the order of their keys.
+func (c *canonicalWriter) dict(d Dict, skip map[Name]bool) {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		if !skip[k] && k != "Metadata" {
+			keys = append(keys, string(k))
+		}
+	}
+	sort.Strings(keys)
+	c.w.WriteString("<<")
+	for _, k := range keys {
+		c.w.WriteByte(' ')
+		writeName(c.w, Name(k))
+		c.w.WriteByte(' ')
+		c.object(d[Name(k)])
+	}
+	c.w.WriteString(" >>")
+}
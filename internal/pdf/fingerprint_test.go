@@ -0,0 +1,92 @@
+package pdf_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// reportObjects returns a one-page document with a content stream, an Info
+// dictionary and a catalog entry the caller chooses.
+func reportObjects(content, info string) []string {
+	return []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		streamObject("", []byte(content)),
+		info,
+	}
+}
+
+func contentHash(t *testing.T, doc *pdf.Document) string {
+	t.Helper()
+	sum, err := doc.ContentHash()
+	if err != nil {
+		t.Fatalf("ContentHash failed: %v", err)
+	}
+	return sum
+}
+
+func TestContentHash_IgnoresMetadataAndLayout(t *testing.T) {
+	original := openPDF(t, pdftest.BuildWithTrailer("/Info 5 0 R /ID [<01> <01>]",
+		reportObjects("BT (Q3) Tj ET", "<< /Title (Q3 Report) >>")...))
+	want := contentHash(t, original)
+
+	// Other metadata and file identifier.
+	retitled := openPDF(t, pdftest.BuildWithTrailer("/Info 5 0 R /ID [<02> <03>]",
+		reportObjects("BT (Q3) Tj ET", "<< /Title (Final) /Producer (Word) >>")...))
+	if got := contentHash(t, retitled); got != want {
+		t.Error("Expected other Info and /ID to keep the hash")
+	}
+
+	// XMP metadata, saved as an incremental update.
+	doc := openPDF(t, pdftest.BuildWithTrailer("/Info 5 0 R", reportObjects("BT (Q3) Tj ET", "<< >>")...))
+	if err := doc.SetMetadata(map[string]string{"Title": "Renamed", "dc:creator": "Ada"}, time.Unix(0, 0)); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := doc.WriteWithOptions(&buf, pdf.WriteOptions{Incremental: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := contentHash(t, openPDF(t, buf.Bytes())); got != want {
+		t.Error("Expected XMP metadata and an incremental update to keep the hash")
+	}
+
+	// Renumbered, recompressed and packed into object streams.
+	doc = openPDF(t, pdftest.Build(reportObjects("BT (Q3) Tj ET", "<< >>")...))
+	if _, err := doc.Optimize(); err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	buf.Reset()
+	if err := doc.WriteWithOptions(&buf, pdf.WriteOptions{ObjectStreams: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := contentHash(t, openPDF(t, buf.Bytes())); got != want {
+		t.Error("Expected an optimized copy to keep the hash")
+	}
+}
+
+func TestContentHash_ChangedContent(t *testing.T) {
+	a := openPDF(t, pdftest.Build(reportObjects("BT (Q3) Tj ET", "<< >>")...))
+	b := openPDF(t, pdftest.Build(reportObjects("BT (Q4) Tj ET", "<< >>")...))
+	if contentHash(t, a) == contentHash(t, b) {
+		t.Error("Expected different page content to change the hash")
+	}
+
+	rotated := reportObjects("BT (Q3) Tj ET", "<< >>")
+	rotated[2] = "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Rotate 90 >>"
+	if contentHash(t, a) == contentHash(t, openPDF(t, pdftest.Build(rotated...))) {
+		t.Error("Expected a rotated page to change the hash")
+	}
+}
+
+func TestContentHash_Encrypted(t *testing.T) {
+	doc := openPDF(t, pdftest.BuildWithTrailer("/Encrypt << /Filter /Standard >>", samplePages(1, "")...))
+	if _, err := doc.ContentHash(); !errors.Is(err, pdf.ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package pdf
+
+// defaultGlyphWidth is used for simple fonts without a /Widths array, such
+// as the standard 14 fonts, in thousandths of a text space unit.
+const defaultGlyphWidth = 500
+
+// font holds what text extraction needs from a font dictionary: how to split
+// strings into character codes, map them to text and advance the pen.
+type font struct {
+	baseFont  string
+	composite bool
+	toUnicode *toUnicodeCMap
+	// encoding names the glyph of each code of a simple font.
+	encoding [256]string
+	// widths maps character codes (CIDs for composite fonts) to glyph
+	// widths in thousandths of a text space unit.
+	widths       map[int]float64
+	defaultWidth float64
+}
+
+// glyph is a decoded character code.
+type glyph struct {
+	text  string
+	width float64
+	// wordSpace is set for the single-byte code 32, which word spacing
+	// applies to.
+	wordSpace bool
+}
+
+// loadFont reads the font dictionary obj. It never fails: missing or broken
+// entries fall back to defaults so that extraction degrades gracefully.
+func (d *Document) loadFont(obj Object) *font {
+	dict := d.GetDict(obj)
+	f := &font{
+		baseFont:     string(d.GetName(dict["BaseFont"])),
+		widths:       map[int]float64{},
+		defaultWidth: defaultGlyphWidth,
+	}
+	if s := d.GetStream(dict["ToUnicode"]); s != nil {
+		if data, err := d.DecodeStream(s); err == nil {
+			f.toUnicode = parseToUnicode(data)
+		}
+	}
+
+	if d.GetName(dict["Subtype"]) == "Type0" {
+		f.composite = true
+		f.defaultWidth = 1000
+		descendants := d.GetArray(dict["DescendantFonts"])
+		if len(descendants) > 0 {
+			d.loadCIDWidths(f, d.GetDict(descendants[0]))
+		}
+		return f
+	}
+
+	d.loadSimpleEncoding(f, dict["Encoding"])
+	first, _ := d.GetInt(dict["FirstChar"])
+	for i, w := range d.GetArray(dict["Widths"]) {
+		if width, ok := d.GetNumber(w); ok {
+			f.widths[int(first)+i] = width
+		}
+	}
+	if missing, ok := d.GetNumber(d.GetDict(dict["FontDescriptor"])["MissingWidth"]); ok && missing > 0 {
+		f.defaultWidth = missing
+	}
+	return f
+}
+
+func (d *Document) loadSimpleEncoding(f *font, obj Object) {
+	switch enc := d.resolveQuiet(obj).(type) {
+	case Name:
+		f.encoding = baseEncoding(enc)
+	case Dict:
+		f.encoding = baseEncoding(d.GetName(enc["BaseEncoding"]))
+		code := 0
+		for _, item := range d.GetArray(enc["Differences"]) {
+			switch v := d.resolveQuiet(item).(type) {
+			case int64:
+				code = int(v)
+			case Name:
+				if code >= 0 && code < 256 {
+					f.encoding[code] = string(v)
+				}
+				code++
+			}
+		}
+	default:
+		f.encoding = baseEncoding("StandardEncoding")
+	}
+}
+
+// loadCIDWidths reads /DW and /W from a CIDFont. /W holds entries of the
+// forms "c [w1 w2 ...]" and "cfirst clast w".
+func (d *Document) loadCIDWidths(f *font, cidFont Dict) {
+	if dw, ok := d.GetNumber(cidFont["DW"]); ok {
+		f.defaultWidth = dw
+	}
+	w := d.GetArray(cidFont["W"])
+	for i := 0; i < len(w); {
+		first, ok := d.GetInt(w[i])
+		if !ok || i+1 >= len(w) {
+			return
+		}
+		if list := d.GetArray(w[i+1]); list != nil {
+			for j, item := range list {
+				if width, ok := d.GetNumber(item); ok {
+					f.widths[int(first)+j] = width
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, _ := d.GetInt(w[i+1])
+		width, _ := d.GetNumber(w[i+2])
+		for c := first; c <= last && c-first < 0x10000; c++ {
+			f.widths[int(c)] = width
+		}
+		i += 3
+	}
+}
+
+// decode splits s into character codes and maps each to text and width.
+func (f *font) decode(s String) []glyph {
+	b := []byte(s)
+	var glyphs []glyph
+	for len(b) > 0 {
+		n := 1
+		switch {
+		case f.toUnicode != nil:
+			n = f.toUnicode.codeLength(b)
+		case f.composite:
+			n = 2
+		}
+		n = min(n, len(b))
+		code := b[:n]
+		b = b[n:]
+
+		g := glyph{width: f.defaultWidth, wordSpace: n == 1 && code[0] == ' '}
+		cid := int(beUint(code))
+		if w, ok := f.widths[cid]; ok {
+			g.width = w
+		}
+		if f.toUnicode != nil {
+			if text, ok := f.toUnicode.mapping[string(code)]; ok {
+				g.text = text
+				glyphs = append(glyphs, g)
+				continue
+			}
+		}
+		if !f.composite {
+			if text, ok := glyphNameText(f.encoding[code[0]]); ok {
+				g.text = text
+			} else if code[0] >= 0x20 && code[0] < 0x7F {
+				g.text = string(rune(code[0]))
+			}
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
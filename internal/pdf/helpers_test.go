@@ -0,0 +1,58 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// samplePages returns the object bodies of a document with n empty pages:
+// the catalog is object 1, the page tree object 2 and the pages follow.
+func samplePages(n int, catalogExtra string) []string {
+	kids := ""
+	for i := 0; i < n; i++ {
+		kids += fmt.Sprintf("%d 0 R ", i+3)
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R " + catalogExtra + ">>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, n),
+	}
+	for i := 0; i < n; i++ {
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>")
+	}
+	return objects
+}
+
+// openPDF parses data as a document.
+func openPDF(t *testing.T, data []byte) *pdf.Document {
+	t.Helper()
+	doc, err := pdf.NewDocument(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to parse PDF: %v", err)
+	}
+	return doc
+}
+
+// writeTempPDF writes data to a file in a temporary directory.
+func writeTempPDF(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.pdf")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	return path
+}
+
+// rewrite serializes doc with Write and parses the result again.
+func rewrite(t *testing.T, doc *pdf.Document) *pdf.Document {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return openPDF(t, buf.Bytes())
+}
@@ -0,0 +1,531 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strings"
+)
+
+// maxImagePixels bounds the size of images that are decoded, so that a
+// damaged /Width or /Height cannot exhaust memory.
+const maxImagePixels = 1 << 26
+
+// ImageInfo describes an image XObject used by a page.
+type ImageInfo struct {
+	Page int `json:"page"`
+	// Name is the resource name the page content uses for the image.
+	Name             string `json:"name"`
+	Object           int    `json:"object"`
+	Width            int    `json:"width"`
+	Height           int    `json:"height"`
+	ColorSpace       string `json:"colorSpace"`
+	BitsPerComponent int    `json:"bitsPerComponent"`
+	Filter           string `json:"filter"`
+	// Size is the length of the encoded stream data in bytes.
+	Size  int  `json:"size"`
+	SMask bool `json:"smask"`
+}
+
+// ExtractedImage records where an extracted image was written, or why it
+// could not be converted.
+type ExtractedImage struct {
+	ImageInfo
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Images lists the image XObjects in the resources of the pages in
+// selection, including those of nested form XObjects. An image is listed
+// once per page that uses it. Inline images are not included.
+func (d *Document) Images(selection string) ([]ImageInfo, error) {
+	labels, err := d.PageLabels()
+	if err != nil {
+		return nil, err
+	}
+	pages, err := selectPages(selection, labels)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := d.Pages()
+	if err != nil {
+		return nil, err
+	}
+	var images []ImageInfo
+	for _, page := range pages {
+		resources := d.GetDict(d.inheritedPageAttr(refs[page-1], "Resources"))
+		images = d.collectImages(images, page, resources, map[Ref]bool{}, 0)
+	}
+	return images, nil
+}
+
+func (d *Document) collectImages(images []ImageInfo, page int, resources Dict, seen map[Ref]bool, depth int) []ImageInfo {
+	xobjects := d.GetDict(resources["XObject"])
+	names := make([]string, 0, len(xobjects))
+	for name := range xobjects {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ref, ok := xobjects[Name(name)].(Ref)
+		if !ok || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		s := d.GetStream(ref)
+		if s == nil {
+			continue
+		}
+		switch d.GetName(s.Dict["Subtype"]) {
+		case "Image":
+			info := d.imageInfo(s)
+			info.Page, info.Name, info.Object = page, name, ref.Num
+			images = append(images, info)
+		case "Form":
+			if formResources := d.GetDict(s.Dict["Resources"]); formResources != nil && depth < maxFormDepth {
+				images = d.collectImages(images, page, formResources, seen, depth+1)
+			}
+		}
+	}
+	return images
+}
+
+func (d *Document) imageInfo(s *Stream) ImageInfo {
+	width, _ := d.GetInt(s.Dict["Width"])
+	height, _ := d.GetInt(s.Dict["Height"])
+	bpc, _ := d.GetInt(s.Dict["BitsPerComponent"])
+	filters, _ := d.streamFilters(s)
+	names := make([]string, len(filters))
+	for i, f := range filters {
+		names[i] = string(f)
+	}
+	info := ImageInfo{
+		Width:            int(width),
+		Height:           int(height),
+		BitsPerComponent: int(bpc),
+		ColorSpace:       d.colorSpaceName(s.Dict["ColorSpace"]),
+		Filter:           strings.Join(names, " "),
+		Size:             len(s.Data),
+		SMask:            d.GetStream(s.Dict["SMask"]) != nil,
+	}
+	if d.resolveQuiet(s.Dict["ImageMask"]) == true {
+		info.ColorSpace, info.BitsPerComponent = "ImageMask", 1
+	}
+	return info
+}
+
+// colorSpaceName describes a color space by its family, adding the base of
+// indexed color spaces, as in "Indexed DeviceRGB".
+func (d *Document) colorSpaceName(obj Object) string {
+	switch cs := d.resolveQuiet(obj).(type) {
+	case Name:
+		return string(cs)
+	case Array:
+		if len(cs) == 0 {
+			return ""
+		}
+		family := d.GetName(cs[0])
+		if (family == "Indexed" || family == "I") && len(cs) > 1 {
+			return string(family) + " " + d.colorSpaceName(cs[1])
+		}
+		return string(family)
+	}
+	return ""
+}
+
+// ExtractImage converts the image XObject with the given object number into
+// an image file and returns its contents and file extension. JPEG and JPEG
+// 2000 data is returned unchanged unless the image has a mask; everything
+// else is converted to PNG, with masks turned into transparency.
+func (d *Document) ExtractImage(num int) ([]byte, string, error) {
+	obj, err := d.Object(num)
+	if err != nil {
+		return nil, "", err
+	}
+	s, ok := obj.(*Stream)
+	if !ok || d.GetName(s.Dict["Subtype"]) != "Image" {
+		return nil, "", fmt.Errorf("%w: object %d is not an image", ErrMalformed, num)
+	}
+	data, codec, err := d.imageData(s)
+	if err != nil {
+		return nil, "", err
+	}
+	masked := d.resolveQuiet(s.Dict["SMask"]) != nil || d.resolveQuiet(s.Dict["Mask"]) != nil
+	switch codec {
+	case "DCTDecode", "DCT":
+		if !masked {
+			return data, ".jpg", nil
+		}
+	case "JPXDecode":
+		return data, ".jp2", nil
+	}
+
+	img, err := d.rasterize(s, data, codec)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := d.applyMasks(img, s); err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), ".png", nil
+}
+
+// imageData removes the general purpose filters of an image stream. It stops
+// at an image codec such as DCTDecode, which it returns with the data still
+// encoded.
+func (d *Document) imageData(s *Stream) ([]byte, Name, error) {
+	names, params := d.streamFilters(s)
+	data := s.Data
+	for i, name := range names {
+		switch name {
+		case "DCTDecode", "DCT", "JPXDecode", "CCITTFaxDecode", "CCF", "JBIG2Decode":
+			if i != len(names)-1 {
+				return nil, "", fmt.Errorf("%w: %s followed by another filter", ErrUnsupportedFilter, name)
+			}
+			return data, name, nil
+		}
+		var err error
+		data, err = d.decodeFilter(name, params[i], data)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return data, "", nil
+}
+
+// rasterize converts image data, as returned by imageData, into pixels.
+func (d *Document) rasterize(s *Stream, data []byte, codec Name) (*image.NRGBA, error) {
+	switch codec {
+	case "":
+	case "DCTDecode", "DCT":
+		src, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: jpeg: %v", ErrMalformed, err)
+		}
+		img := image.NewNRGBA(src.Bounds())
+		draw.Draw(img, img.Bounds(), src, src.Bounds().Min, draw.Src)
+		return img, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFilter, codec)
+	}
+
+	width, _ := d.GetInt(s.Dict["Width"])
+	height, _ := d.GetInt(s.Dict["Height"])
+	if width <= 0 || height <= 0 || width*height > maxImagePixels {
+		return nil, fmt.Errorf("%w: invalid image size %dx%d", ErrMalformed, width, height)
+	}
+	stencil := d.resolveQuiet(s.Dict["ImageMask"]) == true
+	bpc := int(intOr(d, s.Dict["BitsPerComponent"], 8))
+	cs := &imageColorSpace{comps: 1, gray: true}
+	if stencil {
+		bpc = 1
+	} else {
+		var err error
+		if cs, err = d.imageColorSpace(s.Dict["ColorSpace"], 0); err != nil {
+			return nil, err
+		}
+	}
+	switch bpc {
+	case 1, 2, 4, 8, 16:
+	default:
+		return nil, fmt.Errorf("%w: %d bits per component", ErrMalformed, bpc)
+	}
+
+	samples := sampleReader{data: data, bpc: bpc, comps: cs.comps, rowBytes: (int(width)*cs.comps*bpc + 7) / 8}
+	decode := d.decodeRanges(s, cs, bpc)
+	colorKey := d.colorKeyRanges(s, cs.comps)
+	maxValue := float64(uint32(1)<<bpc - 1)
+
+	img := image.NewNRGBA(image.Rect(0, 0, int(width), int(height)))
+	raw := make([]uint32, cs.comps)
+	values := make([]float64, cs.comps)
+	for y := 0; y < int(height); y++ {
+		for x := 0; x < int(width); x++ {
+			for c := range raw {
+				raw[c] = samples.sample(x, y, c)
+				values[c] = decode[2*c] + float64(raw[c])*(decode[2*c+1]-decode[2*c])/maxValue
+			}
+			i := img.PixOffset(x, y)
+			if stencil {
+				// Stencil masks paint where the decoded sample is 0.
+				if values[0] < 0.5 {
+					img.Pix[i+3] = 0xFF
+				}
+				continue
+			}
+			r, g, b := cs.rgb(values)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2] = toByte(r), toByte(g), toByte(b)
+			img.Pix[i+3] = 0xFF
+			if colorKey != nil && inColorKey(raw, colorKey) {
+				img.Pix[i+3] = 0
+			}
+		}
+	}
+	return img, nil
+}
+
+// applyMasks turns a soft mask or an explicit stencil mask into the alpha
+// channel of img, scaling the mask to the image size.
+func (d *Document) applyMasks(img *image.NRGBA, s *Stream) error {
+	mask := d.GetStream(s.Dict["SMask"])
+	soft := mask != nil
+	if !soft {
+		mask = d.GetStream(s.Dict["Mask"])
+	}
+	if mask == nil {
+		return nil
+	}
+	data, codec, err := d.imageData(mask)
+	if err != nil {
+		return fmt.Errorf("reading image mask: %w", err)
+	}
+	alpha, err := d.rasterize(mask, data, codec)
+	if err != nil {
+		return fmt.Errorf("reading image mask: %w", err)
+	}
+
+	bounds, mb := img.Bounds(), alpha.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		my := y * mb.Dy() / bounds.Dy()
+		for x := 0; x < bounds.Dx(); x++ {
+			mx := x * mb.Dx() / bounds.Dx()
+			m := alpha.Pix[alpha.PixOffset(mx, my):]
+			i := img.PixOffset(x, y) + 3
+			if soft {
+				// A soft mask is a grayscale image giving the opacity.
+				img.Pix[i] = m[0]
+			} else if m[3] != 0 {
+				// Explicit masks show the image where the mask, read as a
+				// stencil, would paint: with the default decoding, where
+				// samples are 0. Samples of 1 mask the image out.
+				img.Pix[i] = 0xFF
+			} else {
+				img.Pix[i] = 0
+			}
+		}
+	}
+	return nil
+}
+
+// decodeRanges returns the /Decode array of an image, or the default
+// mapping of the color space.
+func (d *Document) decodeRanges(s *Stream, cs *imageColorSpace, bpc int) []float64 {
+	ranges := make([]float64, 2*cs.comps)
+	for c := 0; c < cs.comps; c++ {
+		ranges[2*c+1] = 1
+		if cs.indexed {
+			ranges[2*c+1] = float64(uint32(1)<<bpc - 1)
+		}
+	}
+	decode := d.GetArray(s.Dict["Decode"])
+	if len(decode) != len(ranges) {
+		return ranges
+	}
+	for i, item := range decode {
+		if v, ok := d.GetNumber(item); ok {
+			ranges[i] = v
+		}
+	}
+	return ranges
+}
+
+// colorKeyRanges returns the /Mask array of an image masked by color key,
+// holding a minimum and maximum raw sample value per component.
+func (d *Document) colorKeyRanges(s *Stream, comps int) []uint32 {
+	mask := d.GetArray(s.Dict["Mask"])
+	if len(mask) != 2*comps {
+		return nil
+	}
+	ranges := make([]uint32, len(mask))
+	for i, item := range mask {
+		v, _ := d.GetInt(item)
+		ranges[i] = uint32(v)
+	}
+	return ranges
+}
+
+func inColorKey(raw []uint32, ranges []uint32) bool {
+	for c, v := range raw {
+		if v < ranges[2*c] || v > ranges[2*c+1] {
+			return false
+		}
+	}
+	return true
+}
+
+func toByte(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 0xFF
+	}
+	return uint8(v*255 + 0.5)
+}
+
+// sampleReader reads the packed samples of an image, whose rows start on
+// byte boundaries. Samples beyond the end of truncated data read as 0.
+type sampleReader struct {
+	data     []byte
+	bpc      int
+	comps    int
+	rowBytes int
+}
+
+func (r sampleReader) sample(x, y, c int) uint32 {
+	bit := y*r.rowBytes*8 + (x*r.comps+c)*r.bpc
+	i := bit / 8
+	switch {
+	case r.bpc == 16:
+		if i+1 >= len(r.data) {
+			return 0
+		}
+		return uint32(r.data[i])<<8 | uint32(r.data[i+1])
+	case i >= len(r.data):
+		return 0
+	case r.bpc == 8:
+		return uint32(r.data[i])
+	}
+	shift := 8 - r.bpc - bit%8
+	return uint32(r.data[i]>>shift) & (1<<r.bpc - 1)
+}
+
+// imageColorSpace converts decoded image samples to RGB.
+type imageColorSpace struct {
+	comps int
+	// gray is set for color spaces with a single gray component.
+	gray bool
+	// indexed is set for Indexed color spaces, whose samples are palette
+	// indexes rather than values from 0 to 1.
+	indexed bool
+	palette [][3]float64
+	// convert maps component values to RGB for color spaces that are
+	// neither gray nor indexed.
+	convert func(v []float64) (r, g, b float64)
+}
+
+func (cs *imageColorSpace) rgb(v []float64) (r, g, b float64) {
+	switch {
+	case cs.indexed:
+		i := int(v[0] + 0.5)
+		if i < 0 || i >= len(cs.palette) {
+			return 0, 0, 0
+		}
+		p := cs.palette[i]
+		return p[0], p[1], p[2]
+	case cs.gray:
+		return v[0], v[0], v[0]
+	}
+	return cs.convert(v)
+}
+
+func rgbColor(v []float64) (r, g, b float64) {
+	return v[0], v[1], v[2]
+}
+
+func cmykColor(v []float64) (r, g, b float64) {
+	k := 1 - v[3]
+	return (1 - v[0]) * k, (1 - v[1]) * k, (1 - v[2]) * k
+}
+
+// imageColorSpace resolves the color space of image samples. ICC profiles
+// are approximated by the device space with the same number of components,
+// and separations are shown as shades of gray by their total tint.
+func (d *Document) imageColorSpace(obj Object, depth int) (*imageColorSpace, error) {
+	if depth > 4 {
+		return nil, fmt.Errorf("%w: nested too deeply", ErrUnsupportedColorSpace)
+	}
+	obj = d.resolveQuiet(obj)
+	var family Name
+	var args Array
+	switch cs := obj.(type) {
+	case Name:
+		family = cs
+	case Array:
+		if len(cs) > 0 {
+			family, args = d.GetName(cs[0]), cs[1:]
+		}
+	}
+
+	switch family {
+	case "DeviceGray", "G", "CalGray":
+		return &imageColorSpace{comps: 1, gray: true}, nil
+	case "DeviceRGB", "RGB", "CalRGB":
+		return &imageColorSpace{comps: 3, convert: rgbColor}, nil
+	case "DeviceCMYK", "CMYK":
+		return &imageColorSpace{comps: 4, convert: cmykColor}, nil
+	case "ICCBased":
+		if len(args) > 0 {
+			n, _ := d.GetInt(d.GetDict(args[0])["N"])
+			switch n {
+			case 1:
+				return d.imageColorSpace(Name("DeviceGray"), depth+1)
+			case 3:
+				return d.imageColorSpace(Name("DeviceRGB"), depth+1)
+			case 4:
+				return d.imageColorSpace(Name("DeviceCMYK"), depth+1)
+			}
+		}
+	case "Indexed", "I":
+		if len(args) == 3 {
+			return d.indexedColorSpace(args, depth)
+		}
+	case "Separation":
+		return &imageColorSpace{comps: 1, convert: tintColor}, nil
+	case "DeviceN":
+		if len(args) > 0 {
+			if n := len(d.GetArray(args[0])); n > 0 {
+				return &imageColorSpace{comps: n, convert: tintColor}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedColorSpace, d.colorSpaceName(obj))
+}
+
+// tintColor shows colorant tints as gray, darker for more ink.
+func tintColor(v []float64) (r, g, b float64) {
+	var sum float64
+	for _, t := range v {
+		sum += t
+	}
+	gray := 1 - min(sum, 1)
+	return gray, gray, gray
+}
+
+func (d *Document) indexedColorSpace(args Array, depth int) (*imageColorSpace, error) {
+	base, err := d.imageColorSpace(args[0], depth+1)
+	if err != nil {
+		return nil, err
+	}
+	if base.indexed {
+		return nil, fmt.Errorf("%w: nested Indexed", ErrUnsupportedColorSpace)
+	}
+	hival, _ := d.GetInt(args[1])
+	var lookup []byte
+	switch v := d.resolveQuiet(args[2]).(type) {
+	case String:
+		lookup = []byte(v)
+	case *Stream:
+		if lookup, err = d.DecodeStream(v); err != nil {
+			return nil, err
+		}
+	}
+	cs := &imageColorSpace{comps: 1, indexed: true}
+	values := make([]float64, base.comps)
+	for i := 0; i <= int(hival) && i < 256 && (i+1)*base.comps <= len(lookup); i++ {
+		for c := range values {
+			values[c] = float64(lookup[i*base.comps+c]) / 255
+		}
+		r, g, b := base.rgb(values)
+		cs.palette = append(cs.palette, [3]float64{r, g, b})
+	}
+	return cs, nil
+}
@@ -0,0 +1,154 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+func streamObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// jpegBytes encodes a small gray JPEG.
+func jpegBytes(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// imagePDF returns a one-page document using a Flate RGB image with a soft
+// mask as /Im1, a JPEG as /Im2 and, inside form /Fm1, an indexed image.
+func imagePDF(t *testing.T) []byte {
+	rgb := zlibBytes([]byte{0xFF, 0, 0, 0, 0xFF, 0})
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im1 4 0 R /Im2 5 0 R /Fm1 6 0 R >> >> >>",
+		streamObject("/Type /XObject /Subtype /Image /Width 2 /Height 1 /ColorSpace /DeviceRGB /BitsPerComponent 8 /SMask 8 0 R /Filter /FlateDecode", rgb),
+		streamObject("/Type /XObject /Subtype /Image /Width 4 /Height 4 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode", jpegBytes(t)),
+		streamObject("/Type /XObject /Subtype /Form /BBox [0 0 1 1] /Resources << /XObject << /Im3 7 0 R >> >>", []byte("/Im3 Do")),
+		streamObject("/Type /XObject /Subtype /Image /Width 2 /Height 1 /ColorSpace [/Indexed /DeviceRGB 1 <FF00000000FF>] /BitsPerComponent 1 /Filter /ASCIIHexDecode", []byte("40>")),
+		streamObject("/Type /XObject /Subtype /Image /Width 2 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8", []byte{0xFF, 0x80}),
+	}
+	return pdftest.Build(objects...)
+}
+
+func TestDocument_Images(t *testing.T) {
+	doc := openPDF(t, imagePDF(t))
+	images, err := doc.Images("")
+	if err != nil {
+		t.Fatalf("Images failed: %v", err)
+	}
+	jpegSize := len(jpegBytes(t))
+	want := []pdf.ImageInfo{
+		{Page: 1, Name: "Im3", Object: 7, Width: 2, Height: 1, ColorSpace: "Indexed DeviceRGB", BitsPerComponent: 1, Filter: "ASCIIHexDecode", Size: 3},
+		{Page: 1, Name: "Im1", Object: 4, Width: 2, Height: 1, ColorSpace: "DeviceRGB", BitsPerComponent: 8, Filter: "FlateDecode", Size: len(zlibBytes([]byte{0xFF, 0, 0, 0, 0xFF, 0})), SMask: true},
+		{Page: 1, Name: "Im2", Object: 5, Width: 4, Height: 4, ColorSpace: "DeviceGray", BitsPerComponent: 8, Filter: "DCTDecode", Size: jpegSize},
+	}
+	if !reflect.DeepEqual(images, want) {
+		t.Errorf("Unexpected images:\n got %+v\nwant %+v", images, want)
+	}
+}
+
+func decodePNG(t *testing.T, data []byte, ext string) image.Image {
+	t.Helper()
+	if ext != ".png" {
+		t.Fatalf("Expected .png, got %s", ext)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	return img
+}
+
+func TestDocument_ExtractImage(t *testing.T) {
+	doc := openPDF(t, imagePDF(t))
+
+	t.Run("soft mask", func(t *testing.T) {
+		data, ext, err := doc.ExtractImage(4)
+		if err != nil {
+			t.Fatalf("ExtractImage failed: %v", err)
+		}
+		img := decodePNG(t, data, ext)
+		got := []color.NRGBA{
+			color.NRGBAModel.Convert(img.At(0, 0)).(color.NRGBA),
+			color.NRGBAModel.Convert(img.At(1, 0)).(color.NRGBA),
+		}
+		want := []color.NRGBA{{0xFF, 0, 0, 0xFF}, {0, 0xFF, 0, 0x80}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected pixels %v, got %v", want, got)
+		}
+	})
+
+	t.Run("jpeg", func(t *testing.T) {
+		data, ext, err := doc.ExtractImage(5)
+		if err != nil {
+			t.Fatalf("ExtractImage failed: %v", err)
+		}
+		if ext != ".jpg" || !bytes.Equal(data, jpegBytes(t)) {
+			t.Errorf("Expected the JPEG data unchanged, got %s of %d bytes", ext, len(data))
+		}
+	})
+
+	t.Run("indexed", func(t *testing.T) {
+		data, ext, err := doc.ExtractImage(7)
+		if err != nil {
+			t.Fatalf("ExtractImage failed: %v", err)
+		}
+		img := decodePNG(t, data, ext)
+		r0, g0, b0, _ := img.At(0, 0).RGBA()
+		r1, g1, b1, _ := img.At(1, 0).RGBA()
+		if r0 != 0xFFFF || g0 != 0 || b0 != 0 || r1 != 0 || g1 != 0 || b1 != 0xFFFF {
+			t.Errorf("Expected red then blue, got (%x %x %x) (%x %x %x)", r0, g0, b0, r1, g1, b1)
+		}
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		if _, _, err := doc.ExtractImage(6); err == nil {
+			t.Error("Expected an error for a form XObject")
+		}
+	})
+}
+
+func TestPDFService_ExtractImages(t *testing.T) {
+	path := writeTempPDF(t, imagePDF(t))
+	outDir := filepath.Join(t.TempDir(), "images")
+
+	extracted, err := pdf.NewPDFService().ExtractImages(path, "", outDir)
+	if err != nil {
+		t.Fatalf("ExtractImages failed: %v", err)
+	}
+	if len(extracted) != 3 {
+		t.Fatalf("Expected 3 images, got %d", len(extracted))
+	}
+	base := filepath.Base(path[:len(path)-len(filepath.Ext(path))])
+	want := filepath.Join(outDir, base+"-p1-5.jpg")
+	if extracted[2].Path != want {
+		t.Errorf("Expected %s, got %s", want, extracted[2].Path)
+	}
+	for _, img := range extracted {
+		if img.Error != "" {
+			t.Errorf("Unexpected error for object %d: %s", img.Object, img.Error)
+			continue
+		}
+		if _, err := os.Stat(img.Path); err != nil {
+			t.Errorf("Expected %s to be written: %v", img.Path, err)
+		}
+	}
+}
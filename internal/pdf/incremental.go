@@ -0,0 +1,135 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// writeIncremental copies the original file to w and appends the changed
+// objects with a cross-reference section listing only them, linked to the
+// original sections through /Prev. The section is a cross-reference stream
+// when the original ends with one, and a classic table otherwise.
+func (d *Document) writeIncremental(w io.Writer) error {
+	if d.r == nil {
+		return errors.New("an incremental update needs a document read from a file")
+	}
+	cw := &countingWriter{w: w}
+	if _, err := io.Copy(cw, io.NewSectionReader(d.r, 0, d.size)); err != nil {
+		return fmt.Errorf("copying the original file: %w", err)
+	}
+	if len(d.changed) == 0 {
+		return nil
+	}
+	out := bufio.NewWriter(cw)
+	if last, err := d.readAt(d.size-1, 1); err == nil && last[0] != '\n' && last[0] != '\r' {
+		out.WriteByte('\n')
+	}
+
+	nums := slices.Sorted(maps.Keys(d.changed))
+	entries := make(map[int]xrefEntry, len(nums)+1)
+	for _, num := range nums {
+		obj := d.changed[num]
+		if obj == nil {
+			// Deleted objects are reused with the next generation.
+			gen := 0
+			if e, ok := d.xref[num]; ok && e.typ == xrefInUse {
+				gen = min(e.gen+1, 65535)
+			}
+			entries[num] = xrefEntry{typ: xrefFree, gen: gen}
+			continue
+		}
+		gen := d.generation(num)
+		entries[num] = xrefEntry{typ: xrefInUse, offset: cw.n + int64(out.Buffered()), gen: gen}
+		if err := writeIndirect(out, num, gen, obj); err != nil {
+			return err
+		}
+	}
+
+	size := d.maxNum + 1
+	if n, ok := d.GetInt(d.trailer["Size"]); ok && int(n) > size {
+		size = int(n)
+	}
+	trailer := Dict{"Root": d.trailer["Root"], "Prev": d.startxref}
+	for _, key := range []Name{"Info", "ID"} {
+		if v, ok := d.trailer[key]; ok {
+			trailer[key] = v
+		}
+	}
+
+	xrefOffset := cw.n + int64(out.Buffered())
+	if d.GetName(d.trailer["Type"]) == "XRef" {
+		entries[size] = xrefEntry{typ: xrefInUse, offset: xrefOffset}
+		nums = append(nums, size)
+		trailer["Size"] = int64(size + 1)
+		if err := writeXrefStreamSection(out, size, trailer, nums, entries); err != nil {
+			return err
+		}
+	} else {
+		trailer["Size"] = int64(size)
+		out.WriteString("xref\n")
+		for _, sub := range subsections(nums) {
+			fmt.Fprintf(out, "%d %d\n", sub[0], sub[1])
+			for num := sub[0]; num < sub[0]+sub[1]; num++ {
+				e := entries[num]
+				if e.typ == xrefFree {
+					fmt.Fprintf(out, "%010d %05d f\r\n", 0, e.gen)
+				} else {
+					fmt.Fprintf(out, "%010d %05d n\r\n", e.offset, e.gen)
+				}
+			}
+		}
+		out.WriteString("trailer\n")
+		if err := writeObject(out, trailer); err != nil {
+			return err
+		}
+		out.WriteString("\n")
+	}
+	fmt.Fprintf(out, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+	return out.Flush()
+}
+
+// writeXrefStreamSection writes a cross-reference stream numbered num with
+// the entries of nums, which must be sorted.
+func writeXrefStreamSection(out *bufio.Writer, num int, dict Dict, nums []int, entries map[int]xrefEntry) error {
+	var maxField2, maxField3 int64 = 0, 0
+	for _, n := range nums {
+		maxField2 = max(maxField2, entries[n].offset)
+		maxField3 = max(maxField3, int64(entries[n].gen))
+	}
+	w2, w3 := byteWidth(maxField2), byteWidth(maxField3)
+	var rows bytes.Buffer
+	for _, n := range nums {
+		e := entries[n]
+		rows.WriteByte(byte(e.typ))
+		putUint(&rows, e.offset, w2)
+		putUint(&rows, int64(e.gen), w3)
+	}
+	var index Array
+	for _, sub := range subsections(nums) {
+		index = append(index, int64(sub[0]), int64(sub[1]))
+	}
+	dict["Type"] = Name("XRef")
+	dict["W"] = Array{int64(1), int64(w2), int64(w3)}
+	dict["Index"] = index
+	dict["Filter"] = Name("FlateDecode")
+	return writeIndirect(out, num, 0, &Stream{Dict: dict, Data: deflate(rows.Bytes())})
+}
+
+// subsections groups sorted object numbers into runs of consecutive
+// numbers, given as the first number and the length of the run.
+func subsections(nums []int) [][2]int {
+	var subs [][2]int
+	for _, num := range nums {
+		if n := len(subs); n > 0 && subs[n-1][0]+subs[n-1][1] == num {
+			subs[n-1][1]++
+			continue
+		}
+		subs = append(subs, [2]int{num, 1})
+	}
+	return subs
+}
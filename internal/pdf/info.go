@@ -0,0 +1,179 @@
+package pdf
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+// Info returns the entries of the document information dictionary that hold
+// text, such as Title, Author and Producer. It returns an empty map when the
+// document has no Info dictionary.
+func (d *Document) Info() (map[string]string, error) {
+	info := map[string]string{}
+	dict := d.GetDict(d.trailer["Info"])
+	for key, value := range dict {
+		if s, ok := d.GetString(value); ok {
+			info[string(key)] = DecodeTextString(s)
+		}
+	}
+	return info, nil
+}
+
+// SetInfo updates entries of the document information dictionary, creating
+// it if needed. An empty value removes the entry.
+func (d *Document) SetInfo(fields map[string]string) error {
+	if d.Encrypted() {
+		return ErrEncrypted
+	}
+	dict := d.GetDict(d.trailer["Info"]).Clone()
+	for key, value := range fields {
+		if value == "" {
+			delete(dict, Name(key))
+			continue
+		}
+		dict[Name(key)] = EncodeTextString(value)
+	}
+	if ref, ok := d.trailer["Info"].(Ref); ok {
+		d.Set(ref, dict)
+		return nil
+	}
+	d.trailer = d.trailer.Clone()
+	d.trailer["Info"] = d.Add(dict)
+	return nil
+}
+
+// SetMetadata updates entries of the Info dictionary like SetInfo and the
+// matching properties of the XMP metadata, creating the metadata stream if
+// needed. ModDate and xmp:MetadataDate are set to now in both, so the two
+// stay equivalent as PDF/A requires.
+func (d *Document) SetMetadata(fields map[string]string, now time.Time) error {
+	if d.Encrypted() {
+		return ErrEncrypted
+	}
+	fields = maps.Clone(fields)
+	fields["ModDate"] = FormatDate(now)
+	if err := d.SetInfo(fields); err != nil {
+		return err
+	}
+
+	props := map[string]string{"xmp:MetadataDate": formatXMPDate(now)}
+	for _, p := range infoXMPProperties {
+		value, ok := fields[p.info]
+		if !ok {
+			continue
+		}
+		if t, isDate := ParseDate(value); isDate && (p.info == "CreationDate" || p.info == "ModDate") {
+			value = formatXMPDate(t)
+		}
+		props[p.xmp] = value
+	}
+	return d.SetXMP(props)
+}
+
+// SetXMP updates properties of the XMP metadata, creating the metadata
+// stream if needed. props is keyed by XMP names such as "dc:title", as
+// XMPMetadata returns them; an empty value removes the property. Only
+// properties of the Dublin Core, PDF, XMP basic and PDF/A identification
+// schemas can be written.
+func (d *Document) SetXMP(props map[string]string) error {
+	if d.Encrypted() {
+		return ErrEncrypted
+	}
+	for name := range props {
+		if !writableXMPProperty(name) {
+			return fmt.Errorf("cannot write XMP property %s: unknown schema", name)
+		}
+	}
+	catalog, root, err := d.Catalog()
+	if err != nil {
+		return err
+	}
+	var packet []byte
+	stream := &Stream{Dict: Dict{"Type": Name("Metadata"), "Subtype": Name("XML")}}
+	if s := d.GetStream(catalog["Metadata"]); s != nil {
+		if packet, err = d.DecodeStream(s); err != nil {
+			return err
+		}
+		// PDF/A readers expect the packet unfiltered.
+		stream.Dict = s.Dict.Clone()
+		delete(stream.Dict, "Filter")
+		delete(stream.Dict, "DecodeParms")
+	}
+	if stream.Data, err = updateXMP(packet, props); err != nil {
+		return err
+	}
+	if ref, ok := catalog["Metadata"].(Ref); ok {
+		d.Set(ref, stream)
+		return nil
+	}
+	catalog = catalog.Clone()
+	catalog["Metadata"] = d.Add(stream)
+	d.Set(root, catalog)
+	return nil
+}
+
+// PDFAIdentification returns the PDF/A part and conformance level declared
+// in the XMP metadata, such as "2" and "B", or empty strings when the
+// document does not claim PDF/A conformance.
+func (d *Document) PDFAIdentification() (part, conformance string) {
+	xmp, err := d.XMPMetadata()
+	if err != nil {
+		return "", ""
+	}
+	return xmp["pdfaid:part"], xmp["pdfaid:conformance"]
+}
+
+// Fields returns the Info entries under their keys, such as "Title",
+// together with the XMP properties under their XMP names, such as
+// "dc:title".
+func (d *Document) Fields() (map[string]string, error) {
+	fields, err := d.Info()
+	if err != nil {
+		return nil, err
+	}
+	xmp, err := d.XMPMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("could not read XMP metadata: %w", err)
+	}
+	for name, value := range xmp {
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// SetFields updates fields keyed as Fields returns them: names containing a
+// colon are XMP properties and the others Info entries. An empty value
+// removes the entry. Info entries of documents that claim PDF/A conformance
+// are mirrored into the XMP metadata.
+func (d *Document) SetFields(fields map[string]string) error {
+	info, xmp := map[string]string{}, map[string]string{}
+	for name, value := range fields {
+		if strings.Contains(name, ":") {
+			xmp[name] = value
+		} else {
+			info[name] = value
+		}
+	}
+	if len(info) > 0 {
+		if err := setInfo(d, info); err != nil {
+			return fmt.Errorf("could not update metadata: %w", err)
+		}
+	}
+	if len(xmp) > 0 {
+		if err := d.SetXMP(xmp); err != nil {
+			return fmt.Errorf("could not update XMP metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// setInfo updates the Info dictionary, using SetMetadata for documents that
+// claim PDF/A conformance.
+func setInfo(doc *Document, fields map[string]string) error {
+	if part, _ := doc.PDFAIdentification(); part != "" {
+		return doc.SetMetadata(fields, time.Now())
+	}
+	return doc.SetInfo(fields)
+}
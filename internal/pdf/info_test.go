@@ -0,0 +1,72 @@
+package pdf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+func TestDocument_SetInfo_CreatesDictionary(t *testing.T) {
+	doc := openPDF(t, pdftest.Build(samplePages(1, "")...))
+	info, err := doc.Info()
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if len(info) != 0 {
+		t.Fatalf("expected no Info entries, got %v", info)
+	}
+
+	if err := doc.SetInfo(map[string]string{"Title": "Über", "Author": "Ada"}); err != nil {
+		t.Fatalf("SetInfo failed: %v", err)
+	}
+	info, err = rewrite(t, doc).Info()
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	want := map[string]string{"Title": "Über", "Author": "Ada"}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("expected %v, got %v", want, info)
+	}
+}
+
+func TestDocument_SetInfo_UpdatesAndRemoves(t *testing.T) {
+	objects := append(samplePages(1, ""), "<< /Title (Old) /Author (Ada) /Producer (Tool) >>")
+	doc := openPDF(t, pdftest.BuildWithTrailer("/Info 4 0 R", objects...))
+
+	if err := doc.SetInfo(map[string]string{"Title": "New", "Author": ""}); err != nil {
+		t.Fatalf("SetInfo failed: %v", err)
+	}
+	info, err := rewrite(t, doc).Info()
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	want := map[string]string{"Title": "New", "Producer": "Tool"}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("expected %v, got %v", want, info)
+	}
+}
+
+func TestPDFService_WriteMetadata(t *testing.T) {
+	objects := append(samplePages(1, ""), "<< /Title (Old) /Producer (Tool) >>")
+	path := writeTempPDF(t, pdftest.BuildWithTrailer("/Info 4 0 R", objects...))
+	service := pdf.NewPDFService()
+
+	fields := map[string]string{"Title": "", "Author": "Ada", "dc:subject": "pdf; metadata", "dc:title": "New"}
+	if err := service.WriteMetadata(path, fields); err != nil {
+		t.Fatalf("WriteMetadata failed: %v", err)
+	}
+	got, err := service.ReadMetadata(path)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	want := map[string]string{"Author": "Ada", "Producer": "Tool", "dc:subject": "pdf; metadata", "dc:title": "New"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if err := service.WriteMetadata(path, map[string]string{"photoshop:City": "Oslo"}); err == nil {
+		t.Error("expected an error for a property of an unknown schema")
+	}
+}
@@ -2,5 +2,74 @@ package pdf
 
 // PDFMetadataHandler defines methods for PDF metadata operations.
 type PDFMetadataHandler interface {
-	UpdateMetadata(filePath, title, producer string) error
+	UpdateMetadata(filePath string, fields map[string]string) error
+	ReadMetadata(filePath string) (map[string]string, error)
+	WriteMetadata(filePath string, fields map[string]string) error
+}
+
+// OutlineHandler defines methods for reading and replacing PDF bookmarks.
+type OutlineHandler interface {
+	ReadOutline(filePath string) ([]*OutlineItem, error)
+	WriteOutline(filePath string, items []*OutlineItem) error
+}
+
+// PageLabelHandler defines methods for reading and replacing page labels.
+type PageLabelHandler interface {
+	ReadPageLabels(filePath string) ([]PageLabelRange, []string, error)
+	WritePageLabels(filePath string, ranges []PageLabelRange) error
+}
+
+// TextExtractor defines methods for extracting plain text from PDF pages.
+type TextExtractor interface {
+	ExtractText(filePath, selection string) ([]PageText, error)
+}
+
+// InfoHandler defines methods for the document information dictionary.
+type InfoHandler interface {
+	ReadInfo(filePath string) (map[string]string, error)
+	WriteInfo(filePath string, fields map[string]string) error
+}
+
+// ContentReader defines methods for reading positioned page text.
+type ContentReader interface {
+	ReadPageTextSpans(filePath string, page int) ([]TextSpan, error)
+}
+
+// ImageHandler defines methods for listing and extracting embedded images.
+type ImageHandler interface {
+	ListImages(filePath, selection string) ([]ImageInfo, error)
+	ExtractImages(filePath, selection, outDir string) ([]ExtractedImage, error)
+}
+
+// Optimizer defines methods for reducing the size of PDF files.
+type Optimizer interface {
+	Optimize(filePath, outPath string, opts OptimizeOptions) (*OptimizeReport, error)
+}
+
+// Validator defines methods for checking the structure of PDF files.
+type Validator interface {
+	Validate(filePath string) (*ValidationReport, error)
+	ValidatePDFA(filePath string) (*ValidationReport, error)
+}
+
+// Repairer defines methods for rebuilding damaged PDF files.
+type Repairer interface {
+	Repair(filePath, outPath string) (*RepairReport, error)
+}
+
+// PageAssembler defines methods for combining and splitting PDF files.
+type PageAssembler interface {
+	Merge(filePaths []string, outPath string) error
+	Split(filePath, outDir string, selections []string) ([]string, error)
+}
+
+// ContentHasher defines methods for identifying PDF files by their content.
+type ContentHasher interface {
+	ContentHash(filePath string) (string, error)
+}
+
+// Differ defines methods for comparing PDF files and their revisions.
+type Differ interface {
+	DiffFiles(oldPath, newPath string) ([]Change, error)
+	DiffRevisions(filePath string) ([]RevisionDiff, error)
 }
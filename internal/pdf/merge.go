@@ -0,0 +1,216 @@
+package pdf
+
+import "fmt"
+
+// Merge returns a new document holding the pages of docs in order, with the
+// Info dictionary of the first. Outlines, forms, page labels and other
+// catalog entries are not carried over.
+func Merge(docs ...*Document) (*Document, error) {
+	a := newAssembler()
+	for i, doc := range docs {
+		pages, err := doc.Pages()
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		if err := a.addPages(doc, pages); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+	}
+	return a.finish(), nil
+}
+
+// ExtractPages returns a new document holding the given 1-based pages of d
+// in order, with the Info dictionary of d. Like Merge, it does not carry
+// over catalog entries other than the pages.
+func (d *Document) ExtractPages(pages []int) (*Document, error) {
+	all, err := d.Pages()
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]Ref, len(pages))
+	for i, n := range pages {
+		if n < 1 || n > len(all) {
+			return nil, fmt.Errorf("%w: page %d of %d", ErrPageOutOfRange, n, len(all))
+		}
+		refs[i] = all[n-1]
+	}
+	a := newAssembler()
+	if err := a.addPages(d, refs); err != nil {
+		return nil, err
+	}
+	return a.finish(), nil
+}
+
+// assembler builds a document from the pages of other documents.
+type assembler struct {
+	dst     *Document
+	root    Ref
+	kids    Array
+	info    Object
+	hasInfo bool
+}
+
+func newAssembler() *assembler {
+	dst := newDocument(nil, 0)
+	return &assembler{dst: dst, root: dst.Add(nil)}
+}
+
+// addPages copies pages of src, with everything they refer to, below the
+// page tree root of the new document.
+func (a *assembler) addPages(src *Document, pages []Ref) error {
+	if src.Encrypted() {
+		return ErrEncrypted
+	}
+	c := &copier{src: src, dst: a.dst, refs: map[int]Ref{}}
+	// Reserve the pages first, so that links between copied pages point to
+	// the copies.
+	refs := make([]Ref, len(pages))
+	for i, page := range pages {
+		if r, ok := c.refs[page.Num]; ok {
+			refs[i] = r
+			continue
+		}
+		refs[i] = a.dst.Add(nil)
+		c.refs[page.Num] = refs[i]
+	}
+	for i, page := range pages {
+		if err := c.copyPage(page, refs[i], a.root); err != nil {
+			return err
+		}
+		a.kids = append(a.kids, refs[i])
+	}
+
+	if !a.hasInfo {
+		a.hasInfo = true
+		if info, ok := src.trailer["Info"]; ok {
+			copied, err := c.copy(info)
+			if err != nil {
+				return err
+			}
+			a.info = copied
+		}
+	}
+	if src.version > a.dst.version {
+		a.dst.version = src.version
+	}
+	return nil
+}
+
+// finish writes the page tree root and the catalog.
+func (a *assembler) finish() *Document {
+	a.dst.Set(a.root, Dict{"Type": Name("Pages"), "Kids": a.kids, "Count": int64(len(a.kids))})
+	catalog := a.dst.Add(Dict{"Type": Name("Catalog"), "Pages": a.root})
+	a.dst.trailer = Dict{"Root": catalog}
+	if a.info != nil {
+		a.dst.trailer["Info"] = a.info
+	}
+	return a.dst
+}
+
+// copier copies objects from one document to another, renumbering them.
+type copier struct {
+	src, dst *Document
+	// refs maps source object numbers to their copies.
+	refs map[int]Ref
+}
+
+// copyPage copies a page into ref, giving it parent as its parent and the
+// attributes it inherits from its ancestors in the source.
+func (c *copier) copyPage(page, ref, parent Ref) error {
+	dict := c.src.GetDict(page)
+	if dict == nil {
+		return fmt.Errorf("%w: page %v is not a dictionary", ErrMalformed, page)
+	}
+	out := make(Dict, len(dict)+4)
+	for key, value := range dict {
+		if key == "Parent" {
+			continue
+		}
+		copied, err := c.copy(value)
+		if err != nil {
+			return err
+		}
+		out[key] = copied
+	}
+	for _, key := range []Name{"Resources", "MediaBox", "CropBox", "Rotate"} {
+		if _, ok := out[key]; ok {
+			continue
+		}
+		if value := c.src.inheritedPageAttr(page, key); value != nil {
+			copied, err := c.copy(value)
+			if err != nil {
+				return err
+			}
+			out[key] = copied
+		}
+	}
+	out["Type"] = Name("Page")
+	out["Parent"] = parent
+	c.dst.Set(ref, out)
+	return nil
+}
+
+// copy returns a copy of obj whose references point to copies of the
+// objects they refer to.
+func (c *copier) copy(obj Object) (Object, error) {
+	switch v := obj.(type) {
+	case Ref:
+		return c.copyRef(v)
+	case Array:
+		out := make(Array, len(v))
+		for i, item := range v {
+			copied, err := c.copy(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = copied
+		}
+		return out, nil
+	case Dict:
+		out := make(Dict, len(v))
+		for key, value := range v {
+			copied, err := c.copy(value)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = copied
+		}
+		return out, nil
+	case *Stream:
+		dict, err := c.copy(v.Dict)
+		if err != nil {
+			return nil, err
+		}
+		return &Stream{Dict: dict.(Dict), Data: v.Data}, nil
+	}
+	return obj, nil
+}
+
+// copyRef copies the object ref refers to, once. References to pages that
+// are not copied and to page tree nodes become null, so that links to other
+// pages do not pull in the rest of the source document.
+func (c *copier) copyRef(ref Ref) (Object, error) {
+	if r, ok := c.refs[ref.Num]; ok {
+		return r, nil
+	}
+	obj, err := c.src.Object(ref.Num)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	if dict, ok := obj.(Dict); ok {
+		if t := c.src.GetName(dict["Type"]); t == "Page" || t == "Pages" {
+			return nil, nil
+		}
+	}
+	r := c.dst.Add(nil)
+	c.refs[ref.Num] = r
+	copied, err := c.copy(obj)
+	if err != nil {
+		return nil, err
+	}
+	c.dst.Set(r, copied)
+	return r, nil
+}
@@ -0,0 +1,152 @@
+package pdf_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// mediaBoxWidths returns the width of the media box of each page, which the
+// tests use to tell pages apart.
+func mediaBoxWidths(t *testing.T, doc *pdf.Document) []int64 {
+	t.Helper()
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatalf("Pages failed: %v", err)
+	}
+	var widths []int64
+	for _, page := range pages {
+		box := doc.GetArray(doc.GetDict(page)["MediaBox"])
+		if len(box) != 4 {
+			t.Fatalf("page %v has no media box", page)
+		}
+		w, _ := doc.GetInt(box[2])
+		widths = append(widths, w)
+	}
+	return widths
+}
+
+// sizedPages returns a document whose pages have the given widths. The
+// first page inherits its media box from the page tree and has a content
+// stream, and the second page links to the first.
+func sizedPages(widths ...int) []byte {
+	n := len(widths)
+	kids := ""
+	for i := range widths {
+		kids += fmt.Sprintf("%d 0 R ", i+3)
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %d 792] >>", kids, n, widths[0]),
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", n+3),
+	}
+	for i, w := range widths[1:] {
+		annots := ""
+		if i == 0 {
+			annots = "/Annots [<< /Type /Annot /Subtype /Link /Dest [3 0 R /Fit] >>] "
+		}
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d 792] %s>>", w, annots))
+	}
+	objects = append(objects, streamObject("", []byte("BT ET")), "<< /Title (Source) >>")
+	return pdftest.BuildWithTrailer(fmt.Sprintf("/Info %d 0 R ", len(objects)), objects...)
+}
+
+func TestMerge(t *testing.T) {
+	first := openPDF(t, sizedPages(100, 200))
+	second := openPDF(t, sizedPages(300, 400, 500))
+
+	merged, err := pdf.Merge(first, second)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	doc := rewrite(t, merged)
+	if got, want := mediaBoxWidths(t, doc), []int64{100, 200, 300, 400, 500}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected widths %v, got %v", want, got)
+	}
+	info, err := doc.Info()
+	if err != nil || info["Title"] != "Source" {
+		t.Errorf("Expected the Info of the first document, got %v, %v", info, err)
+	}
+
+	// Inherited content and links between copied pages point to the copies.
+	pages, _ := doc.Pages()
+	if data := doc.GetStream(doc.GetDict(pages[2])["Contents"]); data == nil || string(data.Data) != "BT ET" {
+		t.Errorf("Expected the content stream of the third page, got %v", data)
+	}
+	annots := doc.GetArray(doc.GetDict(pages[1])["Annots"])
+	dest := doc.GetArray(doc.GetDict(annots[0])["Dest"])
+	if dest[0] != pages[0] {
+		t.Errorf("Expected the link to point to the first page %v, got %v", pages[0], dest[0])
+	}
+}
+
+func TestExtractPages(t *testing.T) {
+	doc := openPDF(t, sizedPages(100, 200, 300))
+
+	part, err := doc.ExtractPages([]int{3, 2})
+	if err != nil {
+		t.Fatalf("ExtractPages failed: %v", err)
+	}
+	out := rewrite(t, part)
+	if got, want := mediaBoxWidths(t, out), []int64{300, 200}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected widths %v, got %v", want, got)
+	}
+	// The link to the first page, which was not extracted, is dropped.
+	pages, _ := out.Pages()
+	annots := out.GetArray(out.GetDict(pages[1])["Annots"])
+	if dest := out.GetArray(out.GetDict(annots[0])["Dest"]); dest[0] != nil {
+		t.Errorf("Expected a null destination, got %v", dest[0])
+	}
+
+	if _, err := doc.ExtractPages([]int{4}); !errors.Is(err, pdf.ErrPageOutOfRange) {
+		t.Errorf("Expected ErrPageOutOfRange, got %v", err)
+	}
+}
+
+func TestPDFService_MergeAndSplit(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.pdf"), filepath.Join(dir, "b.pdf")
+	if err := os.WriteFile(a, sizedPages(100, 200), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, sizedPages(300), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	service := pdf.NewPDFService()
+	merged := filepath.Join(dir, "merged.pdf")
+	if err := service.Merge([]string{a, b}, merged); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	paths, err := service.Split(merged, "", []string{"1-2", "3"})
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	want := []string{filepath.Join(dir, "merged-1.pdf"), filepath.Join(dir, "merged-2.pdf")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Expected parts %v, got %v", want, paths)
+	}
+	for i, widths := range [][]int64{{100, 200}, {300}} {
+		doc, err := pdf.Open(paths[i])
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		if got := mediaBoxWidths(t, doc); !reflect.DeepEqual(got, widths) {
+			t.Errorf("part %d: expected widths %v, got %v", i+1, widths, got)
+		}
+		doc.Close()
+	}
+
+	// Without selections every page becomes a part.
+	paths, err = service.Split(merged, t.TempDir(), nil)
+	if err != nil || len(paths) != 3 {
+		t.Errorf("Expected 3 parts, got %v, %v", paths, err)
+	}
+}
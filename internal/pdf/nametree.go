@@ -0,0 +1,77 @@
+package pdf
+
+// walkNameTree calls fn for every key/value pair in the name tree rooted at
+// root, stopping early if fn returns false.
+func (d *Document) walkNameTree(root Object, fn func(key String, value Object) bool) {
+	seen := map[Ref]bool{}
+	var walk func(node Object) bool
+	walk = func(node Object) bool {
+		if ref, ok := node.(Ref); ok {
+			if seen[ref] {
+				return true
+			}
+			seen[ref] = true
+		}
+		dict := d.GetDict(node)
+		if dict == nil {
+			return true
+		}
+		names := d.GetArray(dict["Names"])
+		for i := 0; i+1 < len(names); i += 2 {
+			key, _ := d.GetString(names[i])
+			if !fn(key, names[i+1]) {
+				return false
+			}
+		}
+		for _, kid := range d.GetArray(dict["Kids"]) {
+			if !walk(kid) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(root)
+}
+
+// lookupName finds key in the name tree rooted at root.
+func (d *Document) lookupName(root Object, key String) (Object, bool) {
+	var found Object
+	var ok bool
+	d.walkNameTree(root, func(k String, v Object) bool {
+		if k == key {
+			found, ok = v, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// walkNumberTree calls fn for every key/value pair in the number tree rooted
+// at root, in the order they appear.
+func (d *Document) walkNumberTree(root Object, fn func(key int64, value Object)) {
+	seen := map[Ref]bool{}
+	var walk func(node Object)
+	walk = func(node Object) {
+		if ref, ok := node.(Ref); ok {
+			if seen[ref] {
+				return
+			}
+			seen[ref] = true
+		}
+		dict := d.GetDict(node)
+		if dict == nil {
+			return
+		}
+		nums := d.GetArray(dict["Nums"])
+		for i := 0; i+1 < len(nums); i += 2 {
+			if key, ok := d.GetInt(nums[i]); ok {
+				fn(key, nums[i+1])
+			}
+		}
+		for _, kid := range d.GetArray(dict["Kids"]) {
+			walk(kid)
+		}
+	}
+	walk(root)
+}
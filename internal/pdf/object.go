@@ -0,0 +1,160 @@
+package pdf
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Object is a PDF object. It holds one of nil (null), bool, int64, float64,
+// Name, String, Array, Dict, Ref or *Stream.
+type Object any
+
+// Name is a PDF name object such as /Title, stored without the leading slash.
+type Name string
+
+// String is a PDF string object holding the raw, unescaped bytes.
+type String string
+
+// Array is a PDF array object.
+type Array []Object
+
+// Dict is a PDF dictionary object.
+type Dict map[Name]Object
+
+// Ref is an indirect reference to an object.
+type Ref struct {
+	Num int
+	Gen int
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%d %d R", r.Num, r.Gen)
+}
+
+// Stream is a PDF stream object. Data holds the stream bytes as stored in the
+// file, that is, still encoded with the filters listed in the dictionary.
+type Stream struct {
+	Dict Dict
+	Data []byte
+}
+
+// keyword is a bare token such as obj, stream or a content stream operator.
+type keyword string
+
+// Clone returns a shallow copy of the dictionary.
+func (d Dict) Clone() Dict {
+	c := make(Dict, len(d))
+	for k, v := range d {
+		c[k] = v
+	}
+	return c
+}
+
+// writeObject serializes obj in PDF syntax.
+func writeObject(w *bufio.Writer, obj Object) error {
+	switch v := obj.(type) {
+	case nil:
+		w.WriteString("null")
+	case bool:
+		w.WriteString(strconv.FormatBool(v))
+	case int:
+		w.WriteString(strconv.Itoa(v))
+	case int64:
+		w.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		w.WriteString(formatReal(v))
+	case Name:
+		writeName(w, v)
+	case String:
+		writeString(w, v)
+	case Array:
+		w.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				w.WriteByte(' ')
+			}
+			if err := writeObject(w, item); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case Dict:
+		return writeDict(w, v)
+	case Ref:
+		fmt.Fprintf(w, "%d %d R", v.Num, v.Gen)
+	case *Stream:
+		dict := v.Dict.Clone()
+		dict["Length"] = int64(len(v.Data))
+		if err := writeDict(w, dict); err != nil {
+			return err
+		}
+		w.WriteString("\nstream\n")
+		w.Write(v.Data)
+		w.WriteString("\nendstream")
+	case keyword:
+		w.WriteString(string(v))
+	default:
+		return fmt.Errorf("cannot serialize %T", obj)
+	}
+	return nil
+}
+
+func writeDict(w *bufio.Writer, d Dict) error {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	w.WriteString("<<")
+	for _, k := range keys {
+		w.WriteByte(' ')
+		writeName(w, Name(k))
+		w.WriteByte(' ')
+		if err := writeObject(w, d[Name(k)]); err != nil {
+			return err
+		}
+	}
+	w.WriteString(" >>")
+	return nil
+}
+
+func writeName(w *bufio.Writer, n Name) {
+	w.WriteByte('/')
+	for i := 0; i < len(n); i++ {
+		c := n[i]
+		if c < '!' || c > '~' || c == '#' || isDelimiter(c) {
+			fmt.Fprintf(w, "#%02X", c)
+			continue
+		}
+		w.WriteByte(c)
+	}
+}
+
+func writeString(w *bufio.Writer, s String) {
+	w.WriteByte('(')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', ')', '\\':
+			w.WriteByte('\\')
+			w.WriteByte(c)
+		case '\r':
+			w.WriteString(`\r`)
+		case '\n':
+			w.WriteString(`\n`)
+		default:
+			w.WriteByte(c)
+		}
+	}
+	w.WriteByte(')')
+}
+
+func formatReal(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,285 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"sort"
+)
+
+// maxDedupPasses bounds how often duplicates are searched again after
+// merging made further objects identical, such as fonts whose font files
+// were merged.
+const maxDedupPasses = 8
+
+// OptimizeStats counts the changes made by Optimize.
+type OptimizeStats struct {
+	ObjectsBefore       int `json:"objectsBefore"`
+	ObjectsAfter        int `json:"objectsAfter"`
+	UnusedObjects       int `json:"unusedObjects"`
+	MergedObjects       int `json:"mergedObjects"`
+	RecompressedStreams int `json:"recompressedStreams"`
+}
+
+// OptimizeOptions control PDFService.Optimize.
+type OptimizeOptions struct {
+	// Info holds Info dictionary entries to update in the same write, as
+	// for SetInfo.
+	Info map[string]string
+	// ObjectStreams packs objects into object streams, see WriteOptions.
+	ObjectStreams bool
+	// Images, when set, re-encodes images as JPEG, see CompressImages.
+	Images *ImageCompression
+}
+
+// OptimizeReport describes the result of optimizing a file.
+type OptimizeReport struct {
+	OptimizeStats
+	SizeBefore int64 `json:"sizeBefore"`
+	SizeAfter  int64 `json:"sizeAfter"`
+	// Unchanged is set when the optimized file was not smaller, so the
+	// original was kept.
+	Unchanged bool `json:"unchanged"`
+	// Pages lists the image savings per page when images were compressed.
+	Pages []PageSavings `json:"pages,omitempty"`
+}
+
+// Optimize reduces the size of the document in memory. It drops objects
+// that cannot be reached from the trailer, merges identical streams and
+// font dictionaries, recompresses streams with Flate where that makes them
+// smaller and renumbers the remaining objects. Write the result with
+// WriteOptions.ObjectStreams to also pack objects into object streams.
+//
+// Every object is loaded, so later changes such as SetInfo can be made
+// before or after optimizing and are written together.
+func (d *Document) Optimize() (*OptimizeStats, error) {
+	if d.Encrypted() {
+		return nil, ErrEncrypted
+	}
+	stats := &OptimizeStats{}
+	objects := map[int]Object{}
+	for num := 1; num <= d.maxNum; num++ {
+		obj, err := d.Object(num)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil || isStructuralStream(obj) {
+			continue
+		}
+		objects[num] = obj
+	}
+	stats.ObjectsBefore = len(objects)
+
+	live := reachable(objects, d.trailer)
+	stats.UnusedObjects = len(objects) - len(live)
+
+	canonical := dedupe(objects, live)
+	var kept []int
+	for _, num := range live {
+		if canonical[num] == num {
+			kept = append(kept, num)
+		}
+	}
+	stats.MergedObjects = len(live) - len(kept)
+
+	renumbered := map[int]int{}
+	for i, num := range kept {
+		renumbered[num] = i + 1
+	}
+	remap := func(ref Ref) Object {
+		if n, ok := renumbered[canonical[ref.Num]]; ok {
+			return Ref{Num: n}
+		}
+		return nil
+	}
+
+	changed := make(map[int]Object, len(kept))
+	for _, num := range kept {
+		obj := objects[num]
+		if s, ok := obj.(*Stream); ok {
+			if smaller, ok := d.recompress(s); ok {
+				obj = smaller
+				stats.RecompressedStreams++
+			}
+		}
+		changed[renumbered[num]] = remapRefs(obj, remap)
+	}
+	stats.ObjectsAfter = len(kept)
+
+	trailer := remapRefs(d.trailer, remap).(Dict)
+	d.trailer = trailer
+	d.changed = changed
+	d.objects = map[int]Object{}
+	d.streams = map[int]*cachedStream{}
+	d.objStms = map[int]*objectStream{}
+	d.xref = map[int]xrefEntry{}
+	d.maxNum = len(kept)
+	return stats, nil
+}
+
+// reachable returns the numbers of the objects referenced directly or
+// indirectly from the trailer, in ascending order. The /Length of streams
+// is not followed since streams are written with a direct length.
+func reachable(objects map[int]Object, trailer Dict) []int {
+	seen := map[int]bool{}
+	var visit func(obj Object)
+	visit = func(obj Object) {
+		switch v := obj.(type) {
+		case Ref:
+			target, ok := objects[v.Num]
+			if !ok || seen[v.Num] {
+				return
+			}
+			seen[v.Num] = true
+			visit(target)
+		case Array:
+			for _, item := range v {
+				visit(item)
+			}
+		case Dict:
+			for _, item := range v {
+				visit(item)
+			}
+		case *Stream:
+			for key, item := range v.Dict {
+				if key != "Length" {
+					visit(item)
+				}
+			}
+		}
+	}
+	for key, value := range trailer {
+		// The previous revisions and cross-reference streams are not kept.
+		if key != "Prev" && key != "XRefStm" && key != "Encrypt" {
+			visit(value)
+		}
+	}
+
+	nums := make([]int, 0, len(seen))
+	for num := range seen {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// dedupe maps each live object to the first object identical to it. Only
+// streams and font related dictionaries are merged: other objects, pages in
+// particular, are distinct even when their contents are equal.
+func dedupe(objects map[int]Object, live []int) map[int]int {
+	canonical := make(map[int]int, len(live))
+	for _, num := range live {
+		canonical[num] = num
+	}
+	remap := func(ref Ref) Object {
+		if n, ok := canonical[ref.Num]; ok {
+			return Ref{Num: n}
+		}
+		return nil
+	}
+	for pass := 0; pass < maxDedupPasses; pass++ {
+		merged := false
+		first := map[[sha256.Size]byte]int{}
+		for _, num := range live {
+			if canonical[num] != num || !mergeable(objects[num]) {
+				continue
+			}
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			if err := writeObject(w, remapRefs(objects[num], remap)); err != nil {
+				continue
+			}
+			w.Flush()
+			key := sha256.Sum256(buf.Bytes())
+			if n, ok := first[key]; ok {
+				canonical[num] = n
+				merged = true
+				continue
+			}
+			first[key] = num
+		}
+		if !merged {
+			break
+		}
+		// Point merged objects at the final canonical object.
+		for _, num := range live {
+			canonical[num] = canonical[canonical[num]]
+		}
+	}
+	return canonical
+}
+
+func mergeable(obj Object) bool {
+	switch v := obj.(type) {
+	case *Stream:
+		return true
+	case Dict:
+		switch v["Type"] {
+		case Name("Font"), Name("FontDescriptor"), Name("Encoding"):
+			return true
+		}
+	}
+	return false
+}
+
+// remapRefs returns a copy of obj with every reference replaced by the
+// result of remap. Dictionary entries that become null are dropped.
+func remapRefs(obj Object, remap func(Ref) Object) Object {
+	switch v := obj.(type) {
+	case Ref:
+		return remap(v)
+	case Array:
+		out := make(Array, len(v))
+		for i, item := range v {
+			out[i] = remapRefs(item, remap)
+		}
+		return out
+	case Dict:
+		out := make(Dict, len(v))
+		for key, item := range v {
+			if item = remapRefs(item, remap); item != nil {
+				out[key] = item
+			}
+		}
+		return out
+	case *Stream:
+		dict := remapRefs(v.Dict, remap).(Dict)
+		delete(dict, "Length")
+		return &Stream{Dict: dict, Data: v.Data}
+	}
+	return obj
+}
+
+// recompress returns s compressed with Flate when that is smaller than its
+// current encoding. Image codecs such as DCTDecode are kept, but filters
+// applied on top of them are removed. XMP metadata is left as is so that it
+// stays readable by tools that scan files for it.
+func (d *Document) recompress(s *Stream) (*Stream, bool) {
+	if d.GetName(s.Dict["Type"]) == "Metadata" {
+		return nil, false
+	}
+	names, params := d.streamFilters(s)
+	data, codec, err := d.imageData(s)
+	if err != nil {
+		return nil, false
+	}
+	dict := s.Dict.Clone()
+	delete(dict, "DecodeParms")
+	delete(dict, "DL")
+	if codec != "" {
+		if len(names) == 1 {
+			return nil, false
+		}
+		dict["Filter"] = codec
+		if p := params[len(params)-1]; p != nil {
+			dict["DecodeParms"] = p
+		}
+		return &Stream{Dict: dict, Data: data}, true
+	}
+	compressed := deflate(data)
+	if len(compressed) >= len(s.Data) {
+		return nil, false
+	}
+	dict["Filter"] = Name("FlateDecode")
+	return &Stream{Dict: dict, Data: compressed}, true
+}
@@ -0,0 +1,138 @@
+package pdf_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// bloatedPDF returns a two-page document whose pages have identical,
+// uncompressed content and each use their own copy of the same embedded
+// font. Object 12 is not referenced from anywhere.
+func bloatedPDF() []byte {
+	content := strings.Repeat("BT /F1 12 Tf 72 720 Td (Repeated page text) Tj ET\n", 40)
+	fontFile := strings.Repeat("font program bytes ", 20)
+	return pdftest.Build(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 5 0 R /Resources << /Font << /F1 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 6 0 R /Resources << /Font << /F1 8 0 R >> >> >>",
+		streamObject("", []byte(content)),
+		streamObject("", []byte(content)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Embedded /Encoding /WinAnsiEncoding /FontDescriptor 9 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Embedded /Encoding /WinAnsiEncoding /FontDescriptor 10 0 R >>",
+		"<< /Type /FontDescriptor /FontName /Embedded /Flags 32 /FontFile 11 0 R >>",
+		"<< /Type /FontDescriptor /FontName /Embedded /Flags 32 /FontFile 13 0 R >>",
+		streamObject("", []byte(fontFile)),
+		"(unused)",
+		streamObject("", []byte(fontFile)),
+	)
+}
+
+func TestDocument_Optimize(t *testing.T) {
+	doc := openPDF(t, bloatedPDF())
+	stats, err := doc.Optimize()
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	want := pdf.OptimizeStats{
+		ObjectsBefore:       13,
+		ObjectsAfter:        8,
+		UnusedObjects:       1,
+		MergedObjects:       4,
+		RecompressedStreams: 2,
+	}
+	if *stats != want {
+		t.Errorf("Expected %+v, got %+v", want, *stats)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.WriteWithOptions(&buf, pdf.WriteOptions{ObjectStreams: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.5")) {
+		t.Errorf("Expected the version to be raised to 1.5, got %q", buf.Bytes()[:8])
+	}
+	if len(buf.Bytes()) >= len(bloatedPDF()) {
+		t.Errorf("Expected the file to shrink from %d bytes, got %d", len(bloatedPDF()), buf.Len())
+	}
+
+	optimized := openPDF(t, buf.Bytes())
+	pages, err := optimized.Pages()
+	if err != nil || len(pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %v (%v)", pages, err)
+	}
+	first, second := optimized.GetDict(pages[0]), optimized.GetDict(pages[1])
+	if first["Contents"] != second["Contents"] {
+		t.Errorf("Expected pages to share their content, got %v and %v", first["Contents"], second["Contents"])
+	}
+	font := func(page pdf.Dict) pdf.Object {
+		return optimized.GetDict(optimized.GetDict(page["Resources"])["Font"])["F1"]
+	}
+	if font(first) != font(second) {
+		t.Errorf("Expected pages to share their font, got %v and %v", font(first), font(second))
+	}
+	for i := range pages {
+		text, err := optimized.PageText(i + 1)
+		if err != nil || !strings.HasPrefix(text, "Repeated page text") {
+			t.Errorf("Unexpected text on page %d: %q (%v)", i+1, text, err)
+		}
+	}
+}
+
+func TestPDFService_Optimize_WithMetadata(t *testing.T) {
+	path := writeTempPDF(t, bloatedPDF())
+	out := path + ".optimized.pdf"
+	defer os.Remove(out)
+
+	report, err := pdf.NewPDFService().Optimize(path, out, pdf.OptimizeOptions{
+		Info:          map[string]string{"Title": "Optimized", "Producer": "pdfmod"},
+		ObjectStreams: true,
+	})
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	if report.SizeBefore != int64(len(bloatedPDF())) || report.SizeAfter >= report.SizeBefore {
+		t.Errorf("Unexpected sizes %d -> %d", report.SizeBefore, report.SizeAfter)
+	}
+
+	info, err := pdf.NewPDFService().ReadInfo(out)
+	if err != nil {
+		t.Fatalf("ReadInfo failed: %v", err)
+	}
+	if info["Title"] != "Optimized" || info["Producer"] != "pdfmod" {
+		t.Errorf("Expected metadata to be updated, got %v", info)
+	}
+}
+
+func TestPDFService_Optimize_NotSmaller(t *testing.T) {
+	// A small file without anything to remove grows when it is rewritten
+	// with object streams, so the original is kept.
+	data := pdftest.Build(samplePages(1, "")...)
+	path := writeTempPDF(t, data)
+	out := path + ".optimized.pdf"
+	defer os.Remove(out)
+
+	for _, outPath := range []string{"", out} {
+		report, err := pdf.NewPDFService().Optimize(path, outPath, pdf.OptimizeOptions{ObjectStreams: true})
+		if err != nil {
+			t.Fatalf("Optimize failed: %v", err)
+		}
+		if !report.Unchanged || report.SizeAfter != report.SizeBefore {
+			t.Errorf("Expected the file to be unchanged, got %+v", report)
+		}
+		written := []string{path}
+		if outPath != "" {
+			written = append(written, outPath)
+		}
+		for _, p := range written {
+			if got, err := os.ReadFile(p); err != nil || !bytes.Equal(got, data) {
+				t.Errorf("Expected %s to hold the original file", p)
+			}
+		}
+	}
+}
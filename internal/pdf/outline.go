@@ -0,0 +1,270 @@
+package pdf
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OutlineItem is a bookmark in the document outline.
+type OutlineItem struct {
+	Title string `json:"title"`
+	// Page is the 1-based target page, or 0 when the destination could not be
+	// resolved to a page of this document.
+	Page     int            `json:"page"`
+	Children []*OutlineItem `json:"children,omitempty"`
+}
+
+// Outline reads the /Outlines tree of the document. It returns nil when the
+// document has no bookmarks.
+func (d *Document) Outline() ([]*OutlineItem, error) {
+	catalog, _, err := d.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	root := d.GetDict(catalog["Outlines"])
+	if root == nil {
+		return nil, nil
+	}
+	pageIndex, err := d.pageIndex()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[Ref]bool{}
+	return d.readOutlineItems(root["First"], catalog, pageIndex, seen), nil
+}
+
+func (d *Document) readOutlineItems(first Object, catalog Dict, pageIndex map[Ref]int, seen map[Ref]bool) []*OutlineItem {
+	var items []*OutlineItem
+	for node := first; node != nil; {
+		ref, ok := node.(Ref)
+		if !ok || seen[ref] {
+			break
+		}
+		seen[ref] = true
+		dict := d.GetDict(ref)
+		if dict == nil {
+			break
+		}
+		title, _ := d.GetString(dict["Title"])
+		item := &OutlineItem{
+			Title: DecodeTextString(title),
+			Page:  d.outlineTarget(dict, catalog, pageIndex),
+		}
+		item.Children = d.readOutlineItems(dict["First"], catalog, pageIndex, seen)
+		items = append(items, item)
+		node = dict["Next"]
+	}
+	return items
+}
+
+// outlineTarget returns the 1-based page an outline item points to, or 0.
+func (d *Document) outlineTarget(item, catalog Dict, pageIndex map[Ref]int) int {
+	dest := item["Dest"]
+	if dest == nil {
+		if action := d.GetDict(item["A"]); action != nil && d.GetName(action["S"]) == "GoTo" {
+			dest = action["D"]
+		}
+	}
+	return d.destinationPage(dest, catalog, pageIndex)
+}
+
+// destinationPage resolves an explicit or named destination to a 1-based
+// page number, or 0 if it does not point to a page of this document.
+func (d *Document) destinationPage(dest Object, catalog Dict, pageIndex map[Ref]int) int {
+	dest = d.resolveQuiet(dest)
+	switch v := dest.(type) {
+	case Name:
+		dest = d.GetDict(catalog["Dests"])[v]
+	case String:
+		names := d.GetDict(catalog["Names"])
+		dest, _ = d.lookupName(names["Dests"], v)
+	}
+	dest = d.resolveQuiet(dest)
+	if dict, ok := dest.(Dict); ok {
+		dest = d.resolveQuiet(dict["D"])
+	}
+	arr, ok := dest.(Array)
+	if !ok || len(arr) == 0 {
+		return 0
+	}
+	switch target := arr[0].(type) {
+	case Ref:
+		return pageIndex[target]
+	case int64:
+		// Remote destinations give a 0-based page number.
+		return int(target) + 1
+	}
+	return 0
+}
+
+// pageIndex maps page references to 1-based page numbers.
+func (d *Document) pageIndex() (map[Ref]int, error) {
+	pages, err := d.Pages()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[Ref]int, len(pages))
+	for i, ref := range pages {
+		index[ref] = i + 1
+	}
+	return index, nil
+}
+
+// SetOutline replaces the document outline with items. An empty list removes
+// the outline. The previous outline objects are left unreferenced.
+func (d *Document) SetOutline(items []*OutlineItem) error {
+	catalog, catalogRef, err := d.Catalog()
+	if err != nil {
+		return err
+	}
+	pages, err := d.Pages()
+	if err != nil {
+		return err
+	}
+	catalog = catalog.Clone()
+	if len(items) == 0 {
+		delete(catalog, "Outlines")
+		d.Set(catalogRef, catalog)
+		return nil
+	}
+
+	rootRef := d.Add(nil)
+	first, last, err := d.addOutlineItems(items, rootRef, pages)
+	if err != nil {
+		return err
+	}
+	d.Set(rootRef, Dict{
+		"Type":  Name("Outlines"),
+		"First": first,
+		"Last":  last,
+		"Count": int64(len(items)),
+	})
+	catalog["Outlines"] = rootRef
+	d.Set(catalogRef, catalog)
+	return nil
+}
+
+// addOutlineItems adds one level of outline items below parent. Items with
+// children are written closed.
+func (d *Document) addOutlineItems(items []*OutlineItem, parent Ref, pages []Ref) (first, last Ref, err error) {
+	refs := make([]Ref, len(items))
+	for i := range items {
+		refs[i] = d.Add(nil)
+	}
+	for i, item := range items {
+		dict := Dict{
+			"Title":  EncodeTextString(item.Title),
+			"Parent": parent,
+		}
+		if item.Page != 0 {
+			if item.Page < 1 || item.Page > len(pages) {
+				return first, last, fmt.Errorf("%w: bookmark %q points to page %d of %d", ErrPageOutOfRange, item.Title, item.Page, len(pages))
+			}
+			dict["Dest"] = Array{pages[item.Page-1], Name("Fit")}
+		}
+		if i > 0 {
+			dict["Prev"] = refs[i-1]
+		}
+		if i < len(items)-1 {
+			dict["Next"] = refs[i+1]
+		}
+		if len(item.Children) > 0 {
+			childFirst, childLast, err := d.addOutlineItems(item.Children, refs[i], pages)
+			if err != nil {
+				return first, last, err
+			}
+			dict["First"] = childFirst
+			dict["Last"] = childLast
+			dict["Count"] = int64(-len(item.Children))
+		}
+		d.Set(refs[i], dict)
+	}
+	return refs[0], refs[len(refs)-1], nil
+}
+
+// WriteOutlineJSON writes items as indented JSON.
+func WriteOutlineJSON(w io.Writer, items []*OutlineItem) error {
+	if items == nil {
+		items = []*OutlineItem{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+// WriteOutlineText writes items in the indented text format: one bookmark per
+// line, two spaces of indentation per level, the page number ("-" when
+// unresolved) and the title.
+func WriteOutlineText(w io.Writer, items []*OutlineItem) error {
+	bw := bufio.NewWriter(w)
+	var write func(items []*OutlineItem, depth int)
+	write = func(items []*OutlineItem, depth int) {
+		for _, item := range items {
+			page := "-"
+			if item.Page > 0 {
+				page = strconv.Itoa(item.Page)
+			}
+			fmt.Fprintf(bw, "%s%s %s\n", strings.Repeat("  ", depth), page, item.Title)
+			write(item.Children, depth+1)
+		}
+	}
+	write(items, 0)
+	return bw.Flush()
+}
+
+// ParseOutlineJSON reads an outline written by WriteOutlineJSON.
+func ParseOutlineJSON(r io.Reader) ([]*OutlineItem, error) {
+	var items []*OutlineItem
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("invalid outline JSON: %w", err)
+	}
+	return items, nil
+}
+
+// ParseOutlineText reads an outline written by WriteOutlineText. Blank lines
+// and lines starting with # are ignored.
+func ParseOutlineText(r io.Reader) ([]*OutlineItem, error) {
+	var roots []*OutlineItem
+	// stack[i] is the most recent item at depth i.
+	var stack []*OutlineItem
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := strings.Count(text[:len(text)-len(trimmed)], " ") + 2*strings.Count(text[:len(text)-len(trimmed)], "\t")
+		depth := indent / 2
+		if depth > len(stack) {
+			return nil, fmt.Errorf("line %d: indented more than one level below the previous bookmark", line)
+		}
+
+		pageField, title, _ := strings.Cut(trimmed, " ")
+		item := &OutlineItem{Title: strings.TrimSpace(title)}
+		if pageField != "-" {
+			page, err := strconv.Atoi(pageField)
+			if err != nil || page < 1 {
+				return nil, fmt.Errorf("line %d: invalid page number %q", line, pageField)
+			}
+			item.Page = page
+		}
+
+		stack = stack[:depth]
+		if depth == 0 {
+			roots = append(roots, item)
+		} else {
+			parent := stack[depth-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
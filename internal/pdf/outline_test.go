@@ -0,0 +1,136 @@
+package pdf_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// outlinePDF returns a three-page document with a nested outline using an
+// explicit destination, a GoTo action and a named destination.
+func outlinePDF() []byte {
+	objects := samplePages(3, "/Outlines 6 0 R /Names << /Dests 10 0 R >> ")
+	objects = append(objects,
+		"<< /Type /Outlines /First 7 0 R /Last 9 0 R /Count 2 >>",
+		"<< /Title (Introduction) /Parent 6 0 R /Next 9 0 R /First 8 0 R /Last 8 0 R /Count 1 /Dest [3 0 R /Fit] >>",
+		"<< /Title <FEFF00DC0062006500720073006900630068007400> /Parent 7 0 R /A << /S /GoTo /D [4 0 R /XYZ 0 792 0] >> >>",
+		"<< /Title (Appendix) /Parent 6 0 R /Prev 7 0 R /Dest (appendix) >>",
+		"<< /Names [(appendix) [5 0 R /Fit]] >>",
+	)
+	return pdftest.Build(objects...)
+}
+
+func TestDocument_Outline(t *testing.T) {
+	doc := openPDF(t, outlinePDF())
+	items, err := doc.Outline()
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	want := []*pdf.OutlineItem{
+		{Title: "Introduction", Page: 1, Children: []*pdf.OutlineItem{{Title: "Übersicht", Page: 2}}},
+		{Title: "Appendix", Page: 3},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("Unexpected outline:\n got %s\nwant %s", outlineText(t, items), outlineText(t, want))
+	}
+}
+
+func TestDocument_Outline_None(t *testing.T) {
+	doc := openPDF(t, pdftest.Build(samplePages(1, "")...))
+	items, err := doc.Outline()
+	if err != nil || items != nil {
+		t.Errorf("Expected no outline, got %v, %v", items, err)
+	}
+}
+
+func TestDocument_SetOutline(t *testing.T) {
+	doc := openPDF(t, outlinePDF())
+	want := []*pdf.OutlineItem{
+		{Title: "Cover", Page: 1},
+		{Title: "Body", Page: 2, Children: []*pdf.OutlineItem{
+			{Title: "Part A", Page: 2},
+			{Title: "Part B", Page: 3, Children: []*pdf.OutlineItem{{Title: "Detail", Page: 0}}},
+		}},
+	}
+	if err := doc.SetOutline(want); err != nil {
+		t.Fatalf("SetOutline failed: %v", err)
+	}
+
+	got, err := rewrite(t, doc).Outline()
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected outline:\n got %s\nwant %s", outlineText(t, got), outlineText(t, want))
+	}
+}
+
+func TestDocument_SetOutline_PageOutOfRange(t *testing.T) {
+	doc := openPDF(t, outlinePDF())
+	err := doc.SetOutline([]*pdf.OutlineItem{{Title: "Missing", Page: 4}})
+	if !errors.Is(err, pdf.ErrPageOutOfRange) {
+		t.Errorf("Expected ErrPageOutOfRange, got %v", err)
+	}
+}
+
+func TestOutlineText_RoundTrip(t *testing.T) {
+	text := "1 Introduction\n  2 Background\n    - Unlinked note\n3 Results: final\n"
+	items, err := pdf.ParseOutlineText(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ParseOutlineText failed: %v", err)
+	}
+	if got := outlineText(t, items); got != text {
+		t.Errorf("Expected %q, got %q", text, got)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.WriteOutlineJSON(&buf, items); err != nil {
+		t.Fatalf("WriteOutlineJSON failed: %v", err)
+	}
+	fromJSON, err := pdf.ParseOutlineJSON(&buf)
+	if err != nil {
+		t.Fatalf("ParseOutlineJSON failed: %v", err)
+	}
+	if !reflect.DeepEqual(fromJSON, items) {
+		t.Errorf("JSON round trip changed the outline: %s", outlineText(t, fromJSON))
+	}
+}
+
+func TestParseOutlineText_Errors(t *testing.T) {
+	for _, text := range []string{"x Title\n", "1 Top\n    3 Too deep\n"} {
+		if _, err := pdf.ParseOutlineText(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected error for %q", text)
+		}
+	}
+}
+
+func TestPDFService_WriteOutline(t *testing.T) {
+	path := writeTempPDF(t, outlinePDF())
+	service := pdf.NewPDFService()
+
+	items := []*pdf.OutlineItem{{Title: "Only", Page: 2}}
+	if err := service.WriteOutline(path, items); err != nil {
+		t.Fatalf("WriteOutline failed: %v", err)
+	}
+	got, err := service.ReadOutline(path)
+	if err != nil {
+		t.Fatalf("ReadOutline failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("Expected %v, got %v", items, got)
+	}
+}
+
+func outlineText(t *testing.T, items []*pdf.OutlineItem) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pdf.WriteOutlineText(&buf, items); err != nil {
+		t.Fatalf("WriteOutlineText failed: %v", err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,251 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Page label numbering styles, as used in the /S entry of a label range.
+const (
+	LabelDecimal      = "D"
+	LabelUpperRoman   = "R"
+	LabelLowerRoman   = "r"
+	LabelUpperLetters = "A"
+	LabelLowerLetters = "a"
+	// LabelNone labels pages with the prefix only.
+	LabelNone = ""
+)
+
+// PageLabelRange labels the pages from Page up to the start of the next
+// range.
+type PageLabelRange struct {
+	// Page is the 1-based first page of the range.
+	Page   int    `json:"page"`
+	Style  string `json:"style"`
+	Prefix string `json:"prefix,omitempty"`
+	// Start is the number of the first page in the range.
+	Start int `json:"start"`
+}
+
+// PageLabelRanges reads the /PageLabels number tree of the catalog. It
+// returns nil if the document has no page labels.
+func (d *Document) PageLabelRanges() ([]PageLabelRange, error) {
+	catalog, _, err := d.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	if catalog["PageLabels"] == nil {
+		return nil, nil
+	}
+	var ranges []PageLabelRange
+	d.walkNumberTree(catalog["PageLabels"], func(key int64, value Object) {
+		dict := d.GetDict(value)
+		r := PageLabelRange{Page: int(key) + 1, Style: string(d.GetName(dict["S"])), Start: 1}
+		if prefix, ok := d.GetString(dict["P"]); ok {
+			r.Prefix = DecodeTextString(prefix)
+		}
+		if start, ok := d.GetInt(dict["St"]); ok && start > 0 {
+			r.Start = int(start)
+		}
+		ranges = append(ranges, r)
+	})
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].Page < ranges[j].Page })
+	return ranges, nil
+}
+
+// SetPageLabelRanges replaces the page labels of the document. An empty list
+// removes them.
+func (d *Document) SetPageLabelRanges(ranges []PageLabelRange) error {
+	catalog, catalogRef, err := d.Catalog()
+	if err != nil {
+		return err
+	}
+	pages, err := d.Pages()
+	if err != nil {
+		return err
+	}
+	if err := validatePageLabelRanges(ranges, len(pages)); err != nil {
+		return err
+	}
+	catalog = catalog.Clone()
+	if len(ranges) == 0 {
+		delete(catalog, "PageLabels")
+		d.Set(catalogRef, catalog)
+		return nil
+	}
+
+	nums := Array{}
+	for _, r := range ranges {
+		label := Dict{}
+		if r.Style != LabelNone {
+			label["S"] = Name(r.Style)
+		}
+		if r.Prefix != "" {
+			label["P"] = EncodeTextString(r.Prefix)
+		}
+		if r.Start > 1 {
+			label["St"] = int64(r.Start)
+		}
+		nums = append(nums, int64(r.Page-1), label)
+	}
+	catalog["PageLabels"] = Dict{"Nums": nums}
+	d.Set(catalogRef, catalog)
+	return nil
+}
+
+func validatePageLabelRanges(ranges []PageLabelRange, numPages int) error {
+	for i, r := range ranges {
+		if r.Page < 1 || r.Page > numPages {
+			return fmt.Errorf("%w: label range starts at page %d of %d", ErrPageOutOfRange, r.Page, numPages)
+		}
+		if i == 0 && r.Page != 1 {
+			return fmt.Errorf("the first label range must start at page 1, not %d", r.Page)
+		}
+		if i > 0 && r.Page <= ranges[i-1].Page {
+			return fmt.Errorf("label ranges must be in increasing page order")
+		}
+		switch r.Style {
+		case LabelDecimal, LabelUpperRoman, LabelLowerRoman, LabelUpperLetters, LabelLowerLetters, LabelNone:
+		default:
+			return fmt.Errorf("unknown page label style %q", r.Style)
+		}
+		if r.Start < 1 {
+			return fmt.Errorf("page label numbering must start at 1 or higher, not %d", r.Start)
+		}
+	}
+	return nil
+}
+
+// PageLabels returns the label of every page of the document.
+func (d *Document) PageLabels() ([]string, error) {
+	ranges, err := d.PageLabelRanges()
+	if err != nil {
+		return nil, err
+	}
+	pages, err := d.Pages()
+	if err != nil {
+		return nil, err
+	}
+	return pageLabels(ranges, len(pages)), nil
+}
+
+// pageLabels returns the label of every page. Pages before the first range,
+// or all pages if there are no ranges, are labelled with their page number.
+func pageLabels(ranges []PageLabelRange, numPages int) []string {
+	labels := make([]string, numPages)
+	for page := 1; page <= numPages; page++ {
+		labels[page-1] = strconv.Itoa(page)
+	}
+	for i, r := range ranges {
+		end := numPages
+		if i+1 < len(ranges) {
+			end = min(numPages, ranges[i+1].Page-1)
+		}
+		for page := max(1, r.Page); page <= end; page++ {
+			labels[page-1] = r.Prefix + formatLabelNumber(r.Style, r.Start+page-r.Page)
+		}
+	}
+	return labels
+}
+
+func formatLabelNumber(style string, n int) string {
+	switch style {
+	case LabelDecimal:
+		return strconv.Itoa(n)
+	case LabelUpperRoman:
+		return strings.ToUpper(toRoman(n))
+	case LabelLowerRoman:
+		return toRoman(n)
+	case LabelUpperLetters:
+		return strings.ToUpper(toLetters(n))
+	case LabelLowerLetters:
+		return toLetters(n)
+	}
+	return ""
+}
+
+func toRoman(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"m", "cm", "d", "cd", "c", "xc", "l", "xl", "x", "ix", "v", "iv", "i"}
+	var b strings.Builder
+	for i, v := range values {
+		for n >= v {
+			b.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return b.String()
+}
+
+// toLetters numbers pages a..z, then aa..zz, aaa..zzz and so on.
+func toLetters(n int) string {
+	if n < 1 {
+		return ""
+	}
+	letter := byte('a' + (n-1)%26)
+	return strings.Repeat(string(letter), (n-1)/26+1)
+}
+
+// FormatPageLabelRanges formats ranges in the compact syntax accepted by
+// ParsePageLabelRanges, for example "1:r,5:D,21:D:A-".
+func FormatPageLabelRanges(ranges []PageLabelRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		style := r.Style
+		if style == LabelNone {
+			style = "-"
+		}
+		part := fmt.Sprintf("%d:%s", r.Page, style)
+		if r.Prefix != "" || r.Start > 1 {
+			part += ":" + r.Prefix
+		}
+		if r.Start > 1 {
+			part += ":" + strconv.Itoa(r.Start)
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParsePageLabelRanges parses the compact page label syntax: a comma
+// separated list of PAGE:STYLE[:PREFIX[:START]] ranges, where STYLE is one of
+// D (1, 2, 3), R (I, II), r (i, ii), A (A, B), a (a, b) or - (prefix only).
+// An empty string or "none" yields no ranges.
+func ParsePageLabelRanges(spec string) ([]PageLabelRange, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "none" {
+		return nil, nil
+	}
+	var ranges []PageLabelRange
+	for _, part := range strings.Split(spec, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, fmt.Errorf("invalid page label range %q: want PAGE:STYLE[:PREFIX[:START]]", part)
+		}
+		page, err := strconv.Atoi(fields[0])
+		if err != nil || page < 1 {
+			return nil, fmt.Errorf("invalid page number in page label range %q", part)
+		}
+		r := PageLabelRange{Page: page, Style: fields[1], Start: 1}
+		switch r.Style {
+		case "-":
+			r.Style = LabelNone
+		case LabelDecimal, LabelUpperRoman, LabelLowerRoman, LabelUpperLetters, LabelLowerLetters:
+		default:
+			return nil, fmt.Errorf("unknown page label style %q in %q", fields[1], part)
+		}
+		if len(fields) > 2 {
+			r.Prefix = fields[2]
+		}
+		if len(fields) > 3 {
+			r.Start, err = strconv.Atoi(fields[3])
+			if err != nil || r.Start < 1 {
+				return nil, fmt.Errorf("invalid start number in page label range %q", part)
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
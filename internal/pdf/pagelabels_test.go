@@ -0,0 +1,107 @@
+package pdf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+func TestDocument_PageLabels(t *testing.T) {
+	// Front matter in lower roman, body in decimal, appendix as A-1, A-2,
+	// split across two number tree leaves.
+	objects := samplePages(7, "/PageLabels 10 0 R ")
+	objects = append(objects,
+		"<< /Kids [11 0 R 12 0 R] >>",
+		"<< /Nums [0 << /S /r >> 2 << /S /D >>] /Limits [0 2] >>",
+		"<< /Nums [5 << /S /D /P (A-) >> 6 << /P (Back) >>] /Limits [5 6] >>",
+	)
+	doc := openPDF(t, pdftest.Build(objects...))
+
+	ranges, err := doc.PageLabelRanges()
+	if err != nil {
+		t.Fatalf("PageLabelRanges failed: %v", err)
+	}
+	want := []pdf.PageLabelRange{
+		{Page: 1, Style: "r", Start: 1},
+		{Page: 3, Style: "D", Start: 1},
+		{Page: 6, Style: "D", Prefix: "A-", Start: 1},
+		{Page: 7, Style: "", Prefix: "Back", Start: 1},
+	}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("Expected %+v, got %+v", want, ranges)
+	}
+
+	labels, err := doc.PageLabels()
+	if err != nil {
+		t.Fatalf("PageLabels failed: %v", err)
+	}
+	wantLabels := []string{"i", "ii", "1", "2", "3", "A-1", "Back"}
+	if !reflect.DeepEqual(labels, wantLabels) {
+		t.Errorf("Expected %v, got %v", wantLabels, labels)
+	}
+}
+
+func TestDocument_SetPageLabelRanges(t *testing.T) {
+	doc := openPDF(t, pdftest.Build(samplePages(30, "")...))
+	ranges, err := pdf.ParsePageLabelRanges("1:R,4:D:Page :10,28:a")
+	if err != nil {
+		t.Fatalf("ParsePageLabelRanges failed: %v", err)
+	}
+	if err := doc.SetPageLabelRanges(ranges); err != nil {
+		t.Fatalf("SetPageLabelRanges failed: %v", err)
+	}
+
+	labels, err := rewrite(t, doc).PageLabels()
+	if err != nil {
+		t.Fatalf("PageLabels failed: %v", err)
+	}
+	for page, want := range map[int]string{1: "I", 3: "III", 4: "Page 10", 27: "Page 33", 28: "a", 30: "c"} {
+		if labels[page-1] != want {
+			t.Errorf("Expected page %d to be labelled %q, got %q", page, want, labels[page-1])
+		}
+	}
+
+	if err := doc.SetPageLabelRanges([]pdf.PageLabelRange{{Page: 2, Style: "D", Start: 1}}); err == nil {
+		t.Error("Expected error for ranges not starting at page 1")
+	}
+}
+
+func TestParsePageLabelRanges(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []pdf.PageLabelRange
+		wantErr bool
+	}{
+		{spec: "none", want: nil},
+		{spec: "1:r,5:D", want: []pdf.PageLabelRange{{Page: 1, Style: "r", Start: 1}, {Page: 5, Style: "D", Start: 1}}},
+		{spec: "1:-:Cover", want: []pdf.PageLabelRange{{Page: 1, Style: "", Prefix: "Cover", Start: 1}}},
+		{spec: "1:D:A-:3", want: []pdf.PageLabelRange{{Page: 1, Style: "D", Prefix: "A-", Start: 3}}},
+		{spec: "1:x", wantErr: true},
+		{spec: "0:D", wantErr: true},
+		{spec: "1:D::0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := pdf.ParsePageLabelRanges(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if tt.want != nil {
+				if back, _ := pdf.ParsePageLabelRanges(pdf.FormatPageLabelRanges(got)); !reflect.DeepEqual(back, got) {
+					t.Errorf("Format/parse round trip gave %+v", back)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParsePageSelection parses a comma separated page selection such as
+// "1-3,7,10-" into 1-based page numbers, in the order given and without
+// duplicates. labels holds the label of every page, as returned by
+// Document.PageLabels, and its length is the page count.
+//
+// Items may be page labels ("iv", "A-1") or ranges between them ("i-iv").
+// When a token matches a page label it refers to that page, so in a document
+// whose front matter is numbered i, ii, ... the token "1" means the page
+// labelled 1. A leading # always means the physical page: "#1" is the first
+// page. An open range ("5-" or "-3") runs to the last or from the first page.
+func ParsePageSelection(spec string, labels []string) ([]int, error) {
+	numPages := len(labels)
+	byLabel := make(map[string]int, numPages)
+	for i := len(labels) - 1; i >= 0; i-- {
+		byLabel[labels[i]] = i + 1
+	}
+	resolve := func(tok string) (int, error) {
+		if strings.HasPrefix(tok, "#") {
+			n, err := strconv.Atoi(tok[1:])
+			if err != nil {
+				return 0, fmt.Errorf("invalid page %q", tok)
+			}
+			if n < 1 || n > numPages {
+				return 0, fmt.Errorf("%w: page %d of %d", ErrPageOutOfRange, n, numPages)
+			}
+			return n, nil
+		}
+		if page, ok := byLabel[tok]; ok {
+			return page, nil
+		}
+		n, err := strconv.Atoi(tok)
+		if err != nil {
+			return 0, fmt.Errorf("unknown page %q", tok)
+		}
+		if n < 1 || n > numPages {
+			return 0, fmt.Errorf("%w: page %d of %d", ErrPageOutOfRange, n, numPages)
+		}
+		return n, nil
+	}
+
+	var pages []int
+	seen := map[int]bool{}
+	add := func(from, to int) {
+		for p := from; p <= to; p++ {
+			if !seen[p] {
+				seen[p] = true
+				pages = append(pages, p)
+			}
+		}
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		page, err := resolve(item)
+		if err == nil {
+			add(page, page)
+			continue
+		}
+		if !strings.Contains(item, "-") {
+			return nil, err
+		}
+		from, to, err := resolvePageRange(item, numPages, resolve)
+		if err != nil {
+			return nil, err
+		}
+		add(from, to)
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("page selection %q selects no pages", spec)
+	}
+	return pages, nil
+}
+
+// resolvePageRange splits item at the first hyphen where both sides resolve
+// to pages. Labels may contain hyphens themselves, so every hyphen is tried.
+func resolvePageRange(item string, numPages int, resolve func(string) (int, error)) (int, int, error) {
+	lastErr := fmt.Errorf("unknown page %q", item)
+	for i := 0; i < len(item); i++ {
+		if item[i] != '-' {
+			continue
+		}
+		from, to := 1, numPages
+		var err error
+		if left := strings.TrimSpace(item[:i]); left != "" {
+			if from, err = resolve(left); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		if right := strings.TrimSpace(item[i+1:]); right != "" {
+			if to, err = resolve(right); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		if from > to {
+			return 0, 0, fmt.Errorf("page range %q runs backwards", item)
+		}
+		return from, to, nil
+	}
+	return 0, 0, lastErr
+}
+
+// inheritedPageAttr returns an attribute of a page, looking it up in the
+// ancestors of the page when the page does not define it. Resources,
+// MediaBox, CropBox and Rotate are inheritable.
+func (d *Document) inheritedPageAttr(page Ref, key Name) Object {
+	node := d.GetDict(page)
+	for depth := 0; node != nil && depth < 64; depth++ {
+		if v, ok := node[key]; ok {
+			return v
+		}
+		node = d.GetDict(node["Parent"])
+	}
+	return nil
+}
+
+// selectPages is ParsePageSelection where an empty selection means every page.
+func selectPages(selection string, labels []string) ([]int, error) {
+	if strings.TrimSpace(selection) == "" {
+		pages := make([]int, len(labels))
+		for i := range pages {
+			pages[i] = i + 1
+		}
+		return pages, nil
+	}
+	return ParsePageSelection(selection, labels)
+}
+
+// PageSize returns the width and height of the media box of a page, in
+// points, and its rotation in degrees. A page without a valid media box is
+// reported as US Letter.
+func (d *Document) PageSize(page Ref) (width, height float64, rotate int) {
+	width, height = 612, 792
+	if box := d.GetArray(d.inheritedPageAttr(page, "MediaBox")); len(box) == 4 {
+		var coords [4]float64
+		valid := true
+		for i, v := range box {
+			n, ok := d.GetNumber(v)
+			coords[i], valid = n, valid && ok
+		}
+		if valid {
+			width, height = math.Abs(coords[2]-coords[0]), math.Abs(coords[3]-coords[1])
+		}
+	}
+	if r, ok := d.GetInt(d.inheritedPageAttr(page, "Rotate")); ok {
+		rotate = int((r%360 + 360) % 360)
+	}
+	return width, height, rotate
+}
@@ -0,0 +1,49 @@
+package pdf_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+func TestParsePageSelection(t *testing.T) {
+	labels := []string{"i", "ii", "iii", "1", "2", "3", "A-1", "A-2"}
+	tests := []struct {
+		spec string
+		want []int
+	}{
+		{"#1-#3", []int{1, 2, 3}},
+		{"ii-1", []int{2, 3, 4}},
+		{"1", []int{4}},
+		{"#8", []int{8}},
+		{"A-1-A-2", []int{7, 8}},
+		{"A-1", []int{7}},
+		{"3-, i", []int{6, 7, 8, 1}},
+		{"-ii,i", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := pdf.ParsePageSelection(tt.spec, labels)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParsePageSelection_Errors(t *testing.T) {
+	labels := []string{"1", "2", "3"}
+	if _, err := pdf.ParsePageSelection("4", labels); !errors.Is(err, pdf.ErrPageOutOfRange) {
+		t.Errorf("Expected ErrPageOutOfRange, got %v", err)
+	}
+	for _, spec := range []string{"3-1", "x", ""} {
+		if _, err := pdf.ParsePageSelection(spec, labels); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
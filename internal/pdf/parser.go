@@ -0,0 +1,405 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// errTruncated is returned when the parser reaches the end of its buffer in
+// the middle of an object. Callers reading from a window of the file retry
+// with a larger window.
+var errTruncated = errors.New("unexpected end of data")
+
+// parser reads PDF objects from an in-memory buffer.
+type parser struct {
+	buf []byte
+	pos int
+	// partial is set when buf is a window into a larger file, so a token
+	// running into the end of the buffer may be incomplete.
+	partial bool
+}
+
+func newParser(buf []byte) *parser {
+	return &parser{buf: buf}
+}
+
+func isWhitespace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isRegular(c byte) bool {
+	return !isWhitespace(c) && !isDelimiter(c)
+}
+
+// skipSpace advances past whitespace and comments.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		if isWhitespace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for p.pos < len(p.buf) && p.buf[p.pos] != '\n' && p.buf[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// eof reports whether only whitespace and comments remain.
+func (p *parser) eof() bool {
+	p.skipSpace()
+	return p.pos >= len(p.buf)
+}
+
+// parseObject reads the next object. Bare words that are not true, false or
+// null are returned as keywords.
+func (p *parser) parseObject() (Object, error) {
+	p.skipSpace()
+	if p.pos >= len(p.buf) {
+		return nil, errTruncated
+	}
+	switch c := p.buf[p.pos]; {
+	case c == '/':
+		return p.parseName()
+	case c == '(':
+		return p.parseLiteralString()
+	case c == '<':
+		if p.pos+1 < len(p.buf) && p.buf[p.pos+1] == '<' {
+			return p.parseDict()
+		}
+		return p.parseHexString()
+	case c == '[':
+		return p.parseArray()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumberOrRef()
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		p.pos++
+		return keyword(c), nil
+	default:
+		return p.parseKeyword()
+	}
+}
+
+func (p *parser) parseKeyword() (Object, error) {
+	start := p.pos
+	for p.pos < len(p.buf) && isRegular(p.buf[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, fmt.Errorf("%w: unexpected byte %q at offset %d", ErrMalformed, p.buf[p.pos], p.pos)
+	}
+	if p.atWindowEnd() {
+		return nil, errTruncated
+	}
+	switch word := string(p.buf[start:p.pos]); word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return keyword(word), nil
+	}
+}
+
+func (p *parser) parseName() (Object, error) {
+	p.pos++ // skip '/'
+	var name []byte
+	for p.pos < len(p.buf) && isRegular(p.buf[p.pos]) {
+		c := p.buf[p.pos]
+		if c == '#' && p.pos+2 < len(p.buf) {
+			if v, err := strconv.ParseUint(string(p.buf[p.pos+1:p.pos+3]), 16, 8); err == nil {
+				name = append(name, byte(v))
+				p.pos += 3
+				continue
+			}
+		}
+		name = append(name, c)
+		p.pos++
+	}
+	if p.atWindowEnd() {
+		return nil, errTruncated
+	}
+	return Name(name), nil
+}
+
+func (p *parser) parseLiteralString() (Object, error) {
+	p.pos++ // skip '('
+	var out []byte
+	depth := 1
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return String(out), nil
+			}
+		case '\r':
+			// End-of-line markers inside strings are read as a single newline.
+			if p.pos < len(p.buf) && p.buf[p.pos] == '\n' {
+				p.pos++
+			}
+			c = '\n'
+		case '\\':
+			if p.pos >= len(p.buf) {
+				return nil, errTruncated
+			}
+			e := p.buf[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if p.pos < len(p.buf) && p.buf[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.buf) && p.buf[p.pos] >= '0' && p.buf[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.buf[p.pos]-'0')
+						p.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return nil, errTruncated
+}
+
+func (p *parser) parseHexString() (Object, error) {
+	p.pos++ // skip '<'
+	var out []byte
+	var hi byte
+	half := false
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		p.pos++
+		if c == '>' {
+			if half {
+				out = append(out, hi<<4)
+			}
+			return String(out), nil
+		}
+		if isWhitespace(c) {
+			continue
+		}
+		v, ok := unhex(c)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid hex digit %q in string", ErrMalformed, c)
+		}
+		if half {
+			out = append(out, hi<<4|v)
+		} else {
+			hi = v
+		}
+		half = !half
+	}
+	return nil, errTruncated
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func (p *parser) parseArray() (Object, error) {
+	p.pos++ // skip '['
+	arr := Array{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.buf) {
+			return nil, errTruncated
+		}
+		if p.buf[p.pos] == ']' {
+			p.pos++
+			return arr, nil
+		}
+		obj, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (p *parser) parseDict() (Object, error) {
+	p.pos += 2 // skip '<<'
+	dict := Dict{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.buf) {
+			return nil, errTruncated
+		}
+		if bytes.HasPrefix(p.buf[p.pos:], []byte(">>")) {
+			p.pos += 2
+			return dict, nil
+		}
+		key, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: dictionary key %v is not a name", ErrMalformed, key)
+		}
+		value, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		if kw, ok := value.(keyword); ok {
+			return nil, fmt.Errorf("%w: unexpected %q as value of /%s", ErrMalformed, kw, name)
+		}
+		dict[name] = value
+	}
+}
+
+// parseNumberOrRef parses a number, or an indirect reference when the number
+// is followed by a generation number and R.
+func (p *parser) parseNumberOrRef() (Object, error) {
+	num, err := p.parseNumber()
+	if err != nil {
+		return nil, err
+	}
+	n, ok := num.(int64)
+	if !ok || n < 0 {
+		return num, nil
+	}
+
+	save := p.pos
+	p.skipSpace()
+	if p.atWindowEnd() {
+		return nil, errTruncated
+	}
+	if p.pos < len(p.buf) && p.buf[p.pos] >= '0' && p.buf[p.pos] <= '9' {
+		genObj, err := p.parseNumber()
+		if err == errTruncated {
+			return nil, err
+		}
+		if gen, ok := genObj.(int64); err == nil && ok {
+			p.skipSpace()
+			if p.atWindowEnd() {
+				return nil, errTruncated
+			}
+			if p.pos < len(p.buf) && p.buf[p.pos] == 'R' && (p.pos+1 >= len(p.buf) || !isRegular(p.buf[p.pos+1])) {
+				p.pos++
+				return Ref{Num: int(n), Gen: int(gen)}, nil
+			}
+		}
+	}
+	p.pos = save
+	return num, nil
+}
+
+func (p *parser) parseNumber() (Object, error) {
+	start := p.pos
+	if p.pos < len(p.buf) && (p.buf[p.pos] == '+' || p.buf[p.pos] == '-') {
+		p.pos++
+	}
+	real := false
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		if c == '.' {
+			real = true
+		} else if c < '0' || c > '9' {
+			break
+		}
+		p.pos++
+	}
+	if p.atWindowEnd() {
+		return nil, errTruncated
+	}
+	text := string(p.buf[start:p.pos])
+	if !real {
+		if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return v, nil
+		}
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		if text == "+" || text == "-" || text == "." || text == "-." {
+			return 0.0, nil
+		}
+		return nil, fmt.Errorf("%w: invalid number %q", ErrMalformed, text)
+	}
+	return v, nil
+}
+
+// atWindowEnd reports whether a partial buffer has been consumed completely.
+func (p *parser) atWindowEnd() bool {
+	return p.partial && p.pos >= len(p.buf)
+}
+
+// expectKeyword parses the next object and checks that it is the given keyword.
+func (p *parser) expectKeyword(want string) error {
+	obj, err := p.parseObject()
+	if err != nil {
+		return err
+	}
+	if kw, ok := obj.(keyword); !ok || string(kw) != want {
+		return fmt.Errorf("%w: expected %q, got %v", ErrMalformed, want, obj)
+	}
+	return nil
+}
+
+// parseInt parses the next object and checks that it is an integer.
+func (p *parser) parseInt() (int64, error) {
+	p.skipSpace()
+	if p.pos >= len(p.buf) {
+		return 0, errTruncated
+	}
+	obj, err := p.parseNumber()
+	if err != nil {
+		return 0, err
+	}
+	n, ok := obj.(int64)
+	if !ok {
+		return 0, fmt.Errorf("%w: expected integer, got %v", ErrMalformed, obj)
+	}
+	return n, nil
+}
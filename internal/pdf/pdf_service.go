@@ -1,101 +1,607 @@
 package pdf
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"time"
+	"path/filepath"
+	"strings"
 )
 
-const maxRetries = 10 // Set a limit on the number of retries
-
 // PDFService is a service to update PDF metadata.
-type PDFService struct{}
+type PDFService struct {
+	// Logger receives the progress of metadata updates. Metadata values are
+	// only logged at debug level.
+	Logger *slog.Logger
+	// ReadFields and WriteFields, when set, read and write the metadata of
+	// ReadMetadata and WriteMetadata, and so of UpdateMetadata, instead of
+	// this package.
+	ReadFields  func(filePath string) (map[string]string, error)
+	WriteFields func(filePath string, fields map[string]string) error
+}
 
-// NewPDFService creates a new PDFService instance.
+// NewPDFService creates a new PDFService instance logging to the default
+// logger.
 func NewPDFService() *PDFService {
-	return &PDFService{}
+	return &PDFService{Logger: slog.Default()}
+}
+
+// logger returns the Logger, or the default logger when there is none.
+func (s *PDFService) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
+	}
+	return s.Logger
 }
 
 var _ PDFMetadataHandler = &PDFService{}
 
-// UpdatePDFMetadata updates the title and producer name in the PDF metadata, with retry on failure.
-func (s *PDFService) UpdateMetadata(filePath, title, name string) error {
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		log.Printf("Attempt %d to update PDF metadata", attempt)
+// UpdateMetadata updates the PDF metadata with fields, keyed as ReadMetadata
+// returns them, in a single write and verifies the written file with
+// VerifyUpdate.
+func (s *PDFService) UpdateMetadata(filePath string, fields map[string]string) error {
+	logger := s.logger().With("file", filepath.Base(filePath))
+	// The values are only logged at debug level, as titles may be
+	// confidential.
+	logger.Debug("updating metadata", "fields", fields)
+
+	before, err := s.Validate(filePath)
+	if err != nil {
+		return err
+	}
+	if err := s.WriteMetadata(filePath, fields); err != nil {
+		return err
+	}
+	if err := s.VerifyUpdate(filePath, before, fields); err != nil {
+		return err
+	}
+	logger.Debug("metadata updated")
+	return nil
+}
+
+// VerifyUpdate checks the PDF file after fields were written to it: they
+// must read back as written, keyed as ReadMetadata returns them, and the
+// write must not have introduced structural errors. before is the report of
+// Validate on the file before it was written, so that errors the file
+// already had, which a rewrite cannot fix, are left to validate to report.
+// Errors are compared by check, since a rewrite may renumber objects.
+func (s *PDFService) VerifyUpdate(filePath string, before *ValidationReport, fields map[string]string) error {
+	after, err := s.Validate(filePath)
+	if err != nil {
+		return fmt.Errorf("could not verify the updated PDF file: %w", err)
+	}
+	known := map[string]int{}
+	for _, f := range before.Findings {
+		if f.Severity == SeverityError {
+			known[f.Check]++
+		}
+	}
+	for _, f := range after.Findings {
+		if f.Severity != SeverityError {
+			continue
+		}
+		if known[f.Check] == 0 {
+			return fmt.Errorf("updated PDF file is damaged: %s", f)
+		}
+		known[f.Check]--
+	}
+
+	written, err := s.ReadMetadata(filePath)
+	if err != nil {
+		return fmt.Errorf("could not verify the updated PDF file: %w", err)
+	}
+	for key, value := range fields {
+		if written[key] != value {
+			return fmt.Errorf("updated PDF file has %s %q instead of %q", key, written[key], value)
+		}
+	}
+	return nil
+}
 
-		// Read the original PDF file.
-		pdfData, err := os.ReadFile(filePath)
+var _ OutlineHandler = &PDFService{}
+
+// ReadOutline returns the bookmarks of the PDF file.
+func (s *PDFService) ReadOutline(filePath string) ([]*OutlineItem, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	return doc.Outline()
+}
+
+// WriteOutline replaces the bookmarks of the PDF file with items.
+func (s *PDFService) WriteOutline(filePath string, items []*OutlineItem) error {
+	doc, err := Open(filePath)
+	if err != nil {
+		return fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	if err := doc.SetOutline(items); err != nil {
+		return fmt.Errorf("could not set bookmarks: %w", err)
+	}
+	if err := doc.WriteFile(filePath); err != nil {
+		return fmt.Errorf("could not write updated PDF file: %w", err)
+	}
+	return nil
+}
+
+var _ PageLabelHandler = &PDFService{}
+
+// ReadPageLabels returns the page label ranges of the PDF file and the
+// resulting label of every page.
+func (s *PDFService) ReadPageLabels(filePath string) ([]PageLabelRange, []string, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	ranges, err := doc.PageLabelRanges()
+	if err != nil {
+		return nil, nil, err
+	}
+	labels, err := doc.PageLabels()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ranges, labels, nil
+}
+
+// WritePageLabels replaces the page labels of the PDF file with ranges.
+func (s *PDFService) WritePageLabels(filePath string, ranges []PageLabelRange) error {
+	doc, err := Open(filePath)
+	if err != nil {
+		return fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	if err := doc.SetPageLabelRanges(ranges); err != nil {
+		return fmt.Errorf("could not set page labels: %w", err)
+	}
+	if err := doc.WriteFile(filePath); err != nil {
+		return fmt.Errorf("could not write updated PDF file: %w", err)
+	}
+	return nil
+}
+
+var _ TextExtractor = &PDFService{}
+
+// ExtractText returns the text of the selected pages of the PDF file. An
+// empty selection means every page.
+func (s *PDFService) ExtractText(filePath, selection string) ([]PageText, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	return doc.ExtractText(selection)
+}
+
+var _ InfoHandler = &PDFService{}
+
+// ReadInfo returns the text entries of the PDF file's Info dictionary.
+func (s *PDFService) ReadInfo(filePath string) (map[string]string, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	return doc.Info()
+}
+
+// WriteInfo updates entries of the PDF file's Info dictionary. An empty
+// value removes the entry. Files that claim PDF/A conformance get the same
+// changes in their XMP metadata and a new ModDate, so they stay conformant.
+func (s *PDFService) WriteInfo(filePath string, fields map[string]string) error {
+	doc, err := Open(filePath)
+	if err != nil {
+		return fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	if err := setInfo(doc, fields); err != nil {
+		return fmt.Errorf("could not update metadata: %w", err)
+	}
+	if err := doc.WriteFile(filePath); err != nil {
+		return fmt.Errorf("could not write updated PDF file: %w", err)
+	}
+	return nil
+}
+
+// ReadMetadata returns the Info entries of the PDF file under their keys,
+// such as "Title", together with its XMP properties under their XMP names,
+// such as "dc:title".
+func (s *PDFService) ReadMetadata(filePath string) (map[string]string, error) {
+	if s.ReadFields != nil {
+		return s.ReadFields(filePath)
+	}
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	return doc.Fields()
+}
+
+// WriteMetadata updates the PDF file with fields keyed as ReadMetadata
+// returns them: names containing a colon are XMP properties and the others
+// Info entries. An empty value removes the entry.
+func (s *PDFService) WriteMetadata(filePath string, fields map[string]string) error {
+	if s.WriteFields != nil {
+		return s.WriteFields(filePath, fields)
+	}
+	doc, err := Open(filePath)
+	if err != nil {
+		return fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	if err := doc.SetFields(fields); err != nil {
+		return err
+	}
+	if err := doc.WriteFile(filePath); err != nil {
+		return fmt.Errorf("could not write updated PDF file: %w", err)
+	}
+	return nil
+}
+
+var _ ContentReader = &PDFService{}
+
+// ReadPageTextSpans returns the positioned text of a page of the PDF file.
+func (s *PDFService) ReadPageTextSpans(filePath string, page int) ([]TextSpan, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	return doc.PageTextSpans(page)
+}
+
+var _ ImageHandler = &PDFService{}
+
+// ListImages returns the images used by the selected pages of the PDF file.
+// An empty selection means every page.
+func (s *PDFService) ListImages(filePath, selection string) ([]ImageInfo, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	return doc.Images(selection)
+}
+
+// ExtractImages writes the images used by the selected pages of the PDF
+// file into outDir, named after the file, page and object number. Images
+// used on several pages are written once. Images that cannot be converted
+// are reported with an error instead of a path.
+func (s *PDFService) ExtractImages(filePath, selection, outDir string) ([]ExtractedImage, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+
+	images, err := doc.Images(selection)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	written := map[int]bool{}
+	var extracted []ExtractedImage
+	for _, img := range images {
+		if written[img.Object] {
+			continue
+		}
+		written[img.Object] = true
+		result := ExtractedImage{ImageInfo: img}
+		data, ext, err := doc.ExtractImage(img.Object)
 		if err != nil {
-			return fmt.Errorf("could not read PDF file: %w", err)
+			result.Error = err.Error()
+			extracted = append(extracted, result)
+			continue
+		}
+		result.Path = filepath.Join(outDir, fmt.Sprintf("%s-p%d-%d%s", base, img.Page, img.Object, ext))
+		if err := os.WriteFile(result.Path, data, 0o644); err != nil {
+			return extracted, fmt.Errorf("could not write image: %w", err)
 		}
+		extracted = append(extracted, result)
+	}
+	return extracted, nil
+}
+
+var _ Optimizer = &PDFService{}
 
-		// Replace the /Title and /Producer fields with the new title and name.
-		pdfData = s.replaceMetadataField(pdfData, "Title", title)
-		pdfData = s.replaceMetadataField(pdfData, "Producer", name)
+// Optimize rewrites the PDF file to outPath, or in place when outPath is
+// empty, without unused objects and duplicate streams and with better
+// compressed streams, and with images re-encoded if opts.Images is set.
+// Info entries in opts are updated in the same write. Without Info entries,
+// a rewrite that is not smaller is discarded: the original file is kept,
+// or copied to outPath, and the report is marked Unchanged.
+func (s *PDFService) Optimize(filePath, outPath string, opts OptimizeOptions) (*OptimizeReport, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
 
-		// Write the updated data back to the PDF file.
-		err = os.WriteFile(filePath, pdfData, 0644)
+	if len(opts.Info) > 0 {
+		if err := setInfo(doc, opts.Info); err != nil {
+			return nil, err
+		}
+	}
+	var pages []PageSavings
+	if opts.Images != nil {
+		if pages, err = doc.CompressImages(*opts.Images); err != nil {
+			return nil, err
+		}
+	}
+	stats, err := doc.Optimize()
+	if err != nil {
+		return nil, err
+	}
+	if outPath == "" {
+		outPath = filePath
+	}
+	err = replaceFile(outPath, func(f *os.File) error {
+		if err := doc.WriteWithOptions(f, WriteOptions{ObjectStreams: opts.ObjectStreams}); err != nil {
+			return err
+		}
+		written, err := f.Stat()
 		if err != nil {
-			return fmt.Errorf("could not write updated PDF file: %w", err)
+			return err
 		}
+		if written.Size() >= info.Size() && len(opts.Info) == 0 {
+			return errNotSmaller
+		}
+		return nil
+	})
+	if errors.Is(err, errNotSmaller) {
+		return s.keepOriginal(filePath, outPath, info.Size(), stats)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not write updated PDF file: %w", err)
+	}
+	written, err := os.Stat(outPath)
+	if err != nil {
+		return nil, err
+	}
+	return &OptimizeReport{OptimizeStats: *stats, SizeBefore: info.Size(), SizeAfter: written.Size(), Pages: pages}, nil
+}
+
+// errNotSmaller stops writing an optimized file that is not smaller than
+// the original.
+var errNotSmaller = errors.New("optimized file is not smaller")
 
-		// Verify the update by reading back the file and checking for the updated metadata
-		if s.verifyUpdate(filePath, title, name) {
-			log.Println("PDF metadata updated successfully")
-			return nil
+// keepOriginal reports an optimization that did not make the file smaller,
+// copying the original file to outPath unless it is the file itself.
+func (s *PDFService) keepOriginal(filePath, outPath string, size int64, stats *OptimizeStats) (*OptimizeReport, error) {
+	if outPath != filePath {
+		err := replaceFile(outPath, func(f *os.File) error {
+			in, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+			_, err = io.Copy(f, in)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not write PDF file: %w", err)
 		}
+	}
+	s.logger().Debug("kept the original file, as optimizing did not make it smaller", "file", filepath.Base(filePath))
+	return &OptimizeReport{OptimizeStats: *stats, SizeBefore: size, SizeAfter: size, Unchanged: true}, nil
+}
+
+var _ Validator = &PDFService{}
+
+// Validate checks the structure of the PDF file. The error is only set when
+// the file cannot be read; problems with its contents are findings.
+func (s *PDFService) Validate(filePath string) (*ValidationReport, error) {
+	return validateFile(filePath, Validate)
+}
+
+// ValidatePDFA checks the structure of the PDF file and its conformance to
+// the PDF/A-2b requirements on metadata, encryption, fonts and output
+// intents.
+func (s *PDFService) ValidatePDFA(filePath string) (*ValidationReport, error) {
+	return validateFile(filePath, ValidatePDFA)
+}
+
+func validateFile(filePath string, validate func(io.ReaderAt, int64) *ValidationReport) (*ValidationReport, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
 
-		log.Printf("Verification failed for attempt %d, retrying...", attempt)
-		time.Sleep(1 * time.Second) // Optional delay between retries
+	report := validate(f, info.Size())
+	report.File = filePath
+	return report, nil
+}
+
+var _ Repairer = &PDFService{}
+
+// Repair rebuilds the cross-reference data of the PDF file by scanning its
+// objects and writes a clean copy to outPath, or over the file when outPath
+// is empty.
+func (s *PDFService) Repair(filePath, outPath string) (*RepairReport, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+
+	doc, report, err := Repair(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("could not repair PDF file: %w", err)
+	}
+	if outPath == "" {
+		outPath = filePath
+	}
+	if err := doc.WriteFile(outPath); err != nil {
+		return nil, fmt.Errorf("could not write repaired PDF file: %w", err)
+	}
+	return report, nil
+}
+
+var _ PageAssembler = &PDFService{}
+
+// Merge writes the pages of the PDF files, in order, to outPath. The Info
+// dictionary is taken from the first file.
+func (s *PDFService) Merge(filePaths []string, outPath string) error {
+	if len(filePaths) == 0 {
+		return errors.New("no files to merge")
+	}
+	docs := make([]*Document, 0, len(filePaths))
+	defer func() {
+		for _, doc := range docs {
+			doc.Close()
+		}
+	}()
+	for _, path := range filePaths {
+		doc, err := Open(path)
+		if err != nil {
+			return fmt.Errorf("could not open PDF file %s: %w", path, err)
+		}
+		docs = append(docs, doc)
 	}
 
-	return fmt.Errorf("failed to update PDF metadata after %d attempts", maxRetries)
+	merged, err := Merge(docs...)
+	if err != nil {
+		return fmt.Errorf("could not merge PDF files: %w", err)
+	}
+	if err := merged.WriteFile(outPath); err != nil {
+		return fmt.Errorf("could not write merged PDF file: %w", err)
+	}
+	return nil
 }
 
-// replaceMetadataField replaces the metadata field in the PDF file data.
-func (s *PDFService) replaceMetadataField(pdfData []byte, fieldName, newValue string) []byte {
-	fieldPrefix := fmt.Sprintf("/%s (", fieldName)
-	start := bytes.Index(pdfData, []byte(fieldPrefix))
-	if start == -1 {
-		log.Printf("Field %s not found in PDF. Skipping replacement.", fieldName)
-		// Field not found; return original data unchanged.
-		return pdfData
+// Split writes parts of the PDF file into outDir, or the directory of the
+// file when outDir is empty, and returns their paths. Each selection, as
+// accepted by ParsePageSelection, becomes one part; without selections
+// every page becomes a part. Parts are named after the file with their
+// number appended, such as report-2.pdf.
+func (s *PDFService) Split(filePath, outDir string, selections []string) ([]string, error) {
+	doc, err := Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
 	}
-	start += len(fieldPrefix)
+	defer doc.Close()
 
-	// Find the end of the current field value (marked by ')')
-	end := bytes.Index(pdfData[start:], []byte(")"))
-	if end == -1 {
-		log.Printf("End of field %s value not found. Skipping replacement.", fieldName)
-		return pdfData
+	labels, err := doc.PageLabels()
+	if err != nil {
+		return nil, err
+	}
+	var parts [][]int
+	for _, selection := range selections {
+		pages, err := ParsePageSelection(selection, labels)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, pages)
+	}
+	if len(selections) == 0 {
+		for page := 1; page <= len(labels); page++ {
+			parts = append(parts, []int{page})
+		}
 	}
-	end += start
 
-	// Replace the field value with the new value
-	log.Printf("Replacing %s field value with: %s", fieldName, newValue)
-	return bytes.Replace(pdfData, pdfData[start:end], []byte(newValue), 1)
+	if outDir == "" {
+		outDir = filepath.Dir(filePath)
+	}
+	base := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	paths := make([]string, 0, len(parts))
+	for i, pages := range parts {
+		part, err := doc.ExtractPages(pages)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(outDir, fmt.Sprintf("%s-%d.pdf", base, i+1))
+		if err := part.WriteFile(path); err != nil {
+			return paths, fmt.Errorf("could not write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
 }
 
-// verifyUpdate reads the file and checks if the title and producer fields were updated correctly.
-func (s *PDFService) verifyUpdate(filePath, expectedTitle, expectedProducer string) bool {
-	// Read the file back to verify the update
-	pdfData, err := os.ReadFile(filePath)
+var _ ContentHasher = &PDFService{}
+
+// ContentHash returns the hash of the PDF file without its metadata, which
+// is the same for copies of a document that differ only in metadata or in
+// how the file is laid out.
+func (s *PDFService) ContentHash(filePath string) (string, error) {
+	doc, err := Open(filePath)
 	if err != nil {
-		log.Printf("Failed to read PDF file for verification: %v", err)
-		return false
+		return "", fmt.Errorf("could not open PDF file: %w", err)
 	}
+	defer doc.Close()
+
+	return doc.ContentHash()
+}
 
-	// Check if the updated metadata values are present
-	titleUpdated := bytes.Contains(pdfData, []byte(fmt.Sprintf("/Title (%s)", expectedTitle)))
-	producerUpdated := bytes.Contains(pdfData, []byte(fmt.Sprintf("/Producer (%s)", expectedProducer)))
+var _ Differ = &PDFService{}
 
-	if titleUpdated && producerUpdated {
-		log.Println("Verification succeeded: Title and Producer fields updated correctly.")
-		return true
+// DiffFiles returns the differences in metadata, pages, outline and
+// attachments from the PDF file at oldPath to the one at newPath.
+func (s *PDFService) DiffFiles(oldPath, newPath string) ([]Change, error) {
+	var summaries [2]*Summary
+	for i, path := range []string{oldPath, newPath} {
+		doc, err := Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not open PDF file: %w", err)
+		}
+		summaries[i], err = doc.Summary()
+		doc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", path, err)
+		}
 	}
+	return Diff(summaries[0], summaries[1]), nil
+}
 
-	log.Println("Verification failed: Title or Producer fields not updated correctly.")
-	return false
+// DiffRevisions returns the changes made by each incremental update of the
+// PDF file, oldest first. A file that was never updated has none.
+func (s *PDFService) DiffRevisions(filePath string) ([]RevisionDiff, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	revisions, err := Revisions(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	return DiffRevisions(revisions)
 }
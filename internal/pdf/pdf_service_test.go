@@ -2,10 +2,14 @@ package pdf_test
 
 import (
 	"bytes"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
 )
 
 func TestUpdateMetadata(t *testing.T) {
@@ -16,9 +20,9 @@ func TestUpdateMetadata(t *testing.T) {
 	}
 	defer os.Remove(tempFile.Name())
 
-	// Write some dummy PDF content to the temp file
-	dummyPDFContent := []byte("%PDF-1.4\n1 0 obj\n<< /Title (Old Title) /Producer (Old Producer) >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF")
-	if _, err := tempFile.Write(dummyPDFContent); err != nil {
+	// Write a minimal PDF with an Info dictionary to the temp file
+	pdfContent := pdftest.BuildWithTrailer("/Info 4 0 R ", append(samplePages(1, ""), "<< /Title (Old Title) /Producer (Old Producer) >>")...)
+	if _, err := tempFile.Write(pdfContent); err != nil {
 		t.Fatalf("Failed to write to temp file: %v", err)
 	}
 	tempFile.Close()
@@ -38,7 +42,7 @@ func TestUpdateMetadata(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.title, func(t *testing.T) {
 			// Call the UpdateMetadata method
-			err := service.UpdateMetadata(tempFile.Name(), tt.title, tt.producer)
+			err := service.UpdateMetadata(tempFile.Name(), map[string]string{"Title": tt.title, "Producer": tt.producer})
 			if err != nil {
 				t.Fatalf("UpdateMetadata failed: %v", err)
 			}
@@ -59,3 +63,85 @@ func TestUpdateMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateMetadata_LogsValuesOnlyAtDebug(t *testing.T) {
+	for _, level := range []slog.Level{slog.LevelInfo, slog.LevelDebug} {
+		t.Run(level.String(), func(t *testing.T) {
+			path := writeTempPDF(t, pdftest.BuildWithTrailer("/Info 4 0 R ", append(samplePages(1, ""), "<< /Title (Old) >>")...))
+			var logs bytes.Buffer
+			service := pdf.NewPDFService()
+			service.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: level}))
+
+			if err := service.UpdateMetadata(path, map[string]string{"Title": "Confidential Merger", "Producer": "Corp"}); err != nil {
+				t.Fatalf("UpdateMetadata failed: %v", err)
+			}
+			logged := strings.Contains(logs.String(), "Confidential Merger")
+			if want := level == slog.LevelDebug; logged != want {
+				t.Errorf("Expected the title logged: %v, got logs:\n%s", want, logs.String())
+			}
+		})
+	}
+}
+
+func TestUpdateMetadata_ExistingErrors(t *testing.T) {
+	// A page tree /Count that is wrong before the update is reported by
+	// validate, and does not fail the update.
+	objects := append(samplePages(2, ""), "<< /Title (Old) >>")
+	objects[1] = strings.Replace(objects[1], "/Count 2", "/Count 3", 1)
+	path := writeTempPDF(t, pdftest.BuildWithTrailer("/Info 5 0 R ", objects...))
+	service := pdf.NewPDFService()
+	if report, _ := service.Validate(path); report.Valid() {
+		t.Fatal("Expected the sample to have a validation error")
+	}
+
+	if err := service.UpdateMetadata(path, map[string]string{"Title": "New Title", "Producer": "New Producer"}); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+	info, err := service.ReadInfo(path)
+	if err != nil || info["Title"] != "New Title" || info["Producer"] != "New Producer" {
+		t.Errorf("Expected the new title and producer, got %v, %v", info, err)
+	}
+}
+
+func TestUpdateMetadata_Fields(t *testing.T) {
+	// Updates write and verify the metadata through ReadFields and
+	// WriteFields when they are set.
+	path := writeTempPDF(t, pdftest.BuildWithTrailer("/Info 4 0 R ", append(samplePages(1, ""), "<< /Title (Old) >>")...))
+	base := pdf.NewPDFService()
+	var calls []string
+	service := pdf.NewPDFService()
+	service.ReadFields = func(filePath string) (map[string]string, error) {
+		calls = append(calls, "read")
+		return base.ReadMetadata(filePath)
+	}
+	service.WriteFields = func(filePath string, fields map[string]string) error {
+		calls = append(calls, "write")
+		return base.WriteMetadata(filePath, fields)
+	}
+
+	if err := service.UpdateMetadata(path, map[string]string{"Title": "New Title"}); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+	if want := []string{"write", "read"}; !slices.Equal(calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+}
+
+func TestUpdateMetadata_InvalidFile(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "test.pdf")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	// Only matching bytes, without a cross-reference table, is not a PDF
+	// that can be updated.
+	if _, err := tempFile.WriteString("%PDF-1.4\n1 0 obj\n<< /Title (Old Title) >>\nendobj\n%%EOF"); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tempFile.Close()
+
+	if err := pdf.NewPDFService().UpdateMetadata(tempFile.Name(), map[string]string{"Title": "New Title", "Producer": "New Producer"}); err == nil {
+		t.Error("Expected an error for a file without cross-reference data")
+	}
+}
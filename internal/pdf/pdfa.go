@@ -0,0 +1,190 @@
+package pdf
+
+import "io"
+
+// Clauses of ISO 19005-2 (PDF/A-2) checked by ValidatePDFA.
+const (
+	RuleTrailer        = "6.1.3"
+	RuleOutputIntent   = "6.2.3"
+	RuleEmbeddedFonts  = "6.2.11.4.1"
+	RuleMetadataStream = "6.6.2.1"
+	RuleInfoEquivalent = "6.6.3"
+	RuleIdentification = "6.6.4"
+)
+
+// ValidatePDFA runs Validate and checks the PDF/A-2b requirements that
+// concern what pdfmod edits: the trailer ID and the absence of encryption,
+// the output intent, embedded fonts, the XMP metadata stream, the PDF/A
+// identification and the equivalence of the Info dictionary with XMP.
+// These findings are errors with Check CheckPDFA and the clause as Rule.
+func ValidatePDFA(r io.ReaderAt, size int64) *ValidationReport {
+	v := validate(r, size)
+	if v.d != nil {
+		v.checkPDFA()
+	}
+	return v.report
+}
+
+func (v *validator) addPDFA(rule string, object int, format string, args ...any) {
+	v.add(SeverityError, CheckPDFA, object, format, args...)
+	v.report.Findings[len(v.report.Findings)-1].Rule = rule
+}
+
+func (v *validator) checkPDFA() {
+	d := v.d
+	if d.Encrypted() {
+		// Nothing else can be checked without decrypting the streams.
+		v.addPDFA(RuleTrailer, 0, "document is encrypted")
+		return
+	}
+	if len(d.GetArray(d.trailer["ID"])) == 0 {
+		v.addPDFA(RuleTrailer, 0, "trailer has no /ID")
+	}
+	catalog, root, err := d.Catalog()
+	if err != nil {
+		return
+	}
+	v.checkOutputIntents(catalog, root)
+	v.checkEmbeddedFonts()
+	v.checkPDFAMetadata(catalog, root)
+}
+
+// checkOutputIntents requires a GTS_PDFA1 output intent with an ICC profile.
+// All such intents must share the same profile.
+func (v *validator) checkOutputIntents(catalog Dict, root Ref) {
+	d := v.d
+	var profile Object
+	found := false
+	for _, item := range d.GetArray(catalog["OutputIntents"]) {
+		intent := d.GetDict(item)
+		if d.GetName(intent["S"]) != "GTS_PDFA1" {
+			continue
+		}
+		found = true
+		ref, _ := item.(Ref)
+		dest := intent["DestOutputProfile"]
+		switch {
+		case d.GetStream(dest) == nil:
+			v.addPDFA(RuleOutputIntent, ref.Num, "output intent has no /DestOutputProfile")
+		case profile == nil:
+			profile = dest
+		case dest != profile:
+			v.addPDFA(RuleOutputIntent, ref.Num, "output intents use different profiles")
+		}
+	}
+	if !found {
+		v.addPDFA(RuleOutputIntent, root.Num, "catalog has no GTS_PDFA1 output intent")
+	}
+}
+
+// checkEmbeddedFonts requires the program of every font in the resources of
+// pages and form XObjects to be embedded. Type 3 fonts are exempt since
+// their glyphs are content streams.
+func (v *validator) checkEmbeddedFonts() {
+	d := v.d
+	pages, err := d.Pages()
+	if err != nil {
+		return
+	}
+	seenResources := map[Ref]bool{}
+	seenFonts := map[Ref]bool{}
+	var visit func(resources Object, depth int)
+	visit = func(resources Object, depth int) {
+		if ref, ok := resources.(Ref); ok {
+			if seenResources[ref] {
+				return
+			}
+			seenResources[ref] = true
+		}
+		dict := d.GetDict(resources)
+		for _, font := range d.GetDict(dict["Font"]) {
+			ref, isRef := font.(Ref)
+			if isRef {
+				if seenFonts[ref] {
+					continue
+				}
+				seenFonts[ref] = true
+			}
+			if !d.fontEmbedded(font) {
+				v.addPDFA(RuleEmbeddedFonts, ref.Num, "font %s is not embedded", d.GetName(d.GetDict(font)["BaseFont"]))
+			}
+		}
+		if depth >= maxFormDepth {
+			return
+		}
+		for _, xobject := range d.GetDict(dict["XObject"]) {
+			if s := d.GetStream(xobject); s != nil && d.GetName(s.Dict["Subtype"]) == "Form" {
+				visit(s.Dict["Resources"], depth+1)
+			}
+		}
+	}
+	for _, page := range pages {
+		visit(d.inheritedPageAttr(page, "Resources"), 0)
+	}
+}
+
+// fontEmbedded reports whether the font dictionary obj has an embedded
+// font program, looking at the descendant of composite fonts.
+func (d *Document) fontEmbedded(obj Object) bool {
+	font := d.GetDict(obj)
+	switch d.GetName(font["Subtype"]) {
+	case "Type3":
+		return true
+	case "Type0":
+		descendants := d.GetArray(font["DescendantFonts"])
+		if len(descendants) == 0 {
+			return false
+		}
+		font = d.GetDict(descendants[0])
+	}
+	descriptor := d.GetDict(font["FontDescriptor"])
+	for _, key := range []Name{"FontFile", "FontFile2", "FontFile3"} {
+		if d.GetStream(descriptor[key]) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPDFAMetadata requires an XMP metadata stream that identifies the
+// file as PDF/A-2 and agrees with every entry of the Info dictionary.
+func (v *validator) checkPDFAMetadata(catalog Dict, root Ref) {
+	d := v.d
+	metaRef, _ := catalog["Metadata"].(Ref)
+	if d.GetStream(catalog["Metadata"]) == nil {
+		v.addPDFA(RuleMetadataStream, root.Num, "catalog has no XMP metadata stream")
+		return
+	}
+	xmp, err := d.XMPMetadata()
+	if err != nil {
+		v.addPDFA(RuleMetadataStream, metaRef.Num, "cannot read XMP metadata: %v", err)
+		return
+	}
+
+	switch part, conformance := xmp["pdfaid:part"], xmp["pdfaid:conformance"]; {
+	case part == "":
+		v.addPDFA(RuleIdentification, metaRef.Num, "XMP has no pdfaid:part")
+	case part != "2":
+		v.addPDFA(RuleIdentification, metaRef.Num, "pdfaid:part is %q, expected 2", part)
+	case conformance != "A" && conformance != "B" && conformance != "U":
+		v.addPDFA(RuleIdentification, metaRef.Num, "pdfaid:conformance is %q, expected A, B or U", conformance)
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		return
+	}
+	for _, p := range infoXMPProperties {
+		infoValue, ok := info[p.info]
+		if !ok {
+			continue
+		}
+		xmpValue, ok := xmp[p.xmp]
+		switch {
+		case !ok:
+			v.addPDFA(RuleInfoEquivalent, metaRef.Num, "Info /%s has no equivalent %s in XMP", p.info, p.xmp)
+		case !sameMetadataValue(p.info, infoValue, xmpValue):
+			v.addPDFA(RuleInfoEquivalent, metaRef.Num, "Info /%s %q differs from XMP %s %q", p.info, infoValue, p.xmp, xmpValue)
+		}
+	}
+}
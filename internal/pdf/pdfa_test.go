@@ -0,0 +1,195 @@
+package pdf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+const pdfaXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/" pdfaid:part="2" pdfaid:conformance="B"/>
+<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" pdf:Producer="pdfmod">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Annual Report</rdf:li></rdf:Alt></dc:title>
+<dc:rights><rdf:Alt><rdf:li xml:lang="x-default">All rights reserved</rdf:li></rdf:Alt></dc:rights>
+</rdf:Description></rdf:RDF></x:xmpmeta>
+<?xpacket end="w"?>`
+
+// pdfaPDF returns a PDF/A-2b document whose page uses the font fontObject
+// and whose catalog has the given XMP metadata and output intents.
+func pdfaPDF(xmp, outputIntents, fontObject string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 4 0 R " + outputIntents + ">>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 6 0 R >> >> >>",
+		streamObject("/Type /Metadata /Subtype /XML", []byte(xmp)),
+		"<< /Title (Annual Report) /Producer (pdfmod) >>",
+		fontObject,
+		"<< /Type /FontDescriptor /FontName /Embedded /FontFile2 8 0 R >>",
+		streamObject("", []byte("glyphs")),
+		streamObject("/N 3", []byte("icc profile")),
+	}
+	return pdftest.BuildWithTrailer("/Info 5 0 R /ID [<0102> <0102>] ", objects...)
+}
+
+const (
+	embeddedFont = "<< /Type /Font /Subtype /TrueType /BaseFont /Embedded /FontDescriptor 7 0 R >>"
+	pdfaIntents  = "/OutputIntents [<< /Type /OutputIntent /S /GTS_PDFA1 /DestOutputProfile 9 0 R >>] "
+)
+
+func validatePDFA(data []byte) *pdf.ValidationReport {
+	return pdf.ValidatePDFA(bytes.NewReader(data), int64(len(data)))
+}
+
+func pdfaFindings(r *pdf.ValidationReport) []pdf.Finding {
+	var findings []pdf.Finding
+	for _, f := range r.Findings {
+		if f.Check == pdf.CheckPDFA {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func TestValidatePDFA_Conformant(t *testing.T) {
+	report := validatePDFA(pdfaPDF(pdfaXMP, pdfaIntents, embeddedFont))
+	if findings := pdfaFindings(report); len(findings) != 0 {
+		t.Errorf("Expected no PDF/A findings, got %v", findings)
+	}
+}
+
+func TestValidatePDFA_Findings(t *testing.T) {
+	xmp := strings.Replace(pdfaXMP, `pdfaid:part="2"`, `pdfaid:part="1"`, 1)
+	xmp = strings.Replace(xmp, "Annual Report", "Draft", 1)
+	data := pdfaPDF(xmp, "", "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	data = bytes.Replace(data, []byte("/ID [<0102> <0102>] "), nil, 1)
+	data = bytes.Replace(data, []byte("/Producer (pdfmod)"), []byte("/Author (Jo)      "), 1)
+
+	report := validatePDFA(data)
+	tests := []struct {
+		rule   string
+		object int
+		text   string
+	}{
+		{pdf.RuleTrailer, 0, "no /ID"},
+		{pdf.RuleOutputIntent, 1, "no GTS_PDFA1 output intent"},
+		{pdf.RuleEmbeddedFonts, 6, "font Helvetica is not embedded"},
+		{pdf.RuleIdentification, 4, `pdfaid:part is "1"`},
+		{pdf.RuleInfoEquivalent, 4, `Info /Title "Annual Report" differs from XMP dc:title "Draft"`},
+		{pdf.RuleInfoEquivalent, 4, "Info /Author has no equivalent dc:creator"},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, f := range pdfaFindings(report) {
+			if f.Rule == tt.rule && f.Object == tt.object && f.Severity == pdf.SeverityError && strings.Contains(f.Message, tt.text) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected finding %s object %d %q, got %v", tt.rule, tt.object, tt.text, report.Findings)
+		}
+	}
+	if n := len(pdfaFindings(report)); n != len(tests) {
+		t.Errorf("Expected %d PDF/A findings, got %d: %v", len(tests), n, report.Findings)
+	}
+}
+
+func TestValidatePDFA_MissingMetadata(t *testing.T) {
+	data := pdftest.BuildWithTrailer("/ID [<01> <01>] ", samplePages(1, pdfaIntents)...)
+	report := validatePDFA(data)
+	findings := pdfaFindings(report)
+	if len(findings) != 2 || findings[1].Rule != pdf.RuleMetadataStream {
+		t.Errorf("Expected a missing output profile and metadata stream, got %v", findings)
+	}
+	if got := findings[1].String(); got != "error [pdfa 6.6.2.1] object 1: catalog has no XMP metadata stream" {
+		t.Errorf("Unexpected finding text %q", got)
+	}
+}
+
+func TestDocument_SetMetadata(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("", 3600))
+	doc := openPDF(t, pdfaPDF(pdfaXMP, pdfaIntents, embeddedFont))
+	if err := doc.SetMetadata(map[string]string{"Title": "Q1 & <Q2>", "Author": "Jo"}, now); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if report := validatePDFA(buf.Bytes()); len(report.Findings) != 0 {
+		t.Errorf("Expected a conformant file, got %v", report.Findings)
+	}
+	doc = openPDF(t, buf.Bytes())
+	info, _ := doc.Info()
+	if info["ModDate"] != "D:20240301093000+01'00'" {
+		t.Errorf("Expected ModDate to be updated, got %q", info["ModDate"])
+	}
+	xmp, err := doc.XMPMetadata()
+	if err != nil {
+		t.Fatalf("XMPMetadata failed: %v", err)
+	}
+	want := map[string]string{
+		"dc:title":         "Q1 & <Q2>",
+		"dc:creator":       "Jo",
+		"xmp:ModifyDate":   "2024-03-01T09:30:00+01:00",
+		"xmp:MetadataDate": "2024-03-01T09:30:00+01:00",
+		"pdfaid:part":      "2",
+		"pdf:Producer":     "pdfmod",
+		"dc:rights":        "All rights reserved",
+	}
+	for key, value := range want {
+		if xmp[key] != value {
+			t.Errorf("Expected XMP %s %q, got %q", key, value, xmp[key])
+		}
+	}
+}
+
+func TestDocument_SetMetadata_CreatesXMP(t *testing.T) {
+	doc := openPDF(t, pdftest.Build(samplePages(1, "")...))
+	if err := doc.SetMetadata(map[string]string{"Title": "Fresh"}, time.Now()); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	xmp, err := rewrite(t, doc).XMPMetadata()
+	if err != nil || xmp["dc:title"] != "Fresh" {
+		t.Errorf("Expected a new XMP packet with the title, got %v (%v)", xmp, err)
+	}
+}
+
+func TestPDFService_WriteInfo_PDFA(t *testing.T) {
+	path := writeTempPDF(t, pdfaPDF(pdfaXMP, pdfaIntents, embeddedFont))
+	service := pdf.NewPDFService()
+	if err := service.WriteInfo(path, map[string]string{"Title": "Renamed"}); err != nil {
+		t.Fatalf("WriteInfo failed: %v", err)
+	}
+	report, err := service.ValidatePDFA(path)
+	if err != nil {
+		t.Fatalf("ValidatePDFA failed: %v", err)
+	}
+	if len(report.Findings) != 0 {
+		t.Errorf("Expected the file to stay conformant, got %v", report.Findings)
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		time time.Time
+		want string
+	}{
+		{time.Date(2024, 1, 31, 12, 0, 5, 0, time.UTC), "D:20240131120005Z"},
+		{time.Date(2024, 1, 31, 12, 0, 5, 0, time.FixedZone("", -(5*3600+30*60))), "D:20240131120005-05'30'"},
+	}
+	for _, tt := range tests {
+		got := pdf.FormatDate(tt.time)
+		if got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+		if parsed, ok := pdf.ParseDate(got); !ok || !parsed.Equal(tt.time) {
+			t.Errorf("Expected %q to parse back to %v, got %v", got, tt.time, parsed)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// objectMarker matches the "N G obj" that starts an indirect object.
+var objectMarker = regexp.MustCompile(`(\d{1,10})[\x00\t\n\f\r ]+(\d{1,5})[\x00\t\n\f\r ]+obj\b`)
+
+// infoKeys are the entries that identify a document information dictionary.
+var infoKeys = []Name{"Title", "Author", "Subject", "Keywords", "Creator", "Producer", "CreationDate", "ModDate"}
+
+// RepairReport describes what Repair recovered.
+type RepairReport struct {
+	// ObjectsRecovered counts the objects found by scanning, including
+	// those inside object streams.
+	ObjectsRecovered int `json:"objectsRecovered"`
+	// ObjectsDropped lists objects whose markers were found but that could
+	// not be parsed.
+	ObjectsDropped []int `json:"objectsDropped,omitempty"`
+	// LengthsFixed lists streams whose /Length did not match their data.
+	LengthsFixed []int `json:"lengthsFixed,omitempty"`
+	Catalog      int   `json:"catalog"`
+	// CatalogSource says how the catalog was found: "trailer", "scan" when
+	// an object with /Type /Catalog was used, or "rebuilt".
+	CatalogSource string `json:"catalogSource"`
+	Info          int    `json:"info,omitempty"`
+	// InfoSource is "trailer", "scan" or empty when there is no Info.
+	InfoSource string `json:"infoSource,omitempty"`
+}
+
+// Repair reconstructs a document whose cross-reference data is missing or
+// wrong by scanning r for "N G obj" markers. When an object occurs more
+// than once, as after incremental updates, the last readable copy wins.
+// The catalog and Info dictionary are taken from the last trailer that
+// points to usable objects, or found by their contents. Writing the
+// returned document produces a clean file with correct stream lengths.
+func Repair(r io.ReaderAt, size int64) (*Document, *RepairReport, error) {
+	d := newDocument(r, size)
+	if err := d.readHeader(); err != nil {
+		return nil, nil, err
+	}
+	data := make([]byte, size)
+	if n, err := r.ReadAt(data, 0); err != nil && !(err == io.EOF && int64(n) == size) {
+		return nil, nil, err
+	}
+
+	report := &RepairReport{}
+	var trailers []Dict
+	failed := map[int]bool{}
+	var skipUntil int64
+	for _, m := range objectMarker.FindAllSubmatchIndex(data, -1) {
+		off := int64(m[0])
+		if off < skipUntil || (off > 0 && isRegular(data[off-1]) && !isDelimiter(data[off-1])) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		gen, _ := strconv.Atoi(string(data[m[4]:m[5]]))
+		obj, ref, err := d.readObjectAt(off)
+		if err != nil || ref.Num != num {
+			failed[num] = true
+			continue
+		}
+		if s, ok := obj.(*Stream); ok {
+			// Markers inside the stream data are not objects of this file.
+			skipUntil = off + int64(len(s.Data))
+			if s.Dict["Type"] == Name("XRef") {
+				trailers = append(trailers, s.Dict)
+				continue
+			}
+		}
+		delete(failed, num)
+		d.xref[num] = xrefEntry{typ: xrefInUse, offset: off, gen: gen}
+		d.maxNum = max(d.maxNum, num)
+	}
+	for _, i := range bytesIndexAll(data, []byte("trailer")) {
+		p := newParser(data[i+len("trailer"):])
+		if obj, err := p.parseObject(); err == nil {
+			if dict, ok := obj.(Dict); ok {
+				trailers = append(trailers, dict)
+			}
+		}
+	}
+
+	d.recoverCompressedObjects()
+	report.ObjectsRecovered = len(d.xref)
+	for num := range failed {
+		if _, ok := d.xref[num]; !ok {
+			report.ObjectsDropped = append(report.ObjectsDropped, num)
+		}
+	}
+	sort.Ints(report.ObjectsDropped)
+	report.LengthsFixed = d.wrongStreamLengths()
+
+	d.trailer = Dict{}
+	for _, t := range trailers {
+		if _, ok := t["Encrypt"]; ok {
+			return nil, nil, ErrEncrypted
+		}
+		if root, ok := t["Root"].(Ref); ok && d.GetName(d.GetDict(root)["Type"]) == "Catalog" {
+			d.trailer["Root"] = root
+		}
+		if info, ok := t["Info"].(Ref); ok && d.GetDict(info) != nil {
+			d.trailer["Info"] = info
+		}
+		if id := d.GetArray(t["ID"]); len(id) == 2 {
+			d.trailer["ID"] = id
+		}
+	}
+	if err := d.recoverCatalog(report); err != nil {
+		return nil, nil, err
+	}
+	d.recoverInfo(report)
+	d.trailer["Size"] = int64(d.maxNum + 1)
+	return d, report, nil
+}
+
+func bytesIndexAll(data, sep []byte) []int {
+	var idx []int
+	for start := 0; ; {
+		i := bytes.Index(data[start:], sep)
+		if i < 0 {
+			return idx
+		}
+		idx = append(idx, start+i)
+		start += i + len(sep)
+	}
+}
+
+// recoverCompressedObjects adds the objects of every object stream found,
+// unless the object was also found uncompressed.
+func (d *Document) recoverCompressedObjects() {
+	var stmNums []int
+	for num, e := range d.xref {
+		if e.typ == xrefInUse {
+			stmNums = append(stmNums, num)
+		}
+	}
+	sort.Ints(stmNums)
+	for _, stmNum := range stmNums {
+		obj, err := d.Object(stmNum)
+		if s, ok := obj.(*Stream); err != nil || !ok || s.Dict["Type"] != Name("ObjStm") {
+			continue
+		}
+		stm, err := d.objectStream(stmNum)
+		if err != nil {
+			continue
+		}
+		p := newParser(stm.data)
+		for i := range stm.offsets {
+			num, err := p.parseInt()
+			if err != nil {
+				break
+			}
+			p.parseInt()
+			if _, ok := d.xref[int(num)]; ok {
+				continue
+			}
+			d.xref[int(num)] = xrefEntry{typ: xrefCompressed, offset: int64(stmNum), gen: i}
+			d.maxNum = max(d.maxNum, int(num))
+		}
+	}
+}
+
+// wrongStreamLengths returns the streams whose /Length does not match the
+// data found up to their endstream keyword.
+func (d *Document) wrongStreamLengths() []int {
+	var nums []int
+	for num, e := range d.xref {
+		if e.typ != xrefInUse {
+			continue
+		}
+		obj, err := d.Object(num)
+		s, ok := obj.(*Stream)
+		if err != nil || !ok {
+			continue
+		}
+		if length, ok := d.GetInt(s.Dict["Length"]); !ok || length != int64(len(s.Data)) {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// recoverCatalog makes sure the trailer points to a catalog, using the last
+// object with /Type /Catalog or, failing that, a new catalog for the root
+// of the page tree.
+func (d *Document) recoverCatalog(report *RepairReport) error {
+	if root, ok := d.trailer["Root"].(Ref); ok {
+		report.Catalog, report.CatalogSource = root.Num, "trailer"
+		return nil
+	}
+	var pagesRoot Ref
+	for num := d.maxNum; num > 0; num-- {
+		dict, ok := d.resolveQuiet(Ref{Num: num, Gen: d.generation(num)}).(Dict)
+		if !ok {
+			continue
+		}
+		switch d.GetName(dict["Type"]) {
+		case "Catalog":
+			ref := Ref{Num: num, Gen: d.generation(num)}
+			d.trailer["Root"] = ref
+			report.Catalog, report.CatalogSource = num, "scan"
+			return nil
+		case "Pages":
+			if _, hasParent := dict["Parent"]; !hasParent && pagesRoot.Num == 0 {
+				pagesRoot = Ref{Num: num, Gen: d.generation(num)}
+			}
+		}
+	}
+	if pagesRoot.Num == 0 {
+		return fmt.Errorf("%w: no catalog or page tree found", ErrMalformed)
+	}
+	ref := d.Add(Dict{"Type": Name("Catalog"), "Pages": pagesRoot})
+	d.trailer["Root"] = ref
+	report.Catalog, report.CatalogSource = ref.Num, "rebuilt"
+	return nil
+}
+
+// recoverInfo finds the document information dictionary when no trailer
+// pointed to one: the last dictionary without /Type holding Info entries.
+func (d *Document) recoverInfo(report *RepairReport) {
+	if info, ok := d.trailer["Info"].(Ref); ok {
+		report.Info, report.InfoSource = info.Num, "trailer"
+		return
+	}
+	for num := d.maxNum; num > 0; num-- {
+		ref := Ref{Num: num, Gen: d.generation(num)}
+		dict, ok := d.resolveQuiet(ref).(Dict)
+		if !ok || dict["Type"] != nil {
+			continue
+		}
+		for _, key := range infoKeys {
+			if _, ok := dict[key].(String); ok {
+				d.trailer["Info"] = ref
+				report.Info, report.InfoSource = num, "scan"
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package pdf_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+func repair(t *testing.T, data []byte) (*pdf.Document, *pdf.RepairReport) {
+	t.Helper()
+	doc, report, err := pdf.Repair(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	return doc, report
+}
+
+func TestRepair_ShiftedOffsets(t *testing.T) {
+	data := pdftest.BuildWithTrailer("/Info 5 0 R ", append(samplePages(2, ""), "<< /Title (Shifted) >>")...)
+	// Inserting bytes after the header invalidates every xref offset.
+	data = bytes.Replace(data, []byte("%PDF-1.4\n"), []byte("%PDF-1.4\n% inserted by a broken tool\n"), 1)
+
+	doc, report := repair(t, data)
+	if report.CatalogSource != "trailer" || report.Catalog != 1 {
+		t.Errorf("Expected catalog 1 from the trailer, got %d (%s)", report.Catalog, report.CatalogSource)
+	}
+	if report.InfoSource != "trailer" || report.Info != 5 {
+		t.Errorf("Expected Info 5 from the trailer, got %d (%s)", report.Info, report.InfoSource)
+	}
+	if report.ObjectsRecovered != 5 {
+		t.Errorf("Expected 5 recovered objects, got %d", report.ObjectsRecovered)
+	}
+	doc = rewrite(t, doc)
+	if pages, err := doc.Pages(); err != nil || len(pages) != 2 {
+		t.Errorf("Expected 2 pages, got %d (%v)", len(pages), err)
+	}
+	if info, _ := doc.Info(); info["Title"] != "Shifted" {
+		t.Errorf("Expected title Shifted, got %q", info["Title"])
+	}
+}
+
+func TestRepair_MissingTrailer(t *testing.T) {
+	data := pdftest.Build(append(samplePages(1, ""), "<< /Producer (scanner) >>")...)
+	data = data[:bytes.LastIndex(data, []byte("endobj"))+len("endobj\n")]
+
+	doc, report := repair(t, data)
+	if report.CatalogSource != "scan" || report.Catalog != 1 {
+		t.Errorf("Expected catalog 1 found by scanning, got %d (%s)", report.Catalog, report.CatalogSource)
+	}
+	if report.InfoSource != "scan" || report.Info != 4 {
+		t.Errorf("Expected Info 4 found by scanning, got %d (%s)", report.Info, report.InfoSource)
+	}
+	if info, _ := rewrite(t, doc).Info(); info["Producer"] != "scanner" {
+		t.Errorf("Expected producer scanner, got %q", info["Producer"])
+	}
+}
+
+func TestRepair_RebuildsCatalog(t *testing.T) {
+	objects := samplePages(1, "")
+	objects[0] = "<< /Broken"
+	data := pdftest.Build(objects...)
+
+	doc, report := repair(t, data)
+	if report.CatalogSource != "rebuilt" || report.Catalog != 4 {
+		t.Errorf("Expected rebuilt catalog 4, got %d (%s)", report.Catalog, report.CatalogSource)
+	}
+	if !reflect.DeepEqual(report.ObjectsDropped, []int{1}) {
+		t.Errorf("Expected object 1 to be dropped, got %v", report.ObjectsDropped)
+	}
+	if pages, err := rewrite(t, doc).Pages(); err != nil || len(pages) != 1 {
+		t.Errorf("Expected 1 page, got %d (%v)", len(pages), err)
+	}
+}
+
+func TestRepair_StreamLengthsAndUpdates(t *testing.T) {
+	objects := samplePages(1, "")
+	objects[2] = "<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>"
+	objects = append(objects, "<< /Length 3 >>\nstream\nBT /F1 12 Tf ET\nendstream", "<< /Title (Old) >>")
+	data := pdftest.BuildWithTrailer("/Info 5 0 R ", objects...)
+	// An appended revision without its own xref replaces the Info object.
+	data = append(data, "5 0 obj\n<< /Title (New) >>\nendobj\n"...)
+
+	doc, report := repair(t, data)
+	if !reflect.DeepEqual(report.LengthsFixed, []int{4}) {
+		t.Errorf("Expected the length of object 4 to be fixed, got %v", report.LengthsFixed)
+	}
+	doc = rewrite(t, doc)
+	if info, _ := doc.Info(); info["Title"] != "New" {
+		t.Errorf("Expected the updated title New, got %q", info["Title"])
+	}
+	pages, _ := doc.Pages()
+	content := doc.GetStream(doc.GetDict(pages[0])["Contents"])
+	if content == nil || string(content.Data) != "BT /F1 12 Tf ET" {
+		t.Errorf("Expected the full content stream, got %+v", content)
+	}
+}
+
+func TestRepair_Errors(t *testing.T) {
+	if _, _, err := pdf.Repair(bytes.NewReader([]byte("not a pdf")), 9); !errors.Is(err, pdf.ErrNotPDF) {
+		t.Errorf("Expected ErrNotPDF, got %v", err)
+	}
+	data := []byte("%PDF-1.4\n1 0 obj\n<< /Title (x) >>\nendobj\n")
+	if _, _, err := pdf.Repair(bytes.NewReader(data), int64(len(data))); !errors.Is(err, pdf.ErrMalformed) {
+		t.Errorf("Expected ErrMalformed without a catalog or page tree, got %v", err)
+	}
+}
+
+func TestPDFService_Repair(t *testing.T) {
+	data := pdftest.Build(samplePages(1, "")...)
+	path := writeTempPDF(t, bytes.Replace(data, []byte("%PDF-1.4\n"), []byte("%PDF-1.4\n\n\n"), 1))
+	service := pdf.NewPDFService()
+	if _, err := service.Repair(path, ""); err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	report, err := service.Validate(path)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Findings) != 0 {
+		t.Errorf("Expected a clean file after repair, got %v", report.Findings)
+	}
+}
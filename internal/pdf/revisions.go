@@ -0,0 +1,84 @@
+package pdf
+
+import (
+	"bytes"
+	"io"
+)
+
+// Revision is the document as it was saved at some point: the file up to
+// the end of one of its incremental updates.
+type Revision struct {
+	Document *Document
+	// Size is the length of the file at this revision.
+	Size int64
+}
+
+// Revisions returns the revisions of the PDF in r, which holds size bytes,
+// oldest first and ending with the current document. A file that was never
+// updated incrementally has one revision. Ends of file that do not complete
+// a readable revision, such as that of the first page section of a
+// linearized file, are skipped.
+func Revisions(r io.ReaderAt, size int64) ([]Revision, error) {
+	ends, err := eofMarkers(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []Revision
+	for _, end := range ends {
+		doc, err := NewDocument(io.NewSectionReader(r, 0, end), end)
+		if err != nil {
+			continue
+		}
+		// Data after the last marker, such as a trailing newline, leaves
+		// the same revision.
+		if n := len(revisions); n > 0 && revisions[n-1].Document.startxref == doc.startxref {
+			continue
+		}
+		revisions = append(revisions, Revision{Document: doc, Size: end})
+	}
+	if n := len(revisions); n == 0 || revisions[n-1].Size != size {
+		// The current document is read as a whole, even when it does not
+		// end with a marker.
+		doc, err := NewDocument(r, size)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || revisions[n-1].Document.startxref != doc.startxref {
+			revisions = append(revisions, Revision{Document: doc, Size: size})
+		}
+	}
+	return revisions, nil
+}
+
+// eofMarkers returns the offsets just past each %%EOF marker in r, and its
+// line ending.
+func eofMarkers(r io.ReaderAt, size int64) ([]int64, error) {
+	const chunk = 64 << 10
+	marker := []byte("%%EOF")
+	var ends []int64
+	buf := make([]byte, chunk+len(marker)+1)
+	for off := int64(0); off < size; off += chunk {
+		n, err := r.ReadAt(buf[:min(int64(len(buf)), size-off)], off)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		data := buf[:n]
+		for i := 0; ; {
+			j := bytes.Index(data[i:], marker)
+			// Markers starting in the overlap are found in the next chunk.
+			if j < 0 || i+j >= chunk {
+				break
+			}
+			end := i + j + len(marker)
+			if end < len(data) && data[end] == '\r' {
+				end++
+			}
+			if end < len(data) && data[end] == '\n' {
+				end++
+			}
+			ends = append(ends, off+int64(end))
+			i += j + len(marker)
+		}
+	}
+	return ends, nil
+}
@@ -0,0 +1,104 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TextSpan is the text shown by a single text-showing operator.
+type TextSpan struct {
+	Text string
+	// X and Y locate the start of the text in default user space; EndX is
+	// where the pen stopped.
+	X, Y, EndX float64
+	// FontSize is the effective font size in user space units, after the
+	// text and transformation matrices are applied.
+	FontSize float64
+	Font     string
+}
+
+// PageTextSpans returns the text of a page as spans, in content stream order.
+// page is 1-based.
+func (d *Document) PageTextSpans(page int) ([]TextSpan, error) {
+	pages, err := d.Pages()
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 || page > len(pages) {
+		return nil, fmt.Errorf("%w: page %d of %d", ErrPageOutOfRange, page, len(pages))
+	}
+	var spans []TextSpan
+	w := newContentWalker(d)
+	w.onText = func(s TextSpan) { spans = append(spans, s) }
+	if err := w.walkPage(pages[page-1]); err != nil {
+		return nil, fmt.Errorf("page %d: %w", page, err)
+	}
+	return spans, nil
+}
+
+// PageText returns the plain text of a page. page is 1-based.
+func (d *Document) PageText(page int) (string, error) {
+	spans, err := d.PageTextSpans(page)
+	if err != nil {
+		return "", err
+	}
+	return assembleText(spans), nil
+}
+
+// assembleText joins spans into lines. A span starts a new line when it
+// moves vertically by more than half the font size, and is separated from
+// the previous span by a space when there is a visible horizontal gap.
+func assembleText(spans []TextSpan) string {
+	var b strings.Builder
+	for i, s := range spans {
+		if i > 0 {
+			prev := spans[i-1]
+			size := math.Max(math.Max(prev.FontSize, s.FontSize), 1)
+			gap := s.X - prev.EndX
+			switch {
+			case math.Abs(s.Y-prev.Y) > size/2:
+				b.WriteByte('\n')
+			case (gap > size*0.15 || gap < -size) &&
+				!strings.HasSuffix(prev.Text, " ") && !strings.HasPrefix(s.Text, " "):
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(s.Text)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// PageText is the extracted text of one page.
+type PageText struct {
+	Page  int    `json:"page"`
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
+
+// ExtractText returns the text of the pages in selection, which uses the
+// syntax of ParsePageSelection. An empty selection means every page.
+func (d *Document) ExtractText(selection string) ([]PageText, error) {
+	labels, err := d.PageLabels()
+	if err != nil {
+		return nil, err
+	}
+	pages, err := selectPages(selection, labels)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]PageText, 0, len(pages))
+	for _, page := range pages {
+		text, err := d.PageText(page)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, PageText{Page: page, Label: labels[page-1], Text: text})
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package pdf_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+// textPDF returns a one-page document with the given content stream, a
+// WinAnsi Helvetica as /F1, a composite font with a ToUnicode CMap as /F2,
+// a font with a Differences encoding as /F3 and a form XObject /Fm1.
+func textPDF(content string) []byte {
+	cmap := `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar <0003> <0020> <0011> <00DF> endbfchar
+1 beginbfrange <0024> <0026> <0041> endbfrange
+endcmap`
+	form := "BT /F1 10 Tf 0 0 Td (In a form) Tj ET"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Font << /F1 6 0 R /F2 7 0 R /F3 9 0 R >> /XObject << /Fm1 10 0 R >> >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Noto /Encoding /Identity-H /ToUnicode 8 0 R /DescendantFonts [<< /DW 600 >>] >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(cmap), cmap),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Custom /Encoding << /Differences [1 /fi /uni00E9 /T] >> >>",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Form /BBox [0 0 100 100] /Matrix [1 0 0 1 50 50] /Length %d >>\nstream\n%s\nendstream", len(form), form),
+	}
+	// Resources are inherited from the page tree root.
+	objects[1] = "<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources 5 0 R >>"
+	return pdftest.Build(objects...)
+}
+
+func TestDocument_PageText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"lines and kerning", "BT /F1 12 Tf 72 720 Td (Hello) Tj ( World) Tj 0 -14 Td [(Sec) -250 (ond)] TJ ET", "Hello World\nSec ond"},
+		{"quote operators", "BT /F1 10 Tf 12 TL 72 720 Td (one) Tj (two) ' 1 0 (three) \" ET", "one\ntwo\nthree"},
+		{"WinAnsi high codes", "BT /F1 10 Tf 72 720 Td <93E97480> Tj ET", "“ét€"},
+		{"ToUnicode composite font", "BT /F2 10 Tf 72 720 Td <002400250026000300240011> Tj ET", "ABC Aß"},
+		{"Differences", "BT /F3 10 Tf 72 720 Td <0102034142> Tj ET", "fiéTAB"},
+		{"form XObject", "q 1 0 0 1 0 0 cm /Fm1 Do Q", "In a form"},
+		{"inline image", "BI /W 2 /H 1 /CS /G /BPC 8 ID \x00\xFF EI BT /F1 10 Tf (after) Tj ET", "after"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openPDF(t, textPDF(tt.content))
+			got, err := doc.PageText(1)
+			if err != nil {
+				t.Fatalf("PageText failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDocument_PageTextSpans_FontSize(t *testing.T) {
+	doc := openPDF(t, textPDF("BT /F1 1 Tf 24 0 0 24 72 700 Tm (Title) Tj /F1 10 Tf 1 0 0 1 72 650 Tm (Body) Tj ET"))
+	spans, err := doc.PageTextSpans(1)
+	if err != nil {
+		t.Fatalf("PageTextSpans failed: %v", err)
+	}
+	var sizes []float64
+	for _, s := range spans {
+		sizes = append(sizes, s.FontSize)
+	}
+	if want := []float64{24, 10}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("Expected font sizes %v, got %v", want, sizes)
+	}
+	if spans[0].X != 72 || spans[0].Y != 700 {
+		t.Errorf("Expected title at (72, 700), got (%v, %v)", spans[0].X, spans[0].Y)
+	}
+}
+
+func TestPDFService_ExtractText(t *testing.T) {
+	path := writeTempPDF(t, textPDF("BT /F1 12 Tf 72 720 Td (Page text) Tj ET"))
+	pages, err := pdf.NewPDFService().ExtractText(path, "")
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	want := []pdf.PageText{{Page: 1, Label: "1", Text: "Page text"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("Expected %+v, got %+v", want, pages)
+	}
+	if _, err := pdf.NewPDFService().ExtractText(path, "2"); err == nil {
+		t.Error("Expected error for a page selection outside the document")
+	}
+}
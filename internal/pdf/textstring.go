@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// pdfDocEncoding maps the bytes of PDFDocEncoding that differ from Latin-1.
+var pdfDocEncoding = map[byte]rune{
+	0x18: '˘', 0x19: 'ˇ', 0x1A: 'ˆ', 0x1B: '˙', 0x1C: '˝', 0x1D: '˛', 0x1E: '˚', 0x1F: '˜',
+	0x80: '•', 0x81: '†', 0x82: '‡', 0x83: '…', 0x84: '—', 0x85: '–', 0x86: 'ƒ', 0x87: '⁄',
+	0x88: '‹', 0x89: '›', 0x8A: '−', 0x8B: '‰', 0x8C: '„', 0x8D: '“', 0x8E: '”', 0x8F: '‘',
+	0x90: '’', 0x91: '‚', 0x92: '™', 0x93: 'ﬁ', 0x94: 'ﬂ', 0x95: 'Ł', 0x96: 'Œ', 0x97: 'Š',
+	0x98: 'Ÿ', 0x99: 'Ž', 0x9A: 'ı', 0x9B: 'ł', 0x9C: 'œ', 0x9D: 'š', 0x9E: 'ž', 0xA0: '€',
+}
+
+// DecodeTextString converts a PDF text string, encoded either as UTF-16BE
+// with a byte order mark, UTF-8 with a byte order mark, or PDFDocEncoding,
+// to a Go string.
+func DecodeTextString(s String) string {
+	b := []byte(s)
+	switch {
+	case len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF:
+		u := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(u))
+	case len(b) >= 3 && b[0] == 0xEF && b[1] == 0xBB && b[2] == 0xBF:
+		return string(b[3:])
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		if r, ok := pdfDocEncoding[c]; ok {
+			runes[i] = r
+		} else {
+			runes[i] = rune(c)
+		}
+	}
+	return string(runes)
+}
+
+// EncodeTextString converts a Go string to a PDF text string, using
+// PDFDocEncoding when possible and UTF-16BE otherwise.
+func EncodeTextString(s string) String {
+	latin := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r == utf8.RuneError || r > 0xFF || (r >= 0x18 && r <= 0x1F) || (r >= 0x7F && r <= 0xA0) || r == 0xAD {
+			return encodeUTF16(s)
+		}
+		latin = append(latin, byte(r))
+	}
+	return String(latin)
+}
+
+func encodeUTF16(s string) String {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 2, 2+2*len(u))
+	b[0], b[1] = 0xFE, 0xFF
+	for _, c := range u {
+		b = append(b, byte(c>>8), byte(c))
+	}
+	return String(b)
+}
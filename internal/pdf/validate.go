@@ -0,0 +1,442 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Severity ranks validation findings.
+type Severity string
+
+const (
+	// SeverityError marks damage that readers may not recover from.
+	SeverityError Severity = "error"
+	// SeverityWarning marks problems readers usually tolerate.
+	SeverityWarning Severity = "warning"
+)
+
+// Checks performed by Validate, as reported in Finding.Check.
+const (
+	CheckHeader    = "header"
+	CheckXref      = "xref"
+	CheckTrailer   = "trailer"
+	CheckObjStm    = "objstm"
+	CheckReference = "reference"
+	CheckLength    = "length"
+	CheckPages     = "pages"
+	CheckMetadata  = "metadata"
+	CheckPDFA      = "pdfa"
+)
+
+// Finding is a problem found by Validate. Object is the number of the
+// object concerned, or 0. Rule names the clause of the standard that was
+// violated, for the checks of ValidatePDFA.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Rule     string   `json:"rule,omitempty"`
+	Object   int      `json:"object,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	check := f.Check
+	if f.Rule != "" {
+		check += " " + f.Rule
+	}
+	if f.Object != 0 {
+		return fmt.Sprintf("%s [%s] object %d: %s", f.Severity, check, f.Object, f.Message)
+	}
+	return fmt.Sprintf("%s [%s] %s", f.Severity, check, f.Message)
+}
+
+// ValidationReport lists the findings for a file.
+type ValidationReport struct {
+	File     string    `json:"file,omitempty"`
+	Version  string    `json:"version,omitempty"`
+	Findings []Finding `json:"findings"`
+}
+
+// Count returns the number of findings with the given severity.
+func (r *ValidationReport) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Valid reports whether there are no errors.
+func (r *ValidationReport) Valid() bool {
+	return r.Count(SeverityError) == 0
+}
+
+// validator collects findings while checking a document.
+type validator struct {
+	d      *Document
+	report *ValidationReport
+}
+
+func (v *validator) add(severity Severity, check string, object int, format string, args ...any) {
+	v.report.Findings = append(v.report.Findings, Finding{
+		Severity: severity,
+		Check:    check,
+		Object:   object,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate checks the structure of the PDF held in r: the header, the
+// cross-reference data and trailer, object streams, references, stream
+// lengths, the page tree and the agreement of the Info dictionary with the
+// XMP metadata. Problems are reported as findings, so Validate never fails.
+func Validate(r io.ReaderAt, size int64) *ValidationReport {
+	return validate(r, size).report
+}
+
+// validate runs the checks of Validate. The returned validator holds the
+// parsed document unless the cross-reference data could not be read.
+func validate(r io.ReaderAt, size int64) *validator {
+	report := &ValidationReport{Findings: []Finding{}}
+	v := &validator{report: report}
+	v.checkHeader(r, size)
+
+	d, err := NewDocument(r, size)
+	if err != nil {
+		v.add(SeverityError, CheckXref, 0, "cannot read the cross-reference data: %v", err)
+		return v
+	}
+	v.d = d
+	report.Version = d.Version()
+
+	v.checkTrailer()
+	v.checkObjects()
+	v.checkPageTree()
+	if d.Encrypted() {
+		v.add(SeverityWarning, CheckMetadata, 0, "document is encrypted, metadata was not checked")
+	} else {
+		v.checkMetadata()
+	}
+	return v
+}
+
+func (v *validator) checkHeader(r io.ReaderAt, size int64) {
+	buf := make([]byte, min(size, 1024))
+	n, _ := r.ReadAt(buf, 0)
+	buf = buf[:n]
+	i := bytes.Index(buf, []byte("%PDF-"))
+	switch {
+	case i < 0:
+		v.add(SeverityError, CheckHeader, 0, "no %%PDF- header in the first 1024 bytes")
+		return
+	case i > 0:
+		v.add(SeverityWarning, CheckHeader, 0, "header starts at offset %d instead of 0", i)
+	}
+	version := buf[i+5:]
+	if len(version) < 3 || version[0] < '1' || version[0] > '2' || version[1] != '.' || version[2] < '0' || version[2] > '9' {
+		v.add(SeverityWarning, CheckHeader, 0, "unknown version in header")
+	}
+}
+
+func (v *validator) checkTrailer() {
+	d := v.d
+	size, ok := d.GetInt(d.trailer["Size"])
+	switch {
+	case !ok:
+		v.add(SeverityError, CheckTrailer, 0, "trailer has no /Size")
+	case int(size) <= d.maxNum:
+		v.add(SeverityError, CheckTrailer, 0, "/Size %d does not exceed the highest object number %d", size, d.maxNum)
+	}
+
+	root := d.trailer["Root"].(Ref)
+	if catalog := d.GetDict(root); catalog == nil {
+		v.add(SeverityError, CheckTrailer, root.Num, "/Root is not a dictionary")
+	} else if d.GetName(catalog["Type"]) != "Catalog" {
+		v.add(SeverityWarning, CheckTrailer, root.Num, "catalog has no /Type /Catalog")
+	}
+
+	if info, ok := d.trailer["Info"]; ok {
+		ref, isRef := info.(Ref)
+		if d.GetDict(info) == nil {
+			v.add(SeverityError, CheckTrailer, ref.Num, "/Info is not a dictionary")
+		} else if !isRef {
+			v.add(SeverityWarning, CheckTrailer, 0, "/Info is not an indirect reference")
+		}
+	}
+
+	id := d.GetArray(d.trailer["ID"])
+	switch {
+	case d.trailer["ID"] == nil:
+		v.add(SeverityWarning, CheckTrailer, 0, "trailer has no /ID")
+	case len(id) != 2:
+		v.add(SeverityError, CheckTrailer, 0, "/ID is not an array of two strings")
+	default:
+		for _, part := range id {
+			if _, ok := d.GetString(part); !ok {
+				v.add(SeverityError, CheckTrailer, 0, "/ID is not an array of two strings")
+				break
+			}
+		}
+	}
+}
+
+// checkObjects reads every object listed in the cross-reference data,
+// checking its location, stream length and references.
+func (v *validator) checkObjects() {
+	d := v.d
+	nums := make([]int, 0, len(d.xref))
+	for num := range d.xref {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	checkedStms := map[int]bool{}
+	for _, num := range nums {
+		e := d.xref[num]
+		switch e.typ {
+		case xrefInUse:
+			v.checkInUse(num, e)
+		case xrefCompressed:
+			stmNum := int(e.offset)
+			if !checkedStms[stmNum] {
+				checkedStms[stmNum] = true
+				v.checkObjectStream(stmNum)
+			}
+			if _, err := d.compressedObject(stmNum, e.gen); err != nil {
+				v.add(SeverityError, CheckObjStm, num, "cannot read from object stream %d: %v", stmNum, err)
+			}
+		case xrefFree:
+		default:
+			v.add(SeverityWarning, CheckXref, num, "unknown cross-reference entry type %d", e.typ)
+		}
+	}
+
+	for _, num := range nums {
+		obj, _, err := d.shallowObject(num)
+		if err != nil || obj == nil {
+			continue
+		}
+		v.checkReferences(num, obj)
+	}
+}
+
+func (v *validator) checkInUse(num int, e xrefEntry) {
+	d := v.d
+	if e.offset <= 0 || e.offset >= d.size {
+		v.add(SeverityError, CheckXref, num, "offset %d is outside the file", e.offset)
+		return
+	}
+	obj, ref, dataStart, err := d.readObjectHeader(e.offset)
+	switch {
+	case err != nil:
+		// Drop the offset readObjectHeader adds, which the message repeats.
+		v.add(SeverityError, CheckXref, num, "no object at offset %d: %v", e.offset, errors.Unwrap(err))
+		return
+	case ref.Num != num:
+		v.add(SeverityError, CheckXref, num, "offset %d holds object %d", e.offset, ref.Num)
+		return
+	case ref.Gen != e.gen:
+		v.add(SeverityWarning, CheckXref, num, "generation is %d, cross-reference says %d", ref.Gen, e.gen)
+	}
+	if dataStart < 0 {
+		return
+	}
+
+	length, ok := d.GetInt(obj.(Dict)["Length"])
+	if ok && length >= 0 {
+		// Scanning for "endstream" is slow on large streams and stops early
+		// when binary data contains the keyword, so trust a /Length that
+		// ends at the keyword.
+		tail, _ := d.readAt(dataStart+length, 32)
+		if bytes.HasPrefix(bytes.TrimLeft(tail, "\r\n \t"), []byte("endstream")) {
+			return
+		}
+	}
+	scanned, err := d.scanStreamLength(dataStart)
+	switch {
+	case err != nil:
+		v.add(SeverityError, CheckLength, num, "stream has no endstream")
+	case !ok:
+		v.add(SeverityError, CheckLength, num, "stream has no valid /Length")
+	default:
+		v.add(SeverityError, CheckLength, num, "/Length is %d but the stream data is %d bytes", length, scanned)
+	}
+}
+
+func (v *validator) checkObjectStream(num int) {
+	d := v.d
+	obj, err := d.Object(num)
+	if err != nil {
+		return // reported by the cross-reference checks
+	}
+	s, ok := obj.(*Stream)
+	if !ok || d.GetName(s.Dict["Type"]) != "ObjStm" {
+		v.add(SeverityError, CheckObjStm, num, "object stream is not a /Type /ObjStm stream")
+		return
+	}
+	n, okN := d.GetInt(s.Dict["N"])
+	first, okFirst := d.GetInt(s.Dict["First"])
+	if !okN || !okFirst || n < 0 || first < 0 {
+		v.add(SeverityError, CheckObjStm, num, "object stream has an invalid /N or /First")
+		return
+	}
+	stm, err := d.objectStream(num)
+	if err != nil {
+		v.add(SeverityError, CheckObjStm, num, "cannot read object stream: %v", err)
+		return
+	}
+	if stm.first > len(stm.data) {
+		v.add(SeverityError, CheckObjStm, num, "/First %d is beyond the %d bytes of data", stm.first, len(stm.data))
+		return
+	}
+	for i := 1; i < len(stm.offsets); i++ {
+		if stm.offsets[i] <= stm.offsets[i-1] {
+			v.add(SeverityWarning, CheckObjStm, num, "object offsets are not increasing")
+			break
+		}
+	}
+}
+
+// checkReferences reports references to objects that do not exist.
+func (v *validator) checkReferences(num int, obj Object) {
+	missing := map[int]bool{}
+	var walk func(obj Object)
+	walk = func(obj Object) {
+		switch o := obj.(type) {
+		case Ref:
+			e, ok := v.d.xref[o.Num]
+			if (!ok || e.typ == xrefFree) && !missing[o.Num] {
+				missing[o.Num] = true
+				v.add(SeverityWarning, CheckReference, num, "reference to missing object %d", o.Num)
+			}
+		case Array:
+			for _, item := range o {
+				walk(item)
+			}
+		case Dict:
+			for _, item := range o {
+				walk(item)
+			}
+		case *Stream:
+			walk(o.Dict)
+		}
+	}
+	walk(obj)
+}
+
+// checkPageTree walks the page tree, reporting cycles, wrong /Count values
+// and /Parent entries that do not point back.
+func (v *validator) checkPageTree() {
+	d := v.d
+	catalog, rootRef, err := d.Catalog()
+	if err != nil {
+		return
+	}
+	root, ok := catalog["Pages"].(Ref)
+	if !ok {
+		v.add(SeverityError, CheckPages, rootRef.Num, "catalog has no /Pages reference")
+		return
+	}
+	visiting := map[Ref]bool{}
+	seen := map[Ref]bool{}
+	var walk func(ref, parent Ref) int
+	walk = func(ref, parent Ref) int {
+		if visiting[ref] {
+			v.add(SeverityError, CheckPages, ref.Num, "page tree cycle")
+			return 0
+		}
+		if seen[ref] {
+			v.add(SeverityError, CheckPages, ref.Num, "page tree node is used more than once")
+			return 0
+		}
+		seen[ref], visiting[ref] = true, true
+		defer delete(visiting, ref)
+
+		node := d.GetDict(ref)
+		if node == nil {
+			v.add(SeverityError, CheckPages, ref.Num, "page tree node is not a dictionary")
+			return 0
+		}
+		if parent.Num != 0 && node["Parent"] != parent {
+			v.add(SeverityWarning, CheckPages, ref.Num, "/Parent does not point to object %d", parent.Num)
+		}
+		switch d.GetName(node["Type"]) {
+		case "Page":
+			return 1
+		case "Pages":
+		default:
+			v.add(SeverityWarning, CheckPages, ref.Num, "page tree node has no /Type /Page or /Pages")
+			if _, ok := node["Kids"]; !ok {
+				return 1
+			}
+		}
+		leaves := 0
+		for _, kid := range d.GetArray(node["Kids"]) {
+			kidRef, ok := kid.(Ref)
+			if !ok {
+				v.add(SeverityError, CheckPages, ref.Num, "/Kids holds a direct object")
+				continue
+			}
+			leaves += walk(kidRef, ref)
+		}
+		if count, ok := d.GetInt(node["Count"]); !ok || int(count) != leaves {
+			v.add(SeverityError, CheckPages, ref.Num, "/Count is %v but the node has %d pages", node["Count"], leaves)
+		}
+		return leaves
+	}
+	if walk(root, Ref{}) == 0 {
+		v.add(SeverityError, CheckPages, root.Num, "document has no pages")
+	}
+}
+
+// checkMetadata compares the Info dictionary with the XMP metadata.
+func (v *validator) checkMetadata() {
+	d := v.d
+	info, err := d.Info()
+	if err != nil {
+		return
+	}
+	catalog, _, err := d.Catalog()
+	if err != nil {
+		return
+	}
+	metaRef, _ := catalog["Metadata"].(Ref)
+	xmp, err := d.XMPMetadata()
+	if err != nil {
+		v.add(SeverityError, CheckMetadata, metaRef.Num, "cannot read XMP metadata: %v", err)
+		return
+	}
+	if xmp == nil {
+		return
+	}
+	for _, p := range infoXMPProperties {
+		infoValue, inInfo := info[p.info]
+		xmpValue, inXMP := xmp[p.xmp]
+		switch {
+		case !inInfo && !inXMP:
+		case !inInfo:
+			v.add(SeverityWarning, CheckMetadata, metaRef.Num, "%s is set in XMP but not in Info", p.xmp)
+		case !inXMP:
+			v.add(SeverityWarning, CheckMetadata, metaRef.Num, "Info /%s is not set in XMP as %s", p.info, p.xmp)
+		case !sameMetadataValue(p.info, infoValue, xmpValue):
+			v.add(SeverityWarning, CheckMetadata, metaRef.Num, "Info /%s %q differs from XMP %s %q", p.info, infoValue, p.xmp, xmpValue)
+		}
+	}
+}
+
+// sameMetadataValue compares an Info value with the matching XMP value.
+// Dates are compared as instants since the two use different formats.
+func sameMetadataValue(key, info, xmp string) bool {
+	if key == "CreationDate" || key == "ModDate" {
+		a, okA := ParseDate(info)
+		b, okB := parseXMPDate(xmp)
+		return okA && okB && a.Equal(b)
+	}
+	return strings.TrimSpace(info) == strings.TrimSpace(xmp)
+}
@@ -0,0 +1,126 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+func validate(data []byte) *pdf.ValidationReport {
+	return pdf.Validate(bytes.NewReader(data), int64(len(data)))
+}
+
+// hasFinding reports whether the report has a finding for the check and
+// object whose message contains text.
+func hasFinding(r *pdf.ValidationReport, severity pdf.Severity, check string, object int, text string) bool {
+	for _, f := range r.Findings {
+		if f.Severity == severity && f.Check == check && f.Object == object && strings.Contains(f.Message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidate_RewrittenFileIsClean(t *testing.T) {
+	doc := openPDF(t, outlinePDF())
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	report := validate(buf.Bytes())
+	if len(report.Findings) != 0 {
+		t.Errorf("Expected no findings, got %v", report.Findings)
+	}
+	if report.Version != "1.4" {
+		t.Errorf("Expected version 1.4, got %q", report.Version)
+	}
+}
+
+func TestValidate_Findings(t *testing.T) {
+	xmp := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" xmlns:dc="http://purl.org/dc/elements/1.1/" pdf:Producer="pdfmod">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">XMP Title</rdf:li></rdf:Alt></dc:title>
+</rdf:Description></rdf:RDF></x:xmpmeta>`
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 6 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 3 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R /Annots [9 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Length 99 >>\nstream\nBT ET\nendstream",
+		streamObject("/Type /Metadata /Subtype /XML", []byte(xmp)),
+		"<< /Title (Info Title) /Producer (pdfmod) >>",
+	}
+	data := pdftest.BuildWithTrailer("/Info 7 0 R ", objects...)
+	// Point the entry of object 4 one byte past the object.
+	off := bytes.Index(data, []byte("4 0 obj"))
+	data = bytes.Replace(data, []byte(fmt.Sprintf("%010d 00000 n", off)), []byte(fmt.Sprintf("%010d 00000 n", off+1)), 1)
+
+	report := validate(data)
+	if report.Valid() {
+		t.Error("Expected the report to have errors")
+	}
+	for _, want := range []struct {
+		severity pdf.Severity
+		check    string
+		object   int
+		text     string
+	}{
+		{pdf.SeverityWarning, pdf.CheckTrailer, 0, "no /ID"},
+		{pdf.SeverityError, pdf.CheckXref, 4, "no object at offset"},
+		{pdf.SeverityError, pdf.CheckLength, 5, "/Length is 99"},
+		{pdf.SeverityWarning, pdf.CheckReference, 3, "missing object 9"},
+		{pdf.SeverityError, pdf.CheckPages, 2, "/Count is 3"},
+		{pdf.SeverityWarning, pdf.CheckMetadata, 6, `"Info Title" differs from XMP dc:title "XMP Title"`},
+	} {
+		if !hasFinding(report, want.severity, want.check, want.object, want.text) {
+			t.Errorf("Missing %s [%s] object %d: %q in %v", want.severity, want.check, want.object, want.text, report.Findings)
+		}
+	}
+	if hasFinding(report, pdf.SeverityWarning, pdf.CheckMetadata, 6, "Producer") {
+		t.Errorf("Expected matching producers not to be reported: %v", report.Findings)
+	}
+}
+
+func TestValidate_PageTreeCycle(t *testing.T) {
+	report := validate(pdftest.Build(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [2 0 R] /Count 1 >>",
+	))
+	if !hasFinding(report, pdf.SeverityError, pdf.CheckPages, 2, "cycle") {
+		t.Errorf("Expected a page tree cycle, got %v", report.Findings)
+	}
+}
+
+func TestValidate_NotPDF(t *testing.T) {
+	report := validate([]byte("hello"))
+	if !hasFinding(report, pdf.SeverityError, pdf.CheckHeader, 0, "no %PDF- header") {
+		t.Errorf("Expected a header error, got %v", report.Findings)
+	}
+	if report.Valid() {
+		t.Error("Expected the report to have errors")
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := map[string]time.Time{
+		"D:20240131120000+01'00'": time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC),
+		"D:20240131120000Z":       time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+		"D:2024":                  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"20240131":                time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for in, want := range tests {
+		got, ok := pdf.ParseDate(in)
+		if !ok || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	if _, ok := pdf.ParseDate("yesterday"); ok {
+		t.Error("Expected an invalid date to fail")
+	}
+}
@@ -0,0 +1,345 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// countingWriter tracks the number of bytes written so object offsets can be
+// recorded for the cross-reference table.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// objectsPerStream is the number of objects packed into each object stream
+// when writing with WriteOptions.ObjectStreams.
+const objectsPerStream = 100
+
+// WriteOptions control how a document is serialized.
+type WriteOptions struct {
+	// ObjectStreams packs all objects except streams into compressed object
+	// streams and replaces the cross-reference table with a cross-reference
+	// stream. The output then requires PDF 1.5.
+	ObjectStreams bool
+	// Incremental appends the changed objects to an unchanged copy of the
+	// original file as an incremental update, instead of writing a new
+	// file. It is the fastest way to save small changes to large files, but
+	// earlier versions of the changed objects stay in the file.
+	Incremental bool
+}
+
+// Write serializes the document to w as a single revision with a fresh
+// cross-reference table. Objects from object streams are written as regular
+// objects, and the object and cross-reference streams themselves are dropped.
+func (d *Document) Write(w io.Writer) error {
+	return d.WriteWithOptions(w, WriteOptions{})
+}
+
+// WriteWithOptions serializes the document to w as a single revision, or
+// as an incremental update with opts.Incremental. The data of unchanged
+// streams is copied from the original file without being held in memory.
+func (d *Document) WriteWithOptions(w io.Writer, opts WriteOptions) error {
+	if d.Encrypted() {
+		return ErrEncrypted
+	}
+	if opts.Incremental {
+		if opts.ObjectStreams {
+			return errors.New("object streams cannot be added in an incremental update")
+		}
+		return d.writeIncremental(w)
+	}
+	cw := &countingWriter{w: w}
+	out := bufio.NewWriter(cw)
+
+	version := d.version
+	if version == "" {
+		version = "1.7"
+	}
+	if opts.ObjectStreams && version < "1.5" {
+		version = "1.5"
+	}
+	fmt.Fprintf(out, "%%PDF-%s\n%%\xE2\xE3\xCF\xD3\n", version)
+
+	entries := make([]xrefEntry, d.maxNum+1)
+	var packed []int
+	for num := 1; num <= d.maxNum; num++ {
+		obj, src, err := d.shallowObject(num)
+		if err != nil {
+			return err
+		}
+		if obj == nil || isStructuralStream(obj) {
+			continue
+		}
+		gen := d.generation(num)
+		if _, isStream := obj.(*Stream); opts.ObjectStreams && !isStream && gen == 0 {
+			packed = append(packed, num)
+			continue
+		}
+		entries[num] = xrefEntry{typ: xrefInUse, offset: cw.n + int64(out.Buffered()), gen: gen}
+		if src != nil {
+			err = d.copyStream(out, num, gen, obj.(*Stream).Dict, src)
+		} else {
+			err = writeIndirect(out, num, gen, obj)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if opts.ObjectStreams {
+		return d.writeObjectStreams(out, cw, entries, packed)
+	}
+
+	xrefOffset := cw.n + int64(out.Buffered())
+	nextFree := freeList(entries)
+	fmt.Fprintf(out, "xref\n0 %d\n", len(entries))
+	for num, e := range entries {
+		switch {
+		case num == 0:
+			fmt.Fprintf(out, "%010d 65535 f\r\n", nextFree[0])
+		case e.typ == xrefFree:
+			fmt.Fprintf(out, "%010d 00001 f\r\n", nextFree[num])
+		default:
+			fmt.Fprintf(out, "%010d %05d n\r\n", e.offset, e.gen)
+		}
+	}
+
+	out.WriteString("trailer\n")
+	if err := writeObject(out, d.newTrailer(len(entries))); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+	return out.Flush()
+}
+
+// writeObjectStreams writes the objects in packed into object streams,
+// followed by a cross-reference stream covering entries and the new streams.
+func (d *Document) writeObjectStreams(out *bufio.Writer, cw *countingWriter, entries []xrefEntry, packed []int) error {
+	for start := 0; start < len(packed); start += objectsPerStream {
+		batch := packed[start:min(start+objectsPerStream, len(packed))]
+		stm, err := d.buildObjectStream(batch)
+		if err != nil {
+			return err
+		}
+		num := len(entries)
+		entries = append(entries, xrefEntry{typ: xrefInUse, offset: cw.n + int64(out.Buffered())})
+		for i, n := range batch {
+			entries[n] = xrefEntry{typ: xrefCompressed, offset: int64(num), gen: i}
+		}
+		if err := writeIndirect(out, num, 0, stm); err != nil {
+			return err
+		}
+	}
+
+	xrefNum := len(entries)
+	xrefOffset := cw.n + int64(out.Buffered())
+	entries = append(entries, xrefEntry{typ: xrefInUse, offset: xrefOffset})
+	nextFree := freeList(entries)
+
+	var maxField2, maxField3 int64 = 0, 65535
+	for num, e := range entries {
+		field2 := e.offset
+		if e.typ == xrefFree {
+			field2 = int64(nextFree[num])
+		}
+		maxField2 = max(maxField2, field2)
+		maxField3 = max(maxField3, int64(e.gen))
+	}
+	w2, w3 := byteWidth(maxField2), byteWidth(maxField3)
+	var rows bytes.Buffer
+	for num, e := range entries {
+		field2, field3 := e.offset, int64(e.gen)
+		if e.typ == xrefFree {
+			field2, field3 = int64(nextFree[num]), 1
+			if num == 0 {
+				field3 = 65535
+			}
+		}
+		rows.WriteByte(byte(e.typ))
+		putUint(&rows, field2, w2)
+		putUint(&rows, field3, w3)
+	}
+
+	dict := d.newTrailer(len(entries))
+	dict["Type"] = Name("XRef")
+	dict["W"] = Array{int64(1), int64(w2), int64(w3)}
+	dict["Filter"] = Name("FlateDecode")
+	if err := writeIndirect(out, xrefNum, 0, &Stream{Dict: dict, Data: deflate(rows.Bytes())}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+	return out.Flush()
+}
+
+// buildObjectStream serializes the objects nums into a compressed object
+// stream.
+func (d *Document) buildObjectStream(nums []int) (*Stream, error) {
+	var header, body bytes.Buffer
+	bw := bufio.NewWriter(&body)
+	for _, num := range nums {
+		obj, err := d.Object(num)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&header, "%d %d ", num, body.Len()+bw.Buffered())
+		if err := writeObject(bw, obj); err != nil {
+			return nil, fmt.Errorf("writing object %d: %w", num, err)
+		}
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	first := header.Len()
+	header.Write(body.Bytes())
+	return &Stream{
+		Dict: Dict{
+			"Type":   Name("ObjStm"),
+			"N":      int64(len(nums)),
+			"First":  int64(first),
+			"Filter": Name("FlateDecode"),
+		},
+		Data: deflate(header.Bytes()),
+	}, nil
+}
+
+// copyStream writes a stream whose data is copied from src in the file.
+func (d *Document) copyStream(out *bufio.Writer, num, gen int, dict Dict, src *streamSource) error {
+	dict = dict.Clone()
+	dict["Length"] = src.length
+	fmt.Fprintf(out, "%d %d obj\n", num, gen)
+	if err := writeObject(out, dict); err != nil {
+		return fmt.Errorf("writing object %d: %w", num, err)
+	}
+	out.WriteString("\nstream\n")
+	if _, err := io.Copy(out, io.NewSectionReader(d.r, src.off, src.length)); err != nil {
+		return fmt.Errorf("copying stream data of object %d: %w", num, err)
+	}
+	out.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+func writeIndirect(out *bufio.Writer, num, gen int, obj Object) error {
+	fmt.Fprintf(out, "%d %d obj\n", num, gen)
+	if err := writeObject(out, obj); err != nil {
+		return fmt.Errorf("writing object %d: %w", num, err)
+	}
+	out.WriteString("\nendobj\n")
+	return nil
+}
+
+// newTrailer returns the trailer entries of a rewritten document. Files
+// without an /ID get one derived from their Info dictionary, so that
+// writing the same document twice gives the same bytes.
+func (d *Document) newTrailer(size int) Dict {
+	trailer := Dict{"Size": int64(size), "Root": d.trailer["Root"]}
+	for _, key := range []Name{"Info", "ID"} {
+		if v, ok := d.trailer[key]; ok {
+			trailer[key] = v
+		}
+	}
+	if _, ok := trailer["ID"]; !ok {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeObject(w, Array{d.trailer["Root"], d.GetDict(d.trailer["Info"]), int64(size)})
+		w.Flush()
+		sum := md5.Sum(buf.Bytes())
+		trailer["ID"] = Array{String(sum[:]), String(sum[:])}
+	}
+	return trailer
+}
+
+// freeList links the free entries into a list starting at object 0 and
+// returns the next free object number of each entry.
+func freeList(entries []xrefEntry) []int {
+	nextFree := make([]int, len(entries))
+	last := 0
+	for num := 1; num < len(entries); num++ {
+		if entries[num].typ == xrefFree {
+			nextFree[last] = num
+			last = num
+		}
+	}
+	return nextFree
+}
+
+// byteWidth returns the number of bytes needed to store v.
+func byteWidth(v int64) int {
+	n := 1
+	for v > 0xFF {
+		v >>= 8
+		n++
+	}
+	return n
+}
+
+// putUint writes v as a big-endian number of n bytes.
+func putUint(buf *bytes.Buffer, v int64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		buf.WriteByte(byte(v >> (8 * i)))
+	}
+}
+
+// isStructuralStream reports whether obj is an object stream or a
+// cross-reference stream, which Write replaces with a plain table.
+func isStructuralStream(obj Object) bool {
+	s, ok := obj.(*Stream)
+	if !ok {
+		return false
+	}
+	t, _ := s.Dict["Type"].(Name)
+	return t == "ObjStm" || t == "XRef"
+}
+
+// WriteFile writes the document to path through a temporary file in the
+// same directory, so the original is only replaced once writing succeeded.
+// path may be the file the document was opened from.
+func (d *Document) WriteFile(path string) error {
+	return d.WriteFileWithOptions(path, WriteOptions{})
+}
+
+// WriteFileWithOptions is WriteFile with serialization options.
+func (d *Document) WriteFileWithOptions(path string, opts WriteOptions) error {
+	return replaceFile(path, func(f *os.File) error {
+		return d.WriteWithOptions(f, opts)
+	})
+}
+
+// replaceFile calls write with a temporary file in the directory of path
+// and renames it to path, keeping the permissions of the file it replaces,
+// unless write fails.
+func replaceFile(path string, write func(f *os.File) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".pdfmod-*.pdf")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
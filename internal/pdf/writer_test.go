@@ -0,0 +1,196 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+)
+
+func TestDocument_Write_CopiesStreamData(t *testing.T) {
+	data := []byte("binary\x00endstreamish\r\ndata")
+	objects := append(samplePages(1, ""),
+		streamObject("", data),
+		// A wrong /Length is corrected from the endstream keyword.
+		"<< /Length 99 >>\nstream\nshort\nendstream")
+	doc := openPDF(t, pdftest.Build(objects...))
+
+	out := rewrite(t, doc)
+	if s := out.GetStream(pdf.Ref{Num: 4}); s == nil || !bytes.Equal(s.Data, data) {
+		t.Errorf("Expected stream data %q, got %v", data, s)
+	}
+	s := out.GetStream(pdf.Ref{Num: 5})
+	if s == nil || string(s.Data) != "short" {
+		t.Fatalf("Expected stream data %q, got %v", "short", s)
+	}
+	if n, _ := out.GetInt(s.Dict["Length"]); n != 5 {
+		t.Errorf("Expected /Length 5, got %d", n)
+	}
+}
+
+// serialize writes doc with opts.
+func serialize(t *testing.T, doc *pdf.Document, opts pdf.WriteOptions) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := doc.WriteWithOptions(&buf, opts); err != nil {
+		t.Fatalf("WriteWithOptions(%+v) failed: %v", opts, err)
+	}
+	return buf.Bytes()
+}
+
+func TestDocument_WriteIncremental(t *testing.T) {
+	classic := pdftest.BuildWithTrailer("/Info 4 0 R ", append(samplePages(1, ""), "<< /Title (Old) /Author (Kept) >>")...)
+	compressed := serialize(t, openPDF(t, classic), pdf.WriteOptions{ObjectStreams: true})
+
+	for name, original := range map[string][]byte{"classic": classic, "xref stream": compressed} {
+		t.Run(name, func(t *testing.T) {
+			doc := openPDF(t, original)
+			if err := doc.SetInfo(map[string]string{"Title": "New"}); err != nil {
+				t.Fatalf("SetInfo failed: %v", err)
+			}
+			added := doc.Add(pdf.String("added"))
+
+			data := serialize(t, doc, pdf.WriteOptions{Incremental: true})
+			if !bytes.HasPrefix(data, original) {
+				t.Fatal("Expected the original file to be kept as a prefix")
+			}
+			if report := pdf.Validate(bytes.NewReader(data), int64(len(data))); !report.Valid() {
+				t.Errorf("Expected a valid update, got %v", report.Findings)
+			}
+			out := openPDF(t, data)
+			info, err := out.Info()
+			if err != nil {
+				t.Fatalf("Info failed: %v", err)
+			}
+			if info["Title"] != "New" || info["Author"] != "Kept" {
+				t.Errorf("Unexpected Info %v", info)
+			}
+			if s, _ := out.GetString(added); s != "added" {
+				t.Errorf("Expected added object, got %q", s)
+			}
+			if pages, err := out.Pages(); err != nil || len(pages) != 1 {
+				t.Errorf("Expected 1 page, got %v, %v", pages, err)
+			}
+		})
+	}
+}
+
+func TestDocument_WriteIncremental_Unchanged(t *testing.T) {
+	original := pdftest.Build(samplePages(1, "")...)
+	data := serialize(t, openPDF(t, original), pdf.WriteOptions{Incremental: true})
+	if !bytes.Equal(data, original) {
+		t.Error("Expected an unchanged document to be copied as is")
+	}
+}
+
+func TestDocument_WriteIncremental_Errors(t *testing.T) {
+	doc := openPDF(t, pdftest.Build(samplePages(1, "")...))
+	if err := doc.WriteWithOptions(io.Discard, pdf.WriteOptions{Incremental: true, ObjectStreams: true}); err == nil {
+		t.Error("Expected an error combining object streams with an incremental update")
+	}
+	merged, err := pdf.Merge(doc)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if err := merged.WriteWithOptions(io.Discard, pdf.WriteOptions{Incremental: true}); err == nil {
+		t.Error("Expected an error for a document not read from a file")
+	}
+}
+
+// writeLargePDF writes a PDF of pages pages to path, each with an image of
+// imageSize random bytes, as found in scanned archives.
+func writeLargePDF(tb testing.TB, path string, pages, imageSize int) int64 {
+	tb.Helper()
+	objects := samplePages(pages, "")
+	for i := range pages {
+		objects[2+i] = fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im0 %d 0 R >> >> >>", pages+3+i)
+	}
+	rng := rand.New(rand.NewSource(1))
+	image := make([]byte, imageSize)
+	for range pages {
+		rng.Read(image)
+		objects = append(objects, streamObject("/Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray", image))
+	}
+	data := pdftest.BuildWithTrailer(fmt.Sprintf("/Info %d 0 R ", len(objects)+1), append(objects, "<< /Title (Scan) >>")...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		tb.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return int64(len(data))
+}
+
+func TestDocument_Object_LargeStreams(t *testing.T) {
+	// The data of streams is read again when needed instead of being kept,
+	// so reading every object of a 32 MB file needs far less memory than
+	// its size.
+	path := filepath.Join(t.TempDir(), "large.pdf")
+	writeLargePDF(t, path, 32, 1<<20)
+	doc, err := pdf.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer doc.Close()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	count, _ := doc.GetInt(doc.Trailer()["Size"])
+	for num := 1; num < int(count); num++ {
+		if _, err := doc.Object(num); err != nil {
+			t.Fatalf("Object failed: %v", err)
+		}
+	}
+	if _, err := doc.ContentHash(); err != nil {
+		t.Fatalf("ContentHash failed: %v", err)
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	if grown := int64(after.HeapAlloc) - int64(before.HeapAlloc); grown > 8<<20 {
+		t.Errorf("Expected memory use to stay bounded, grew by %d bytes", grown)
+	}
+	runtime.KeepAlive(doc)
+}
+
+// BenchmarkDocument_Write_LargeFile updates the title of a 64 MB file. The
+// "loaded" case reads every object first, as ContentHash does; memory use
+// stays bounded in every case since stream data is not kept once read and
+// is copied straight from the file when writing.
+func BenchmarkDocument_Write_LargeFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "large.pdf")
+	size := writeLargePDF(b, path, 64, 1<<20)
+
+	run := func(b *testing.B, loadAll bool, opts pdf.WriteOptions) {
+		b.SetBytes(size)
+		b.ReportAllocs()
+		for range b.N {
+			doc, err := pdf.Open(path)
+			if err != nil {
+				b.Fatalf("Open failed: %v", err)
+			}
+			if loadAll {
+				count, _ := doc.GetInt(doc.Trailer()["Size"])
+				for num := 1; num < int(count); num++ {
+					if _, err := doc.Object(num); err != nil {
+						b.Fatalf("Object failed: %v", err)
+					}
+				}
+			}
+			if err := doc.SetInfo(map[string]string{"Title": "Renamed"}); err != nil {
+				b.Fatalf("SetInfo failed: %v", err)
+			}
+			if err := doc.WriteWithOptions(io.Discard, opts); err != nil {
+				b.Fatalf("WriteWithOptions failed: %v", err)
+			}
+			doc.Close()
+		}
+	}
+	b.Run("loaded", func(b *testing.B) { run(b, true, pdf.WriteOptions{}) })
+	b.Run("rewrite", func(b *testing.B) { run(b, false, pdf.WriteOptions{}) })
+	b.Run("incremental", func(b *testing.B) { run(b, false, pdf.WriteOptions{Incremental: true}) })
+}
@@ -0,0 +1,372 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// XMP namespaces of the properties that mirror Info dictionary entries and
+// of the PDF/A identification.
+const (
+	nsRDF    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC     = "http://purl.org/dc/elements/1.1/"
+	nsPDF    = "http://ns.adobe.com/pdf/1.3/"
+	nsXMP    = "http://ns.adobe.com/xap/1.0/"
+	nsPDFAID = "http://www.aiim.org/pdfa/ns/id/"
+)
+
+// xmpPrefixes are the conventional prefixes used to name properties.
+var xmpPrefixes = map[string]string{
+	nsDC:     "dc",
+	nsPDF:    "pdf",
+	nsXMP:    "xmp",
+	nsPDFAID: "pdfaid",
+}
+
+// infoXMPProperties maps Info dictionary keys to the XMP properties that
+// hold the same value.
+var infoXMPProperties = []struct {
+	info, xmp string
+}{
+	{"Title", "dc:title"},
+	{"Author", "dc:creator"},
+	{"Subject", "dc:description"},
+	{"Keywords", "pdf:Keywords"},
+	{"Creator", "xmp:CreatorTool"},
+	{"Producer", "pdf:Producer"},
+	{"CreationDate", "xmp:CreateDate"},
+	{"ModDate", "xmp:ModifyDate"},
+}
+
+// parseXMP returns the simple properties of an XMP packet, named with the
+// prefixes of xmpPrefixes, such as "pdf:Producer". Language alternatives
+// yield their default entry and ordered lists their items joined by "; ".
+// Properties of other namespaces are named with their namespace URI.
+func parseXMP(data []byte) (map[string]string, error) {
+	props := map[string]string{}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	// Properties are the children of rdf:Description elements, or their
+	// attributes in the abbreviated syntax.
+	var inDescription int
+	var property string
+	var text strings.Builder
+	var items []string
+	var itemLang string
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return props, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: XMP: %v", ErrMalformed, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case t.Name.Space == nsRDF && t.Name.Local == "Description":
+				inDescription = depth
+				for _, attr := range t.Attr {
+					if name, ok := xmpName(attr.Name); ok {
+						props[name] = attr.Value
+					}
+				}
+			case inDescription > 0 && depth == inDescription+1:
+				property, _ = xmpName(t.Name)
+				text.Reset()
+				items = items[:0]
+			case property != "" && t.Name.Space == nsRDF && t.Name.Local == "li":
+				text.Reset()
+				itemLang = ""
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "lang" {
+						itemLang = attr.Value
+					}
+				}
+			}
+		case xml.CharData:
+			if property != "" {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch {
+			case property != "" && t.Name.Space == nsRDF && t.Name.Local == "li":
+				item := strings.TrimSpace(text.String())
+				if itemLang == "x-default" {
+					items = append([]string{item}, items...)
+				} else {
+					items = append(items, item)
+				}
+				text.Reset()
+			case property != "" && depth == inDescription+1:
+				switch {
+				case len(items) == 0:
+					props[property] = strings.TrimSpace(text.String())
+				case property == "dc:title" || property == "dc:description":
+					props[property] = items[0]
+				default:
+					props[property] = strings.Join(items, "; ")
+				}
+				property = ""
+			case depth == inDescription:
+				inDescription = 0
+			}
+			depth--
+		}
+	}
+}
+
+func xmpName(name xml.Name) (string, bool) {
+	if name.Space == "" || name.Space == nsRDF || name.Space == "xmlns" || name.Space == "http://www.w3.org/XML/1998/namespace" {
+		return "", false
+	}
+	if prefix, ok := xmpPrefixes[name.Space]; ok {
+		return prefix + ":" + name.Local, true
+	}
+	return name.Space + name.Local, true
+}
+
+// XMPMetadata returns the properties of the document's XMP metadata stream,
+// as parsed by parseXMP, or nil if the catalog has no /Metadata.
+func (d *Document) XMPMetadata() (map[string]string, error) {
+	catalog, _, err := d.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	s := d.GetStream(catalog["Metadata"])
+	if s == nil {
+		return nil, nil
+	}
+	data, err := d.DecodeStream(s)
+	if err != nil {
+		return nil, err
+	}
+	return parseXMP(data)
+}
+
+// emptyXMPPacket is the packet updateXMP starts from for documents without
+// XMP metadata.
+const emptyXMPPacket = `<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="">
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+var (
+	xmpTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	xmpAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
+)
+
+// updateXMP returns the XMP packet data with the properties props replaced,
+// leaving everything else as it was. props is keyed by the names parseXMP
+// returns; an empty value removes the property. The new values are written
+// into the first rdf:Description. Empty data yields a new packet.
+func updateXMP(data []byte, props map[string]string) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte(emptyXMPPacket)
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	var out bytes.Buffer
+
+	// The decoder runs in raw mode to keep the prefixes of the packet, so
+	// namespace declarations are tracked here.
+	namespaces := map[string]string{"xml": "http://www.w3.org/XML/1998/namespace"}
+	resolve := func(name xml.Name, element bool) (string, bool) {
+		if name.Space == "" && !element {
+			return "", false
+		}
+		return xmpName(xml.Name{Space: namespaces[name.Space], Local: name.Local})
+	}
+	rdfPrefix := func() string {
+		for prefix, uri := range namespaces {
+			if uri == nsRDF && prefix != "" {
+				return prefix
+			}
+		}
+		return "rdf"
+	}
+
+	depth, inDescription, skip := 0, 0, 0
+	written := false
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: XMP: %v", ErrMalformed, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					namespaces[attr.Name.Local] = attr.Value
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					namespaces[""] = attr.Value
+				}
+			}
+			name, _ := resolve(t.Name, true)
+			if inDescription > 0 && depth == inDescription {
+				if _, ok := props[name]; ok {
+					skip = 1
+					continue
+				}
+			}
+			depth++
+			isDescription := namespaces[t.Name.Space] == nsRDF && t.Name.Local == "Description"
+			if isDescription {
+				inDescription = depth
+				attrs := t.Attr[:0:0]
+				for _, attr := range t.Attr {
+					if name, ok := resolve(attr.Name, false); ok {
+						if _, replaced := props[name]; replaced {
+							continue
+						}
+					}
+					attrs = append(attrs, attr)
+				}
+				t.Attr = attrs
+			}
+			writeXMLStart(&out, t)
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			switch {
+			case depth == inDescription:
+				if !written {
+					writeXMPProperties(&out, props, rdfPrefix())
+					written = true
+				}
+				inDescription = 0
+			case !written && namespaces[t.Name.Space] == nsRDF && t.Name.Local == "RDF":
+				rdf := rdfPrefix()
+				fmt.Fprintf(&out, `<%s:Description %s:about="">`, rdf, rdf)
+				writeXMPProperties(&out, props, rdf)
+				fmt.Fprintf(&out, "</%s:Description>", rdf)
+				written = true
+			}
+			depth--
+			fmt.Fprintf(&out, "</%s>", qualifiedName(t.Name))
+		case xml.CharData:
+			if skip == 0 {
+				xmpTextEscaper.WriteString(&out, string(t))
+			}
+		case xml.Comment:
+			if skip == 0 {
+				fmt.Fprintf(&out, "<!--%s-->", t)
+			}
+		case xml.ProcInst:
+			fmt.Fprintf(&out, "<?%s %s?>", t.Target, t.Inst)
+		case xml.Directive:
+			fmt.Fprintf(&out, "<!%s>", t)
+		}
+	}
+	if !written {
+		return nil, fmt.Errorf("%w: XMP has no rdf:RDF element", ErrMalformed)
+	}
+	return out.Bytes(), nil
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func writeXMLStart(out *bytes.Buffer, t xml.StartElement) {
+	out.WriteString("<" + qualifiedName(t.Name))
+	for _, attr := range t.Attr {
+		fmt.Fprintf(out, ` %s="`, qualifiedName(attr.Name))
+		xmpAttrEscaper.WriteString(out, attr.Value)
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+// xmpContainers are the array types of the Dublin Core properties, which
+// XMP requires even for single values.
+var xmpContainers = map[string]string{
+	"dc:title":       "Alt",
+	"dc:description": "Alt",
+	"dc:rights":      "Alt",
+	"dc:creator":     "Seq",
+	"dc:date":        "Seq",
+	"dc:contributor": "Bag",
+	"dc:language":    "Bag",
+	"dc:publisher":   "Bag",
+	"dc:relation":    "Bag",
+	"dc:subject":     "Bag",
+	"dc:type":        "Bag",
+}
+
+// writableXMPProperty reports whether updateXMP can write the property
+// name, which must belong to a namespace of xmpPrefixes.
+func writableXMPProperty(name string) bool {
+	prefix, local, ok := strings.Cut(name, ":")
+	if !ok || local == "" {
+		return false
+	}
+	for _, p := range xmpPrefixes {
+		if p == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// writeXMPProperties writes the non-empty props as property elements that
+// declare their own namespace, using the arrays of xmpContainers.
+func writeXMPProperties(out *bytes.Buffer, props map[string]string, rdf string) {
+	names := make([]string, 0, len(props))
+	for name, value := range props {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, ":")
+		var uri string
+		for ns, p := range xmpPrefixes {
+			if p == prefix {
+				uri = ns
+			}
+		}
+		value := xmpTextEscaper.Replace(props[name])
+		fmt.Fprintf(out, "<%s xmlns:%s=\"%s\">", name, prefix, uri)
+		switch container := xmpContainers[name]; container {
+		case "Alt":
+			fmt.Fprintf(out, `<%[1]s:Alt><%[1]s:li xml:lang="x-default">%[2]s</%[1]s:li></%[1]s:Alt>`, rdf, value)
+		case "Seq", "Bag":
+			// parseXMP joins list items with "; ", so this is its inverse.
+			fmt.Fprintf(out, "<%s:%s>", rdf, container)
+			for _, item := range strings.Split(value, "; ") {
+				fmt.Fprintf(out, "<%[1]s:li>%[2]s</%[1]s:li>", rdf, item)
+			}
+			fmt.Fprintf(out, "</%s:%s>", rdf, container)
+		default:
+			out.WriteString(value)
+		}
+		fmt.Fprintf(out, "</%s>\n", name)
+	}
+}
@@ -0,0 +1,32 @@
+// Package pdftest builds small PDF files for tests.
+package pdftest
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Build returns a PDF with a classic cross-reference table whose objects
+// are the given bodies, numbered from 1. Object 1 must be the catalog.
+func Build(objects ...string) []byte {
+	return BuildWithTrailer("", objects...)
+}
+
+// BuildWithTrailer is Build with extra trailer entries, such as
+// "/Info 4 0 R ".
+func BuildWithTrailer(extraTrailer string, objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R %s>>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, extraTrailer, xref)
+	return buf.Bytes()
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/sidshirsat/pdfmod/internal/output"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// Error codes of API responses. Those shared with the command line tool
+// come from the output package.
+const (
+	CodeBadRequest            = "bad_request"
+	CodeOutsideRoot           = "outside_root"
+	CodeTooLarge              = "too_large"
+	CodeNotFound              = output.CodeNotFound
+	CodeExists                = output.CodeExists
+	CodeNotPDF                = output.CodeNotPDF
+	CodeMalformed             = output.CodeMalformed
+	CodeEncrypted             = output.CodeEncrypted
+	CodeUnsupportedFilter     = output.CodeUnsupportedFilter
+	CodeUnsupportedColorSpace = output.CodeUnsupportedColorSpace
+	CodePageOutOfRange        = output.CodePageOutOfRange
+	CodeInternal              = output.CodeInternal
+)
+
+// ErrorBody is the JSON body of a failed request.
+type ErrorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// apiError is an error with the status and code to report it with.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(message string) error {
+	return &apiError{http.StatusBadRequest, CodeBadRequest, message}
+}
+
+func errOutsideRoot(path string) error {
+	return &apiError{http.StatusForbidden, CodeOutsideRoot, fmt.Sprintf("%s is outside the root directory", path)}
+}
+
+// pdfErrors maps the errors of the pdf package to a status and code.
+var pdfErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{pdf.ErrNotPDF, http.StatusUnsupportedMediaType, CodeNotPDF},
+	{pdf.ErrEncrypted, http.StatusUnprocessableEntity, CodeEncrypted},
+	{pdf.ErrMalformed, http.StatusUnprocessableEntity, CodeMalformed},
+	{pdf.ErrUnsupportedFilter, http.StatusUnprocessableEntity, CodeUnsupportedFilter},
+	{pdf.ErrUnsupportedColorSpace, http.StatusUnprocessableEntity, CodeUnsupportedColorSpace},
+	{pdf.ErrPageOutOfRange, http.StatusBadRequest, CodePageOutOfRange},
+}
+
+// classify returns the status and code err is reported with.
+func classify(err error) (int, string) {
+	var api *apiError
+	if errors.As(err, &api) {
+		return api.status, api.code
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge, CodeTooLarge
+	}
+	for _, e := range pdfErrors {
+		if errors.Is(err, e.err) {
+			return e.status, e.code
+		}
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound, CodeNotFound
+	case errors.Is(err, fs.ErrExist):
+		return http.StatusConflict, CodeExists
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, code := classify(err)
+	var body ErrorBody
+	body.Error.Code = code
+	body.Error.Message = err.Error()
+	writeJSON(w, status, body)
+}
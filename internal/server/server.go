@@ -0,0 +1,397 @@
+// Package server exposes pdfmod operations over a local HTTP API. Every
+// path in a request is relative to a root directory, and requests cannot
+// reach files outside it.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/utils"
+)
+
+// Default request size limits.
+const (
+	DefaultMaxUpload = 64 << 20
+	maxJSONBody      = 1 << 20
+)
+
+// Server handles API requests with the same services the CLI uses.
+type Server struct {
+	// Root is the directory request paths are relative to.
+	Root     string
+	Files    file.FileHandler
+	Metadata pdf.PDFMetadataHandler
+	Pages    pdf.PageAssembler
+	// MaxUpload limits the size of uploaded files in bytes.
+	MaxUpload int64
+}
+
+func NewServer(root string, files file.FileHandler, metadata pdf.PDFMetadataHandler, pages pdf.PageAssembler) *Server {
+	return &Server{
+		Root:      root,
+		Files:     files,
+		Metadata:  metadata,
+		Pages:     pages,
+		MaxUpload: DefaultMaxUpload,
+	}
+}
+
+// Handler returns the routes of the API:
+//
+//	GET    /v1/files                  list the PDFs in the root, or in ?dir=
+//	POST   /v1/files?path=            upload a PDF, with ?overwrite=true to replace one
+//	GET    /v1/files/content?path=    download a PDF
+//	GET    /v1/metadata?path=         read the Info and XMP metadata
+//	PATCH  /v1/metadata?path=         set fields; an empty value removes one
+//	POST   /v1/rename                 {"path", "name"}
+//	POST   /v1/merge                  {"paths", "output", "overwrite"}
+//	POST   /v1/split                  {"path", "pages", "outputDir"}
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/files", s.listFiles)
+	mux.HandleFunc("POST /v1/files", s.uploadFile)
+	mux.HandleFunc("GET /v1/files/content", s.downloadFile)
+	mux.HandleFunc("GET /v1/metadata", s.getMetadata)
+	mux.HandleFunc("PATCH /v1/metadata", s.patchMetadata)
+	mux.HandleFunc("POST /v1/rename", s.rename)
+	mux.HandleFunc("POST /v1/merge", s.merge)
+	mux.HandleFunc("POST /v1/split", s.split)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &apiError{http.StatusNotFound, CodeNotFound, "no such endpoint"})
+	})
+	return mux
+}
+
+// FileEntry describes a PDF in a listing.
+type FileEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+func (s *Server) listFiles(w http.ResponseWriter, r *http.Request) {
+	dir, err := s.resolve(r.URL.Query().Get("dir"), true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	infos, err := s.Files.ListFiles(dir)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	entries := []FileEntry{}
+	for _, info := range infos {
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(info.Name()), ".pdf") {
+			continue
+		}
+		entries = append(entries, FileEntry{
+			Path:    s.relative(filepath.Join(dir, info.Name())),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) uploadFile(w http.ResponseWriter, r *http.Request) {
+	path, err := s.resolvePDF(r.URL.Query().Get("path"), false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	overwrite, _ := strconv.ParseBool(r.URL.Query().Get("overwrite"))
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		writeError(w, &apiError{http.StatusConflict, CodeExists, fmt.Sprintf("%s already exists", s.relative(path))})
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, s.MaxUpload)
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".pdfmod-upload-*.pdf")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// Check the upload parses before it replaces anything.
+	doc, err := pdf.Open(tmp.Name())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	doc.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"path": s.relative(path)})
+}
+
+func (s *Server) downloadFile(w http.ResponseWriter, r *http.Request) {
+	path, err := s.resolvePDF(r.URL.Query().Get("path"), true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), f)
+}
+
+func (s *Server) getMetadata(w http.ResponseWriter, r *http.Request) {
+	path, err := s.resolvePDF(r.URL.Query().Get("path"), true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	fields, err := s.Metadata.ReadMetadata(path)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fields)
+}
+
+func (s *Server) patchMetadata(w http.ResponseWriter, r *http.Request) {
+	path, err := s.resolvePDF(r.URL.Query().Get("path"), true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var fields map[string]string
+	if err := decodeJSON(w, r, &fields); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(fields) == 0 {
+		writeError(w, badRequest("no fields to set"))
+		return
+	}
+	if err := s.Metadata.WriteMetadata(path, fields); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.getMetadata(w, r)
+}
+
+// RenameRequest renames a PDF within its directory.
+type RenameRequest struct {
+	Path string `json:"path"`
+	// Name is the new name without the .pdf extension.
+	Name string `json:"name"`
+}
+
+func (s *Server) rename(w http.ResponseWriter, r *http.Request) {
+	var req RenameRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	path, err := s.resolvePDF(req.Path, true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, validate := range []utils.Validator{utils.NonEmpty, utils.MaxBytes(utils.MaxNameBytes), utils.FilenameChars} {
+		if err := validate(req.Name); err != nil {
+			writeError(w, badRequest("invalid name: "+err.Error()))
+			return
+		}
+	}
+	newPath, err := s.Files.RenameFile(path, req.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"path": s.relative(newPath)})
+}
+
+// MergeRequest combines the pages of PDFs into a new file. An existing
+// output file is only replaced when Overwrite is set.
+type MergeRequest struct {
+	Paths     []string `json:"paths"`
+	Output    string   `json:"output"`
+	Overwrite bool     `json:"overwrite,omitempty"`
+}
+
+func (s *Server) merge(w http.ResponseWriter, r *http.Request) {
+	var req MergeRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(req.Paths) == 0 {
+		writeError(w, badRequest("no paths to merge"))
+		return
+	}
+	paths := make([]string, len(req.Paths))
+	for i, p := range req.Paths {
+		var err error
+		if paths[i], err = s.resolvePDF(p, true); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	output, err := s.resolvePDF(req.Output, false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, err := os.Stat(output); err == nil && !req.Overwrite {
+		writeError(w, &apiError{http.StatusConflict, CodeExists, fmt.Sprintf("%s already exists", s.relative(output))})
+		return
+	}
+	if err := s.Pages.Merge(paths, output); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"path": s.relative(output)})
+}
+
+// SplitRequest writes parts of a PDF to new files, named as
+// PageAssembler.Split names them and replacing files of the same name. Each
+// entry of Pages is a page selection that becomes one part; without any
+// every page does.
+type SplitRequest struct {
+	Path      string   `json:"path"`
+	Pages     []string `json:"pages,omitempty"`
+	OutputDir string   `json:"outputDir,omitempty"`
+}
+
+func (s *Server) split(w http.ResponseWriter, r *http.Request) {
+	var req SplitRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	path, err := s.resolvePDF(req.Path, true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	outDir := filepath.Dir(path)
+	if req.OutputDir != "" {
+		if outDir, err = s.resolve(req.OutputDir, true); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	written, err := s.Pages.Split(path, outDir, req.Pages)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	paths := make([]string, len(written))
+	for i, p := range written {
+		paths[i] = s.relative(p)
+	}
+	writeJSON(w, http.StatusCreated, map[string][]string{"paths": paths})
+}
+
+// resolvePDF is resolve for paths that must name a .pdf file.
+func (s *Server) resolvePDF(path string, mustExist bool) (string, error) {
+	if path == "" {
+		return "", badRequest("path is required")
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+		return "", badRequest(fmt.Sprintf("%s is not a .pdf file", path))
+	}
+	return s.resolve(path, mustExist)
+}
+
+// resolve returns the absolute path of a request path, which is relative to
+// the root, failing when it leads outside the root, also by symbolic links.
+// When mustExist is false only the parent directory has to exist.
+func (s *Server) resolve(path string, mustExist bool) (string, error) {
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", errOutsideRoot(path)
+	}
+	root, err := filepath.EvalSymlinks(s.Root)
+	if err != nil {
+		return "", err
+	}
+	full := filepath.Join(root, filepath.FromSlash(path))
+	if !within(root, full) {
+		return "", errOutsideRoot(path)
+	}
+	check := full
+	if !mustExist {
+		check = filepath.Dir(full)
+	}
+	real, err := filepath.EvalSymlinks(check)
+	if err != nil {
+		return "", err
+	}
+	if !within(root, real) {
+		return "", errOutsideRoot(path)
+	}
+	return full, nil
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// relative returns path relative to the root with forward slashes.
+func (s *Server) relative(path string) string {
+	root, err := filepath.EvalSymlinks(s.Root)
+	if err != nil {
+		root = s.Root
+	}
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// decodeJSON reads a JSON request body of limited size into v.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+		return badRequest("invalid JSON body: " + err.Error())
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
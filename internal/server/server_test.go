@@ -0,0 +1,178 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+	"github.com/sidshirsat/pdfmod/internal/server"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+// minimalPDF returns a one-page PDF.
+func minimalPDF() []byte {
+	return pdftest.Build(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	)
+}
+
+type fixture struct {
+	root     string
+	metadata *mocks.MockPDFMetadataHandler
+	pages    *mocks.MockPageAssembler
+	server   *server.Server
+}
+
+// newFixture returns a server whose root holds a.pdf, with a real file
+// handler and mocked PDF services.
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.pdf"), minimalPDF(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{
+		root:     root,
+		metadata: mocks.NewMockPDFMetadataHandler(ctrl),
+		pages:    mocks.NewMockPageAssembler(ctrl),
+	}
+	f.server = server.NewServer(root, &file.FilePickerService{}, f.metadata, f.pages)
+	return f
+}
+
+// do sends a request and decodes the JSON response into out, if set.
+func (f *fixture) do(t *testing.T, method, target string, body []byte, out any) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	f.server.Handler().ServeHTTP(rec, httptest.NewRequest(method, target, bytes.NewReader(body)))
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+// expectError checks the status and code of a failed request.
+func (f *fixture) expectError(t *testing.T, method, target string, body []byte, status int, code string) {
+	t.Helper()
+	var resp server.ErrorBody
+	rec := f.do(t, method, target, body, &resp)
+	if rec.Code != status || resp.Error.Code != code {
+		t.Errorf("%s %s: expected %d %s, got %d %s (%s)", method, target, status, code, rec.Code, resp.Error.Code, resp.Error.Message)
+	}
+}
+
+func TestServer_Metadata(t *testing.T) {
+	f := newFixture(t)
+	path := filepath.Join(f.root, "a.pdf")
+	gomock.InOrder(
+		f.metadata.EXPECT().ReadMetadata(path).Return(map[string]string{"Title": "Old"}, nil),
+		f.metadata.EXPECT().WriteMetadata(path, map[string]string{"Title": "New", "Author": ""}).Return(nil),
+		f.metadata.EXPECT().ReadMetadata(path).Return(map[string]string{"Title": "New"}, nil),
+	)
+
+	var fields map[string]string
+	if rec := f.do(t, "GET", "/v1/metadata?path=a.pdf", nil, &fields); rec.Code != http.StatusOK || fields["Title"] != "Old" {
+		t.Errorf("expected the metadata, got %d %v", rec.Code, fields)
+	}
+	if rec := f.do(t, "PATCH", "/v1/metadata?path=a.pdf", []byte(`{"Title": "New", "Author": ""}`), &fields); rec.Code != http.StatusOK || fields["Title"] != "New" {
+		t.Errorf("expected the updated metadata, got %d %v", rec.Code, fields)
+	}
+	f.expectError(t, "PATCH", "/v1/metadata?path=a.pdf", []byte(`{"Title": 1}`), http.StatusBadRequest, server.CodeBadRequest)
+}
+
+func TestServer_ErrorMapping(t *testing.T) {
+	f := newFixture(t)
+	f.metadata.EXPECT().ReadMetadata(filepath.Join(f.root, "a.pdf")).Return(nil, fmt.Errorf("could not open PDF file: %w", pdf.ErrEncrypted))
+
+	f.expectError(t, "GET", "/v1/metadata?path=a.pdf", nil, http.StatusUnprocessableEntity, server.CodeEncrypted)
+	f.expectError(t, "GET", "/v1/metadata?path=missing.pdf", nil, http.StatusNotFound, server.CodeNotFound)
+	f.expectError(t, "GET", "/v1/metadata?path=a.txt", nil, http.StatusBadRequest, server.CodeBadRequest)
+	f.expectError(t, "GET", "/v1/unknown", nil, http.StatusNotFound, server.CodeNotFound)
+}
+
+func TestServer_Sandbox(t *testing.T) {
+	f := newFixture(t)
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "b.pdf"), minimalPDF(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(f.root, "link")); err != nil {
+		t.Skipf("symbolic links are not available: %v", err)
+	}
+
+	for _, target := range []string{
+		"/v1/metadata?path=../a.pdf",
+		"/v1/metadata?path=" + filepath.Join(outside, "b.pdf"),
+		"/v1/metadata?path=link/b.pdf",
+		"/v1/files?dir=link",
+	} {
+		f.expectError(t, "GET", target, nil, http.StatusForbidden, server.CodeOutsideRoot)
+	}
+	f.expectError(t, "POST", "/v1/files?path=link/new.pdf", minimalPDF(), http.StatusForbidden, server.CodeOutsideRoot)
+	f.expectError(t, "POST", "/v1/merge", []byte(`{"paths": ["a.pdf"], "output": "../out.pdf"}`), http.StatusForbidden, server.CodeOutsideRoot)
+}
+
+func TestServer_Files(t *testing.T) {
+	f := newFixture(t)
+	f.server.MaxUpload = 1024
+
+	f.expectError(t, "POST", "/v1/files?path=b.pdf", []byte("hello"), http.StatusUnsupportedMediaType, server.CodeNotPDF)
+	f.expectError(t, "POST", "/v1/files?path=b.pdf", bytes.Repeat([]byte("x"), 2048), http.StatusRequestEntityTooLarge, server.CodeTooLarge)
+	f.expectError(t, "POST", "/v1/files?path=a.pdf", minimalPDF(), http.StatusConflict, server.CodeExists)
+	if rec := f.do(t, "POST", "/v1/files?path=b.pdf", minimalPDF(), nil); rec.Code != http.StatusCreated {
+		t.Fatalf("expected the upload to succeed, got %d %s", rec.Code, rec.Body)
+	}
+
+	var entries []server.FileEntry
+	f.do(t, "GET", "/v1/files", nil, &entries)
+	if len(entries) != 2 || entries[0].Path != "a.pdf" || entries[1].Path != "b.pdf" {
+		t.Errorf("expected a.pdf and b.pdf, got %+v", entries)
+	}
+
+	rec := f.do(t, "GET", "/v1/files/content?path=b.pdf", nil, nil)
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), minimalPDF()) {
+		t.Errorf("expected the uploaded file, got %d", rec.Code)
+	}
+}
+
+func TestServer_RenameMergeSplit(t *testing.T) {
+	f := newFixture(t)
+	a := filepath.Join(f.root, "a.pdf")
+	f.pages.EXPECT().Merge([]string{a, a}, filepath.Join(f.root, "aa.pdf")).Return(nil)
+	f.pages.EXPECT().Split(a, f.root, []string{"1", "2-"}).Return([]string{filepath.Join(f.root, "a-1.pdf")}, fmt.Errorf("%w: page 2 of 1", pdf.ErrPageOutOfRange))
+
+	var resp map[string]any
+	if rec := f.do(t, "POST", "/v1/merge", []byte(`{"paths": ["a.pdf", "a.pdf"], "output": "aa.pdf"}`), &resp); rec.Code != http.StatusCreated || resp["path"] != "aa.pdf" {
+		t.Errorf("expected the merged file, got %d %v", rec.Code, resp)
+	}
+	f.expectError(t, "POST", "/v1/split", []byte(`{"path": "a.pdf", "pages": ["1", "2-"]}`), http.StatusBadRequest, server.CodePageOutOfRange)
+
+	f.expectError(t, "POST", "/v1/rename", []byte(`{"path": "a.pdf", "name": "../b"}`), http.StatusBadRequest, server.CodeBadRequest)
+	if rec := f.do(t, "POST", "/v1/rename", []byte(`{"path": "a.pdf", "name": "b"}`), &resp); rec.Code != http.StatusOK || resp["path"] != "b.pdf" {
+		t.Errorf("expected the renamed file, got %d %v", rec.Code, resp)
+	}
+	if _, err := os.Stat(filepath.Join(f.root, "b.pdf")); err != nil {
+		t.Errorf("expected b.pdf to exist: %v", err)
+	}
+	if !strings.Contains(f.do(t, "POST", "/v1/rename", []byte(`{"path": "a.pdf", "name": "c"}`), nil).Body.String(), server.CodeNotFound) {
+		t.Errorf("expected renaming a missing file to fail")
+	}
+}
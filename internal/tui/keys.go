@@ -0,0 +1,95 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyCode identifies a key that does not insert text.
+type KeyCode int
+
+// Keys the terminal UI responds to. KeyRune carries the typed character.
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEsc
+	KeyCtrlC
+	KeyCtrlU
+)
+
+// Key is a decoded key press.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// DecodeKeys splits terminal input into key presses. Escape sequences for
+// keys the UI does not use are dropped, and an escape byte that does not
+// start a sequence is reported as KeyEsc.
+func DecodeKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) > 1 && (b[1] == '[' || b[1] == 'O') {
+				n, key, ok := decodeSequence(b)
+				if ok {
+					keys = append(keys, key)
+				}
+				b = b[n:]
+				continue
+			}
+			keys = append(keys, Key{Code: KeyEsc})
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case c == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case c == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case c == 0x15:
+			keys = append(keys, Key{Code: KeyCtrlU})
+		case c < 0x20:
+			// Other control characters are ignored.
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// decodeSequence decodes a CSI or SS3 sequence such as "\x1b[A" or
+// "\x1b[1;5B", returning its length and the key it stands for.
+func decodeSequence(b []byte) (int, Key, bool) {
+	n := 2
+	for n < len(b) && b[n] >= 0x30 && b[n] <= 0x3f {
+		n++
+	}
+	if n == len(b) {
+		return n, Key{}, false
+	}
+	final := b[n]
+	n++
+	switch final {
+	case 'A':
+		return n, Key{Code: KeyUp}, true
+	case 'B':
+		return n, Key{Code: KeyDown}, true
+	case 'C':
+		return n, Key{Code: KeyRight}, true
+	case 'D':
+		return n, Key{Code: KeyLeft}, true
+	case 'Z':
+		// Shift-Tab moves between the panes like Tab.
+		return n, Key{Code: KeyTab}, true
+	}
+	return n, Key{}, false
+}
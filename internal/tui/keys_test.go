@@ -0,0 +1,27 @@
+package tui_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/tui"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []tui.Key
+	}{
+		{"aé", []tui.Key{{Code: tui.KeyRune, Rune: 'a'}, {Code: tui.KeyRune, Rune: 'é'}}},
+		{"\x1b[A\x1b[B\x1bOC\x1b[1;5D", []tui.Key{{Code: tui.KeyUp}, {Code: tui.KeyDown}, {Code: tui.KeyRight}, {Code: tui.KeyLeft}}},
+		{"\r\t\x7f\x03\x15", []tui.Key{{Code: tui.KeyEnter}, {Code: tui.KeyTab}, {Code: tui.KeyBackspace}, {Code: tui.KeyCtrlC}, {Code: tui.KeyCtrlU}}},
+		{"\x1b", []tui.Key{{Code: tui.KeyEsc}}},
+		// Unused sequences such as Delete are dropped.
+		{"\x1b[3~x", []tui.Key{{Code: tui.KeyRune, Rune: 'x'}}},
+	}
+	for _, tt := range tests {
+		if got := tui.DecodeKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DecodeKeys(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
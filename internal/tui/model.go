@@ -0,0 +1,517 @@
+// Package tui is a full-screen terminal interface for browsing PDFs and
+// editing their metadata.
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/utils"
+)
+
+// mode is what keys currently act on.
+type mode int
+
+const (
+	modeFiles mode = iota
+	modeSearch
+	modeFields
+	modeEdit
+	modeRename
+)
+
+// commonFields are listed for every file, set or not.
+var commonFields = []string{"Title", "Author", "Subject", "Keywords", "Creator", "Producer"}
+
+// change is an applied edit that can be undone.
+type change struct {
+	description string
+	revert      func() error
+}
+
+// Model holds the state of the terminal UI and handles key presses. Edits
+// to fields are kept pending until they are applied to the selected files,
+// or to the highlighted file when none is selected.
+type Model struct {
+	Files    file.FileHandler
+	Metadata pdf.PDFMetadataHandler
+	Dir      string
+	// Defaults are used as by the line-based flow: the producer is offered
+	// when editing the producer, the author is written along with edits and
+	// the rename template proposes new names.
+	Defaults manager.Defaults
+	// Sort orders the files, as for file.SortFiles.
+	Sort string
+
+	files    []os.FileInfo
+	shown    []os.FileInfo
+	cursor   int
+	selected map[string]bool
+	query    string
+
+	mode        mode
+	fieldCursor int
+	input       string
+	editField   string
+
+	pending map[string]string
+	cache   map[string]map[string]string
+	undo    []change
+	message string
+	quit    bool
+	done    bool
+}
+
+// NewModel lists the PDFs in dir, ordered by sortBy.
+func NewModel(files file.FileHandler, metadata pdf.PDFMetadataHandler, dir string, defaults manager.Defaults, sortBy string) (*Model, error) {
+	m := &Model{
+		Files:    files,
+		Metadata: metadata,
+		Dir:      dir,
+		Defaults: defaults,
+		Sort:     sortBy,
+		selected: map[string]bool{},
+		pending:  map[string]string{},
+	}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Done reports whether the user has quit.
+func (m *Model) Done() bool {
+	return m.done
+}
+
+// reload lists the PDFs again and forgets the metadata read so far.
+func (m *Model) reload() error {
+	list, err := m.Files.ListFiles(m.Dir)
+	if err != nil {
+		return err
+	}
+	m.files = m.files[:0]
+	for _, f := range list {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".pdf") {
+			m.files = append(m.files, f)
+		}
+	}
+	file.SortFiles(m.files, m.Sort)
+	m.cache = map[string]map[string]string{}
+	m.filter()
+	return nil
+}
+
+// filter shows the files whose name contains the search query.
+func (m *Model) filter() {
+	m.shown = m.shown[:0]
+	query := strings.ToLower(m.query)
+	for _, f := range m.files {
+		if strings.Contains(strings.ToLower(f.Name()), query) {
+			m.shown = append(m.shown, f)
+		}
+	}
+	m.cursor = max(0, min(m.cursor, len(m.shown)-1))
+}
+
+// current returns the name of the highlighted file, or "" when no file is
+// shown.
+func (m *Model) current() string {
+	if len(m.shown) == 0 {
+		return ""
+	}
+	return m.shown[m.cursor].Name()
+}
+
+// metadata returns the fields of the highlighted file.
+func (m *Model) metadata() (map[string]string, error) {
+	name := m.current()
+	if name == "" {
+		return nil, nil
+	}
+	if values, ok := m.cache[name]; ok {
+		return values, nil
+	}
+	values, err := m.Metadata.ReadMetadata(filepath.Join(m.Dir, name))
+	if err != nil {
+		return nil, err
+	}
+	m.cache[name] = values
+	return values, nil
+}
+
+// fieldNames returns the common fields, then the other Info entries and
+// XMP properties of the highlighted file and the pending edits.
+func (m *Model) fieldNames(values map[string]string) []string {
+	names := append([]string(nil), commonFields...)
+	var rest []string
+	for _, source := range []map[string]string{values, m.pending} {
+		for name := range source {
+			if !contains(names, name) && !contains(rest, name) {
+				rest = append(rest, name)
+			}
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		xmpI, xmpJ := strings.Contains(rest[i], ":"), strings.Contains(rest[j], ":")
+		if xmpI != xmpJ {
+			return xmpJ
+		}
+		return rest[i] < rest[j]
+	})
+	return append(names, rest...)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Update handles a key press.
+func (m *Model) Update(key Key) {
+	if key.Code == KeyCtrlC {
+		m.done = true
+		return
+	}
+	quit := m.quit
+	m.quit = false
+	switch m.mode {
+	case modeSearch:
+		m.updateSearch(key)
+	case modeEdit, modeRename:
+		m.updateInput(key)
+	default:
+		m.updateBrowse(key, quit)
+	}
+}
+
+func (m *Model) updateBrowse(key Key, quit bool) {
+	m.message = ""
+	if key.Code == KeyRune {
+		switch key.Rune {
+		case 'q':
+			if len(m.pending) > 0 && !quit {
+				m.quit = true
+				m.message = utils.Style("Edits are not applied. Press q again to quit.", utils.RoleWarn)
+				return
+			}
+			m.done = true
+			return
+		case 'a':
+			m.apply()
+			return
+		case 'u':
+			m.undoLast()
+			return
+		case 'r':
+			if name := m.current(); name != "" {
+				m.mode = modeRename
+				m.input = m.renameDefault(name)
+			}
+			return
+		}
+	}
+	if m.mode == modeFiles {
+		m.updateFiles(key)
+	} else {
+		m.updateFields(key)
+	}
+}
+
+func (m *Model) updateFiles(key Key) {
+	switch {
+	case key.Code == KeyUp || key.Code == KeyRune && key.Rune == 'k':
+		m.cursor = max(0, m.cursor-1)
+	case key.Code == KeyDown || key.Code == KeyRune && key.Rune == 'j':
+		m.cursor = max(0, min(m.cursor+1, len(m.shown)-1))
+	case key.Code == KeyRune && key.Rune == ' ':
+		if name := m.current(); name != "" {
+			if m.selected[name] {
+				delete(m.selected, name)
+			} else {
+				m.selected[name] = true
+			}
+		}
+	case key.Code == KeyRune && key.Rune == '/':
+		m.mode = modeSearch
+	case key.Code == KeyEsc:
+		if m.query != "" {
+			m.query = ""
+			m.filter()
+		} else {
+			m.selected = map[string]bool{}
+		}
+	case key.Code == KeyTab || key.Code == KeyRight || key.Code == KeyEnter:
+		if m.current() != "" {
+			m.mode = modeFields
+		}
+	}
+}
+
+func (m *Model) updateSearch(key Key) {
+	switch key.Code {
+	case KeyRune:
+		m.query += string(key.Rune)
+	case KeyBackspace:
+		m.query = dropLastRune(m.query)
+	case KeyCtrlU:
+		m.query = ""
+	case KeyEsc:
+		m.query = ""
+		m.mode = modeFiles
+	case KeyEnter, KeyDown, KeyTab:
+		m.mode = modeFiles
+	}
+	m.filter()
+}
+
+func (m *Model) updateFields(key Key) {
+	values, err := m.metadata()
+	if err != nil {
+		m.message = utils.Style(err.Error(), utils.RoleError)
+	}
+	names := m.fieldNames(values)
+	switch {
+	case key.Code == KeyUp || key.Code == KeyRune && key.Rune == 'k':
+		m.fieldCursor = max(0, m.fieldCursor-1)
+	case key.Code == KeyDown || key.Code == KeyRune && key.Rune == 'j':
+		m.fieldCursor = min(m.fieldCursor+1, len(names)-1)
+	case key.Code == KeyEnter || key.Code == KeyRune && key.Rune == 'e':
+		m.editField = names[m.fieldCursor]
+		m.input = m.value(values, m.editField)
+		if _, ok := m.pending[m.editField]; !ok && m.editField == "Producer" && m.Defaults.Producer != "" {
+			m.input = m.Defaults.Producer
+		}
+		m.mode = modeEdit
+	case key.Code == KeyRune && key.Rune == 'd':
+		m.setPending(values, names[m.fieldCursor], "")
+	case key.Code == KeyRune && key.Rune == 'c':
+		delete(m.pending, names[m.fieldCursor])
+	case key.Code == KeyTab || key.Code == KeyLeft || key.Code == KeyEsc:
+		m.mode = modeFiles
+	}
+}
+
+// value returns the pending value of a field, or else its current value.
+func (m *Model) value(values map[string]string, name string) string {
+	if v, ok := m.pending[name]; ok {
+		return v
+	}
+	return values[name]
+}
+
+// setPending records an edit, or drops it when the value is unchanged.
+func (m *Model) setPending(values map[string]string, name, value string) {
+	if value == values[name] {
+		delete(m.pending, name)
+		return
+	}
+	m.pending[name] = value
+}
+
+func (m *Model) updateInput(key Key) {
+	switch key.Code {
+	case KeyRune:
+		m.input += string(key.Rune)
+	case KeyBackspace:
+		m.input = dropLastRune(m.input)
+	case KeyCtrlU:
+		m.input = ""
+	case KeyEsc:
+		m.mode = m.returnMode()
+	case KeyEnter:
+		if m.mode == modeRename {
+			m.rename(strings.TrimSpace(m.input))
+			return
+		}
+		values, _ := m.metadata()
+		m.setPending(values, m.editField, m.input)
+		m.mode = modeFields
+	}
+}
+
+// renameDefault returns the name proposed for the file: the expanded rename
+// template, or else its current name, without extension.
+func (m *Model) renameDefault(name string) string {
+	if m.Defaults.RenameTemplate != "" {
+		values, _ := m.metadata()
+		if proposed := manager.ExpandTemplate(m.Defaults.RenameTemplate, name, values, time.Now()); proposed != "" {
+			return proposed
+		}
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// returnMode is the mode to go back to after editing a line.
+func (m *Model) returnMode() mode {
+	if m.mode == modeEdit {
+		return modeFields
+	}
+	return modeFiles
+}
+
+func dropLastRune(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	return string(r[:len(r)-1])
+}
+
+// targets returns the names of the selected files in list order, or the
+// highlighted file when none is selected.
+func (m *Model) targets() []string {
+	var names []string
+	for _, f := range m.files {
+		if m.selected[f.Name()] {
+			names = append(names, f.Name())
+		}
+	}
+	if len(names) == 0 && m.current() != "" {
+		names = append(names, m.current())
+	}
+	return names
+}
+
+// apply writes the pending edits, with the configured author, to the target
+// files and records how to restore the previous values.
+func (m *Model) apply() {
+	if len(m.pending) == 0 {
+		m.message = "No edits to apply."
+		return
+	}
+	edits := maps.Clone(m.pending)
+	if _, ok := edits["Author"]; !ok && m.Defaults.Author != "" {
+		edits["Author"] = m.Defaults.Author
+	}
+	targets := m.targets()
+	var reverts []func() error
+	revertAll := func() error {
+		for i := len(reverts) - 1; i >= 0; i-- {
+			if err := reverts[i](); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, name := range targets {
+		path := filepath.Join(m.Dir, name)
+		current, err := m.Metadata.ReadMetadata(path)
+		if err == nil {
+			changes, previous := map[string]string{}, map[string]string{}
+			for field, value := range edits {
+				if value != current[field] {
+					changes[field] = value
+					previous[field] = current[field]
+				}
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			if err = m.Metadata.WriteMetadata(path, changes); err == nil {
+				reverts = append(reverts, func() error { return m.Metadata.WriteMetadata(path, previous) })
+				continue
+			}
+		}
+		m.cache = map[string]map[string]string{}
+		if len(reverts) > 0 {
+			m.undo = append(m.undo, change{fmt.Sprintf("partial edit of %d file(s)", len(reverts)), revertAll})
+		}
+		m.message = utils.Style(fmt.Sprintf("%s: %v", name, err), utils.RoleError)
+		return
+	}
+	m.cache = map[string]map[string]string{}
+	fields := len(m.pending)
+	m.pending = map[string]string{}
+	if len(reverts) > 0 {
+		m.undo = append(m.undo, change{fmt.Sprintf("edit of %d file(s)", len(reverts)), revertAll})
+	}
+	m.message = utils.Style(fmt.Sprintf("Applied %d field(s) to %d file(s).", fields, len(targets)), utils.RoleSuccess)
+}
+
+// rename renames the highlighted file and records how to rename it back.
+func (m *Model) rename(newName string) {
+	for _, validate := range []utils.Validator{utils.NonEmpty, utils.MaxBytes(utils.MaxNameBytes), utils.FilenameChars} {
+		if err := validate(newName); err != nil {
+			m.message = utils.Style("Invalid name: "+err.Error()+".", utils.RoleError)
+			return
+		}
+	}
+	oldName := m.current()
+	oldBase := strings.TrimSuffix(oldName, filepath.Ext(oldName))
+	m.mode = modeFiles
+	if newName == oldBase {
+		return
+	}
+	newPath, err := m.Files.RenameFile(filepath.Join(m.Dir, oldName), newName)
+	if err != nil {
+		m.message = utils.Style(err.Error(), utils.RoleError)
+		return
+	}
+	m.undo = append(m.undo, change{"rename of " + oldName, func() error {
+		_, err := m.Files.RenameFile(newPath, oldBase)
+		return err
+	}})
+	if m.selected[oldName] {
+		delete(m.selected, oldName)
+		m.selected[filepath.Base(newPath)] = true
+	}
+	m.refresh(filepath.Base(newPath))
+	m.message = utils.Style("Renamed to "+filepath.Base(newPath)+".", utils.RoleSuccess)
+}
+
+// undoLast reverts the most recent change.
+func (m *Model) undoLast() {
+	if len(m.undo) == 0 {
+		m.message = "Nothing to undo."
+		return
+	}
+	last := m.undo[len(m.undo)-1]
+	m.undo = m.undo[:len(m.undo)-1]
+	if err := last.revert(); err != nil {
+		m.message = utils.Style(fmt.Sprintf("Could not undo %s: %v", last.description, err), utils.RoleError)
+		m.refresh(m.current())
+		return
+	}
+	m.refresh(m.current())
+	m.message = "Undid " + last.description + "."
+}
+
+// refresh lists the files again, keeping name highlighted when it is shown.
+func (m *Model) refresh(name string) {
+	if err := m.reload(); err != nil {
+		m.message = utils.Style(err.Error(), utils.RoleError)
+		return
+	}
+	for i, f := range m.shown {
+		if f.Name() == name {
+			m.cursor = i
+		}
+	}
+	for name := range m.selected {
+		if !m.exists(name) {
+			delete(m.selected, name)
+		}
+	}
+}
+
+func (m *Model) exists(name string) bool {
+	for _, f := range m.files {
+		if f.Name() == name {
+			return true
+		}
+	}
+	return false
+}
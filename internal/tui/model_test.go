@@ -0,0 +1,227 @@
+package tui_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/tui"
+	"github.com/sidshirsat/pdfmod/internal/utils"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+const dir = "/docs"
+
+// newModel returns a model listing the named files, with metadata reads
+// answered from meta.
+func newModel(t *testing.T, ctrl *gomock.Controller, names []string, meta map[string]map[string]string) (*tui.Model, *mocks.MockFileHandler, *mocks.MockPDFMetadataHandler) {
+	t.Helper()
+	return newModelWith(t, ctrl, names, meta, manager.Defaults{}, "")
+}
+
+// newModelWith is newModel with configured defaults and sort order. Each
+// file is an hour newer and 100 bytes larger than the one before.
+func newModelWith(t *testing.T, ctrl *gomock.Controller, names []string, meta map[string]map[string]string, defaults manager.Defaults, sortBy string) (*tui.Model, *mocks.MockFileHandler, *mocks.MockPDFMetadataHandler) {
+	t.Helper()
+	utils.SetColorEnabled(false)
+	t.Cleanup(func() { utils.SetColorEnabled(true) })
+
+	var files []os.FileInfo
+	for i, name := range names {
+		info := mocks.NewMockFileInfo(ctrl)
+		info.EXPECT().Name().Return(name).AnyTimes()
+		info.EXPECT().IsDir().Return(false).AnyTimes()
+		info.EXPECT().ModTime().Return(time.Date(2024, 1, 1, i, 0, 0, 0, time.UTC)).AnyTimes()
+		info.EXPECT().Size().Return(int64(100 * (i + 1))).AnyTimes()
+		files = append(files, info)
+	}
+	fileHandler := mocks.NewMockFileHandler(ctrl)
+	fileHandler.EXPECT().ListFiles(dir).Return(files, nil).AnyTimes()
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	metadata.EXPECT().ReadMetadata(gomock.Any()).DoAndReturn(func(path string) (map[string]string, error) {
+		values := map[string]string{}
+		for k, v := range meta[filepath.Base(path)] {
+			values[k] = v
+		}
+		return values, nil
+	}).AnyTimes()
+
+	m, err := tui.NewModel(fileHandler, metadata, dir, defaults, sortBy)
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+	return m, fileHandler, metadata
+}
+
+// press sends keys to the model, typing the runes of text strings.
+func press(m *tui.Model, keys ...interface{}) {
+	for _, k := range keys {
+		switch k := k.(type) {
+		case tui.KeyCode:
+			m.Update(tui.Key{Code: k})
+		case string:
+			for _, r := range k {
+				m.Update(tui.Key{Code: tui.KeyRune, Rune: r})
+			}
+		}
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m, _, _ := newModel(t, ctrl, []string{"b.pdf", "a.pdf", "notes.txt"}, map[string]map[string]string{
+		"a.pdf": {"Title": "Alpha", "dc:title": "Alpha"},
+	})
+
+	view := m.View(80, 12)
+	lines := strings.Split(view, "\n")
+	if len(lines) != 12 {
+		t.Fatalf("expected 12 lines, got %d", len(lines))
+	}
+	for _, want := range []string{"Files (2)", "> [ ] a.pdf", "  [ ] b.pdf", "Title    Alpha", "dc:title Alpha"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "notes.txt") {
+		t.Errorf("expected only PDFs in view:\n%s", view)
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != 80 {
+			t.Errorf("line %d has width %d: %q", i, n, line)
+		}
+	}
+}
+
+func TestModel_SearchAndSelect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m, _, _ := newModel(t, ctrl, []string{"report-2023.pdf", "report-2024.pdf", "invoice.pdf"}, nil)
+
+	press(m, "/", "rep", tui.KeyEnter, " ", "j", " ")
+	view := m.View(80, 12)
+	for _, want := range []string{"Files (2, 2 selected)  /rep", "[x] report-2023.pdf", "> [x] report-2024.pdf"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "invoice.pdf") {
+		t.Errorf("expected invoice.pdf to be filtered out:\n%s", view)
+	}
+
+	// Escape clears the filter, then the selection.
+	press(m, tui.KeyEsc)
+	if view := m.View(80, 12); !strings.Contains(view, "Files (3, 2 selected)") {
+		t.Errorf("expected the filter to be cleared:\n%s", view)
+	}
+	press(m, tui.KeyEsc)
+	if view := m.View(80, 12); !strings.Contains(view, "Files (3)") {
+		t.Errorf("expected the selection to be cleared:\n%s", view)
+	}
+}
+
+func TestModel_EditApplyUndo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m, _, metadata := newModel(t, ctrl, []string{"a.pdf", "b.pdf"}, map[string]map[string]string{
+		"a.pdf": {"Title": "Old A", "Author": "Ada"},
+		"b.pdf": {"Title": "Old B"},
+	})
+
+	gomock.InOrder(
+		metadata.EXPECT().WriteMetadata(filepath.Join(dir, "a.pdf"), map[string]string{"Title": "New", "Author": ""}).Return(nil),
+		metadata.EXPECT().WriteMetadata(filepath.Join(dir, "b.pdf"), map[string]string{"Title": "New"}).Return(nil),
+		// Undo restores the previous values in reverse order.
+		metadata.EXPECT().WriteMetadata(filepath.Join(dir, "b.pdf"), map[string]string{"Title": "Old B"}).Return(nil),
+		metadata.EXPECT().WriteMetadata(filepath.Join(dir, "a.pdf"), map[string]string{"Title": "Old A", "Author": "Ada"}).Return(nil),
+	)
+
+	// Select both files, replace the title and remove the author.
+	press(m, " ", "j", " ", "k", tui.KeyTab, tui.KeyEnter, tui.KeyCtrlU, "New", tui.KeyEnter, "j", "d")
+	view := m.View(80, 12)
+	for _, want := range []string{"Title   *New", "Author  *(removed)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	press(m, "a")
+	if view := m.View(80, 12); !strings.Contains(view, "Applied 2 field(s) to 2 file(s).") {
+		t.Errorf("expected the edits to be applied:\n%s", view)
+	}
+	press(m, "u")
+	if view := m.View(80, 12); !strings.Contains(view, "Undid edit of 2 file(s).") {
+		t.Errorf("expected the edits to be undone:\n%s", view)
+	}
+	press(m, "u")
+	if view := m.View(80, 12); !strings.Contains(view, "Nothing to undo.") {
+		t.Errorf("expected nothing left to undo:\n%s", view)
+	}
+}
+
+func TestModel_RenameAndUndo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m, fileHandler, _ := newModel(t, ctrl, []string{"scan.pdf"}, nil)
+
+	gomock.InOrder(
+		fileHandler.EXPECT().RenameFile(filepath.Join(dir, "scan.pdf"), "Report").Return(filepath.Join(dir, "Report.pdf"), nil),
+		fileHandler.EXPECT().RenameFile(filepath.Join(dir, "Report.pdf"), "scan").Return(filepath.Join(dir, "scan.pdf"), nil),
+	)
+
+	// An invalid name is rejected without renaming.
+	press(m, "r", tui.KeyCtrlU, "a/b", tui.KeyEnter)
+	if view := m.View(80, 12); !strings.Contains(view, "New name: a/b_") {
+		t.Errorf("expected to stay in the rename prompt:\n%s", view)
+	}
+	press(m, tui.KeyCtrlU, "Report", tui.KeyEnter, "u")
+	if view := m.View(80, 12); !strings.Contains(view, "Undid rename of scan.pdf.") {
+		t.Errorf("expected the rename to be undone:\n%s", view)
+	}
+}
+
+func TestModel_QuitWithPendingEdits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	m, _, _ := newModel(t, ctrl, []string{"a.pdf"}, nil)
+
+	press(m, tui.KeyTab, tui.KeyEnter, "Title", tui.KeyEnter, "q")
+	if m.Done() {
+		t.Fatal("expected q to ask for confirmation with pending edits")
+	}
+	press(m, "q")
+	if !m.Done() {
+		t.Fatal("expected a second q to quit")
+	}
+}
+
+func TestModel_Defaults(t *testing.T) {
+	// The configured sort order, producer, author and rename template are
+	// used as in the line-based flow.
+	ctrl := gomock.NewController(t)
+	defaults := manager.Defaults{Producer: "Example Corp", Author: "Ada", RenameTemplate: "{title}"}
+	m, _, metadata := newModelWith(t, ctrl, []string{"a.pdf", "b.pdf", "c.pdf"}, map[string]map[string]string{
+		"c.pdf": {"Title": "Annual Report"},
+	}, defaults, file.SortSize)
+
+	if view := m.View(80, 12); !strings.Contains(view, "> [ ] c.pdf") {
+		t.Errorf("expected the largest file first:\n%s", view)
+	}
+
+	metadata.EXPECT().WriteMetadata(filepath.Join(dir, "c.pdf"), map[string]string{"Producer": "Example Corp", "Author": "Ada"}).Return(nil)
+	// Edit the producer, the sixth field, accepting the configured one.
+	press(m, tui.KeyTab, "jjjjj", tui.KeyEnter)
+	if view := m.View(80, 12); !strings.Contains(view, "Example Corp_") {
+		t.Errorf("expected the configured producer to be offered:\n%s", view)
+	}
+	press(m, tui.KeyEnter, "a")
+	if view := m.View(80, 12); !strings.Contains(view, "Applied 1 field(s) to 1 file(s).") {
+		t.Errorf("expected the edit to be applied:\n%s", view)
+	}
+
+	press(m, tui.KeyTab, "r")
+	if view := m.View(80, 12); !strings.Contains(view, "New name: Annual Report_") {
+		t.Errorf("expected the rename template to propose the name:\n%s", view)
+	}
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// Run shows the model full screen on the terminal out, reading keys from
+// in, until the user quits. The terminal is restored before returning.
+func Run(in, out *os.File, m *Model) error {
+	restore, err := makeRaw(in)
+	if err != nil {
+		return fmt.Errorf("could not set up the terminal: %w", err)
+	}
+	defer restore()
+
+	w := bufio.NewWriter(out)
+	// Switch to the alternate screen and hide the cursor, and back on exit.
+	fmt.Fprint(w, "\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Fprint(w, "\033[?25h\033[?1049l")
+		w.Flush()
+	}()
+
+	resized := make(chan os.Signal, 1)
+	if len(resizeSignals) > 0 {
+		signal.Notify(resized, resizeSignals...)
+		defer signal.Stop(resized)
+	}
+	input := make(chan []byte)
+	errs := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				errs <- err
+				return
+			}
+			input <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+
+	for !m.Done() {
+		width, height, err := size(out)
+		if err != nil {
+			width, height = 80, 24
+		}
+		fmt.Fprint(w, "\033[H")
+		fmt.Fprint(w, strings.ReplaceAll(m.View(width, height), "\n", "\033[K\r\n"))
+		fmt.Fprint(w, "\033[K")
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		select {
+		case b := <-input:
+			for _, key := range DecodeKeys(b) {
+				m.Update(key)
+			}
+		case <-resized:
+		case err := <-errs:
+			return fmt.Errorf("could not read the terminal: %w", err)
+		}
+	}
+	return nil
+}
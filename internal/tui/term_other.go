@@ -0,0 +1,26 @@
+//go:build !linux && !darwin
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("the terminal UI is not supported on this system")
+
+// IsTerminal reports whether f is a terminal. Terminals are not detected on
+// this system, so the line-based flow is used.
+func IsTerminal(f *os.File) bool {
+	return false
+}
+
+func makeRaw(f *os.File) (func() error, error) {
+	return nil, errUnsupported
+}
+
+func size(f *os.File) (int, int, error) {
+	return 0, 0, errUnsupported
+}
+
+var resizeSignals []os.Signal
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sidshirsat/pdfmod/internal/utils"
+)
+
+// help lists the keys of each mode.
+var help = map[mode]string{
+	modeFiles:  "↑↓ move  space select  / search  tab fields  r rename  a apply  u undo  q quit",
+	modeSearch: "type to filter  enter done  esc clear",
+	modeFields: "↑↓ move  enter edit  d delete  c revert  tab files  a apply  u undo  q quit",
+	modeEdit:   "enter keep  esc cancel  ctrl-u clear",
+	modeRename: "enter rename  esc cancel  ctrl-u clear",
+}
+
+// View renders the screen as lines of at most width characters: a title,
+// the file list beside the fields of the highlighted file, a message line
+// and the key help.
+func (m *Model) View(width, height int) string {
+	width, height = max(width, 40), max(height, 6)
+	rows := height - 3
+	left := min(max(width*2/5, 20), 50)
+	right := width - left - 3
+
+	lines := []string{fit("pdfmod  "+m.Dir, width)}
+	files, fields := m.fileLines(rows), m.fieldLines(rows)
+	for i := 0; i < rows; i++ {
+		lines = append(lines, fit(files[i], left)+" │ "+fit(fields[i], right))
+	}
+
+	message := m.message
+	switch m.mode {
+	case modeRename:
+		message = "New name: " + m.input + "_"
+	case modeSearch:
+		message = "/" + m.query + "_"
+	}
+	lines = append(lines, fit(message, width), fit(help[m.mode], width))
+	return strings.Join(lines, "\n")
+}
+
+// fileLines renders the file list, scrolled to keep the cursor visible.
+func (m *Model) fileLines(rows int) []string {
+	lines := make([]string, rows)
+	header := fmt.Sprintf("Files (%d", len(m.shown))
+	if len(m.selected) > 0 {
+		header += fmt.Sprintf(", %d selected", len(m.selected))
+	}
+	header += ")"
+	if m.query != "" {
+		header += "  /" + m.query
+	}
+	lines[0] = header
+	if len(m.shown) == 0 {
+		lines[1] = "  no PDF files"
+		return lines
+	}
+	visible := rows - 1
+	top := max(0, m.cursor-visible+1)
+	for i := top; i < len(m.shown) && i-top < visible; i++ {
+		name := m.shown[i].Name()
+		line := "  "
+		if i == m.cursor {
+			line = "> "
+			if m.mode == modeFields || m.mode == modeEdit {
+				line = "* "
+			}
+		}
+		if m.selected[name] {
+			line += "[x] "
+		} else {
+			line += "[ ] "
+		}
+		lines[i-top+1] = line + name
+	}
+	return lines
+}
+
+// fieldLines renders the fields of the highlighted file with the pending
+// edits, marked with an asterisk.
+func (m *Model) fieldLines(rows int) []string {
+	lines := make([]string, rows)
+	name := m.current()
+	if name == "" {
+		return lines
+	}
+	lines[0] = strings.TrimSuffix(name, filepath.Ext(name))
+	values, err := m.metadata()
+	if err != nil {
+		lines[1] = utils.Style(err.Error(), utils.RoleError)
+		return lines
+	}
+	names := m.fieldNames(values)
+	labelWidth := 0
+	for _, n := range names {
+		labelWidth = max(labelWidth, len([]rune(n)))
+	}
+	visible := rows - 1
+	top := max(0, m.fieldCursor-visible+1)
+	for i := top; i < len(names) && i-top < visible; i++ {
+		field := names[i]
+		marker := "  "
+		if i == m.fieldCursor && (m.mode == modeFields || m.mode == modeEdit) {
+			marker = "> "
+		}
+		value := m.value(values, field)
+		_, edited := m.pending[field]
+		switch {
+		case m.mode == modeEdit && field == m.editField:
+			value = m.input + "_"
+		case edited && value == "":
+			value = "(removed)"
+		}
+		line := fmt.Sprintf("%s%-*s %s", marker, labelWidth, field, value)
+		if edited {
+			line = fmt.Sprintf("%s%-*s*%s", marker, labelWidth, field, value)
+		}
+		lines[i-top+1] = line
+	}
+	return lines
+}
+
+// fit truncates s to width characters or pads it with spaces. Escape codes
+// added by utils.Style do not count towards the width.
+func fit(s string, width int) string {
+	visible := 0
+	var b strings.Builder
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\033':
+			inEscape = true
+		case inEscape:
+			if r >= 0x40 && r <= 0x7e && r != '[' {
+				inEscape = false
+			}
+		default:
+			if visible == width {
+				continue
+			}
+			visible++
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + strings.Repeat(" ", width-visible)
+}
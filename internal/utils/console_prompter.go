@@ -2,13 +2,22 @@ package utils
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
+// ErrInputClosed is returned when the input ends before the user answers.
+// It wraps io.EOF.
+var ErrInputClosed = fmt.Errorf("input closed: %w", io.EOF)
+
+// ErrInterrupted is returned when the user interrupts a prompt.
+var ErrInterrupted = errors.New("interrupted")
+
 type Prompter interface {
-	PromptUser(prompt string) string
+	PromptUser(prompt string) (string, error)
 }
 
 // ConsolePrompter prompts the user for input via the console.
@@ -16,15 +25,68 @@ type ConsolePrompter struct {
 	Prompter Prompter
 }
 
-func (cp *ConsolePrompter) PromptUser(prompt string) string {
+func (cp *ConsolePrompter) PromptUser(prompt string) (string, error) {
 	return cp.Prompter.PromptUser(prompt)
 }
 
-type BasePrompter struct{}
+// BasePrompter reads answers line by line. In and Out default to standard
+// input and output.
+type BasePrompter struct {
+	In  io.Reader
+	Out io.Writer
+	// Interrupt, when set, aborts a pending prompt with ErrInterrupted when
+	// it receives, such as a channel passed to signal.Notify. The next
+	// prompt receives the line that was being read.
+	Interrupt <-chan os.Signal
+
+	reader  *bufio.Reader
+	pending chan line
+}
+
+// line is the result of reading one line of input.
+type line struct {
+	text string
+	err  error
+}
+
+// PromptUser writes the prompt and returns the trimmed line the user enters.
+// A final line without a newline is returned before ErrInputClosed.
+func (bp *BasePrompter) PromptUser(prompt string) (string, error) {
+	out := bp.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprint(out, prompt)
+	if bp.reader == nil {
+		in := bp.In
+		if in == nil {
+			in = os.Stdin
+		}
+		bp.reader = bufio.NewReader(in)
+	}
+
+	if bp.pending == nil {
+		bp.pending = make(chan line, 1)
+		go func(lines chan<- line) {
+			text, err := bp.reader.ReadString('\n')
+			lines <- line{text, err}
+		}(bp.pending)
+	}
 
-func (bp *BasePrompter) PromptUser(prompt string) string {
-	fmt.Print(prompt)
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	return strings.TrimSpace(input)
+	var l line
+	select {
+	case l = <-bp.pending:
+		bp.pending = nil
+	case <-bp.Interrupt:
+		fmt.Fprintln(out)
+		return "", ErrInterrupted
+	}
+	switch {
+	case l.err == io.EOF && l.text == "":
+		fmt.Fprintln(out)
+		return "", ErrInputClosed
+	case l.err != nil && l.err != io.EOF:
+		return "", fmt.Errorf("could not read input: %w", l.err)
+	}
+	return strings.TrimSpace(l.text), nil
 }
@@ -1,6 +1,9 @@
 package utils_test
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -21,9 +24,12 @@ func TestPromptUser(t *testing.T) {
 		Prompter: mockPrompter,
 	}
 
-	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("2").Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter the number of your choice: ").Return("2", nil).Times(1)
 
-	choice := consolePrompter.PromptUser("Enter the number of your choice: ")
+	choice, err := consolePrompter.PromptUser("Enter the number of your choice: ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if choice != "2" {
 		t.Fatalf("expected choice 2, got %s", choice)
 	}
@@ -40,9 +46,12 @@ func TestPromptUser_EmptyInput(t *testing.T) {
 		Prompter: mockPrompter,
 	}
 
-	mockPrompter.EXPECT().PromptUser("Enter something: ").Return("").Times(1)
+	mockPrompter.EXPECT().PromptUser("Enter something: ").Return("", nil).Times(1)
 
-	choice := consolePrompter.PromptUser("Enter something: ")
+	choice, err := consolePrompter.PromptUser("Enter something: ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if choice != "" {
 		t.Fatalf("expected empty choice, got %s", choice)
 	}
@@ -66,8 +75,51 @@ func TestBasePrompter_PromptUser(t *testing.T) {
 	prompter := &utils.BasePrompter{}
 
 	// Call the method and check the result
-	result := prompter.PromptUser("Enter something: ")
+	result, err := prompter.PromptUser("Enter something: ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if result != expected {
 		t.Fatalf("expected '%s', got '%s'", expected, result)
 	}
 }
+
+func TestBasePrompter_PromptUser_EOF(t *testing.T) {
+	var out bytes.Buffer
+	prompter := &utils.BasePrompter{In: strings.NewReader("first\nlast"), Out: &out}
+
+	for _, want := range []string{"first", "last"} {
+		got, err := prompter.PromptUser("> ")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+	if _, err := prompter.PromptUser("> "); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "> > > ") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestBasePrompter_PromptUser_Interrupt(t *testing.T) {
+	in, w := io.Pipe()
+	defer w.Close()
+	interrupt := make(chan os.Signal, 1)
+	prompter := &utils.BasePrompter{In: in, Out: io.Discard, Interrupt: interrupt}
+
+	interrupt <- os.Interrupt
+	if _, err := prompter.PromptUser("> "); !errors.Is(err, utils.ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+
+	// The line typed after the interrupt answers the next prompt.
+	go w.Write([]byte("again\n"))
+	got, err := prompter.PromptUser("> ")
+	if err != nil || got != "again" {
+		t.Fatalf("expected again, got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,46 @@
+package utils_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/utils"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := utils.NewLogger(&buf, "warn", "text")
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	logger.Info("hidden")
+	logger.Warn("shown", "file", "a.pdf")
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, `level=WARN msg=shown file=a.pdf`) {
+		t.Errorf("Unexpected text output %q", out)
+	}
+
+	buf.Reset()
+	logger, err = utils.NewLogger(&buf, "debug", "json")
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	logger.Debug("details", "attempt", 2)
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["level"] != "DEBUG" || line["msg"] != "details" || line["attempt"] != 2.0 {
+		t.Errorf("Unexpected JSON output %v", line)
+	}
+}
+
+func TestNewLogger_Errors(t *testing.T) {
+	if _, err := utils.NewLogger(&bytes.Buffer{}, "loud", "text"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if _, err := utils.NewLogger(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator checks an answer, returning an error that explains to the user
+// why it was rejected.
+type Validator func(answer string) error
+
+// NonEmpty rejects empty answers.
+func NonEmpty(answer string) error {
+	if answer == "" {
+		return errors.New("a value is required")
+	}
+	return nil
+}
+
+// MaxLength rejects answers longer than n characters.
+func MaxLength(n int) Validator {
+	return func(answer string) error {
+		if utf8.RuneCountInString(answer) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+// MaxNameBytes is the longest new file name, without its extension, that
+// is accepted. It leaves room for the extension within the 255 byte limit
+// most file systems place on names.
+const MaxNameBytes = 250
+
+// MaxBytes rejects answers longer than n bytes in UTF-8, which is how file
+// systems limit names.
+func MaxBytes(n int) Validator {
+	return func(answer string) error {
+		if len(answer) > n {
+			return fmt.Errorf("must be at most %d bytes, not %d", n, len(answer))
+		}
+		return nil
+	}
+}
+
+// FilenameChars rejects characters that are not allowed in file names on
+// common systems, such as path separators and control characters.
+func FilenameChars(answer string) error {
+	for _, r := range answer {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return fmt.Errorf("must not contain %q", r)
+		}
+	}
+	return nil
+}
+
+// Ask prompts with label until the answer passes every validator. When def
+// is not empty it is shown in brackets and used for an empty answer. Errors
+// from the prompter, such as ErrInputClosed, end the loop.
+func Ask(p Prompter, label, def string, validators ...Validator) (string, error) {
+	prompt := label + ": "
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", label, def)
+	}
+	for {
+		answer, err := p.PromptUser(prompt)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if err := validate(answer, validators); err != nil {
+			fmt.Println(Style("Invalid input: "+err.Error()+".", RoleError))
+			continue
+		}
+		return answer, nil
+	}
+}
+
+func validate(answer string, validators []Validator) error {
+	for _, v := range validators {
+		if err := v(answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Confirm asks a yes/no question until the answer is y, yes, n or no in any
+// case. An empty answer returns def.
+func Confirm(p Prompter, label string, def bool) (bool, error) {
+	prompt := label + " [y/N]: "
+	if def {
+		prompt = label + " [Y/n]: "
+	}
+	for {
+		answer, err := p.PromptUser(prompt)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Println(Style("Please answer yes or no.", RoleError))
+	}
+}
+
+// Choose lists the options, numbered from 1, and prompts with label until
+// the user enters one of the numbers. It returns the index of the chosen
+// option.
+func Choose(p Prompter, label string, options []string) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.New("no options to choose from")
+	}
+	for i, option := range options {
+		fmt.Printf("%d. %s\n", i+1, option)
+	}
+	for {
+		answer, err := p.PromptUser(label + ": ")
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Println(Style(fmt.Sprintf("Invalid choice %q. Please enter a number from 1 to %d.", answer, len(options)), RoleError))
+	}
+}
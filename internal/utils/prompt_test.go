@@ -0,0 +1,117 @@
+package utils_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/utils"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+func TestAsk_Default(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	mockPrompter.EXPECT().PromptUser("Name [report]: ").Return("", nil).Times(1)
+
+	got, err := utils.Ask(mockPrompter, "Name", "report", utils.NonEmpty)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "report" {
+		t.Fatalf("expected report, got %q", got)
+	}
+}
+
+func TestAsk_RepromptsOnInvalidInput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	gomock.InOrder(
+		mockPrompter.EXPECT().PromptUser("Name: ").Return("", nil),
+		mockPrompter.EXPECT().PromptUser("Name: ").Return("a/b", nil),
+		mockPrompter.EXPECT().PromptUser("Name: ").Return(strings.Repeat("x", 11), nil),
+		mockPrompter.EXPECT().PromptUser("Name: ").Return("ok", nil),
+	)
+
+	got, err := utils.Ask(mockPrompter, "Name", "", utils.NonEmpty, utils.MaxLength(10), utils.FilenameChars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "ok" {
+		t.Fatalf("expected ok, got %q", got)
+	}
+}
+
+func TestAsk_InputClosed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	mockPrompter.EXPECT().PromptUser("Name: ").Return("", utils.ErrInputClosed).Times(1)
+
+	if _, err := utils.Ask(mockPrompter, "Name", "", utils.NonEmpty); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator utils.Validator
+		answer    string
+		wantErr   bool
+	}{
+		{"non-empty", utils.NonEmpty, "x", false},
+		{"empty", utils.NonEmpty, "", true},
+		{"within length", utils.MaxLength(3), "äöü", false},
+		{"too long", utils.MaxLength(3), "abcd", true},
+		{"within bytes", utils.MaxBytes(4), "äö", false},
+		{"too many bytes", utils.MaxBytes(4), "äöü", true},
+		{"plain name", utils.FilenameChars, "Report (2024) - final", false},
+		{"separator", utils.FilenameChars, "a/b", true},
+		{"colon", utils.FilenameChars, "a:b", true},
+		{"control", utils.FilenameChars, "a\tb", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.validator(tt.answer); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	gomock.InOrder(
+		mockPrompter.EXPECT().PromptUser("Overwrite? [y/N]: ").Return("maybe", nil),
+		mockPrompter.EXPECT().PromptUser("Overwrite? [y/N]: ").Return("YES", nil),
+		mockPrompter.EXPECT().PromptUser("Continue? [Y/n]: ").Return("", nil),
+	)
+
+	if ok, err := utils.Confirm(mockPrompter, "Overwrite?", false); err != nil || !ok {
+		t.Fatalf("expected yes, got %v, %v", ok, err)
+	}
+	if ok, err := utils.Confirm(mockPrompter, "Continue?", true); err != nil || !ok {
+		t.Fatalf("expected the default yes, got %v, %v", ok, err)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPrompter := mocks.NewMockPrompter(ctrl)
+	gomock.InOrder(
+		mockPrompter.EXPECT().PromptUser("Pick: ").Return("3", nil),
+		mockPrompter.EXPECT().PromptUser("Pick: ").Return("two", nil),
+		mockPrompter.EXPECT().PromptUser("Pick: ").Return("2", nil),
+	)
+
+	got, err := utils.Choose(mockPrompter, "Pick", []string{"one", "two"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 1 {
+		t.Fatalf("expected index 1, got %d", got)
+	}
+}
@@ -1,10 +1,13 @@
 package utils
 
+import "os"
+
 const (
-	BlueText  = "\033[34m"
-	RedText   = "\033[31m"
-	GreenText = "\033[32m"
-	ResetText = "\033[0m"
+	BlueText   = "\033[34m"
+	RedText    = "\033[31m"
+	GreenText  = "\033[32m"
+	YellowText = "\033[33m"
+	ResetText  = "\033[0m"
 )
 
 // TextColor represents a text color.
@@ -12,13 +15,59 @@ type TextColor string
 
 // Text colors.
 const (
-	Blue  TextColor = BlueText
-	Red   TextColor = RedText
-	Green TextColor = GreenText
-	Reset TextColor = ResetText
+	Blue   TextColor = BlueText
+	Red    TextColor = RedText
+	Green  TextColor = GreenText
+	Yellow TextColor = YellowText
+	Reset  TextColor = ResetText
+)
+
+// Color modes, as accepted by the color setting.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
 )
 
-// Colorize returns the text with the specified color.
+// colorEnabled controls whether Colorize adds escape codes.
+var colorEnabled = true
+
+// SetColorEnabled turns colored output on or off.
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+}
+
+// ColorEnabled reports whether output written to out should be colored in
+// the given mode. "always" and "never" are taken as they are. In "auto"
+// mode output is colored only when out is a terminal, NO_COLOR is not set
+// and TERM is not "dumb"; getenv looks up the environment.
+func ColorEnabled(mode string, out *os.File, getenv func(string) string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if getenv("NO_COLOR") != "" || getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(out)
+}
+
+// IsTerminal reports whether f is a terminal rather than a file or a pipe.
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Colorize returns the text with the specified color, or the text alone
+// when color is disabled. Prefer Style, which colors text by its role.
 func Colorize(text string, color TextColor) string {
+	if !colorEnabled {
+		return text
+	}
 	return string(color) + text + string(Reset)
 }
@@ -1,6 +1,7 @@
 package utils_test
 
 import (
+	"os"
 	"testing"
 
 	"github.com/sidshirsat/pdfmod/internal/utils"
@@ -27,3 +28,80 @@ func TestColorize(t *testing.T) {
 		})
 	}
 }
+
+func TestColorize_Disabled(t *testing.T) {
+	utils.SetColorEnabled(false)
+	defer utils.SetColorEnabled(true)
+	if result := utils.Colorize("plain", utils.Red); result != "plain" {
+		t.Errorf("Colorize with color disabled = %q; want %q", result, "plain")
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	pipe, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer pipe.Close()
+	defer w.Close()
+
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	tests := []struct {
+		name string
+		mode string
+		env  map[string]string
+		want bool
+	}{
+		{"always", utils.ColorAlways, map[string]string{"NO_COLOR": "1"}, true},
+		{"never", utils.ColorNever, nil, false},
+		{"auto, not a terminal", utils.ColorAuto, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := utils.ColorEnabled(tt.mode, w, env(tt.env)); got != tt.want {
+				t.Errorf("ColorEnabled(%q) = %v; want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+
+	// On a terminal, auto mode still honors NO_COLOR and dumb terminals.
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no terminal available")
+	}
+	defer tty.Close()
+	if !utils.ColorEnabled(utils.ColorAuto, tty, env(nil)) {
+		t.Error("Expected color on a terminal")
+	}
+	for _, vars := range []map[string]string{{"NO_COLOR": "1"}, {"TERM": "dumb"}} {
+		if utils.ColorEnabled(utils.ColorAuto, tty, env(vars)) {
+			t.Errorf("Expected no color with %v", vars)
+		}
+	}
+}
+
+func TestStyle(t *testing.T) {
+	if got := utils.Style("Saved", utils.RoleSuccess); got != utils.GreenText+"Saved"+utils.ResetText {
+		t.Errorf("Style(RoleSuccess) = %q", got)
+	}
+	if got := utils.Style("Careful", utils.RoleWarn); got != utils.YellowText+"Careful"+utils.ResetText {
+		t.Errorf("Style(RoleWarn) = %q", got)
+	}
+
+	utils.SetTheme(utils.Theme{utils.RoleError: utils.Blue})
+	defer utils.SetTheme(utils.DefaultTheme())
+	if got := utils.Style("Failed", utils.RoleError); got != utils.BlueText+"Failed"+utils.ResetText {
+		t.Errorf("Style with a custom theme = %q", got)
+	}
+	if got := utils.Style("Saved", utils.RoleSuccess); got != "Saved" {
+		t.Errorf("Expected roles missing from the theme to be plain, got %q", got)
+	}
+
+	utils.SetColorEnabled(false)
+	defer utils.SetColorEnabled(true)
+	if got := utils.Style("Failed", utils.RoleError); got != "Failed" {
+		t.Errorf("Style with color disabled = %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+// Role is what a piece of output is for, which decides its color.
+type Role int
+
+// Roles of colored output.
+const (
+	// RoleSuccess marks a completed change.
+	RoleSuccess Role = iota
+	// RoleWarn marks something the user should notice before going on.
+	RoleWarn
+	// RoleError marks a failure or invalid input.
+	RoleError
+	// RolePrompt marks a question waiting for input.
+	RolePrompt
+	// RoleInfo highlights a value, such as the selected file.
+	RoleInfo
+)
+
+// Theme maps roles to colors. Roles missing from a theme are not colored.
+type Theme map[Role]TextColor
+
+// DefaultTheme returns the colors used unless SetTheme is called.
+func DefaultTheme() Theme {
+	return Theme{
+		RoleSuccess: Green,
+		RoleWarn:    Yellow,
+		RoleError:   Red,
+		RolePrompt:  Blue,
+		RoleInfo:    Blue,
+	}
+}
+
+var theme = DefaultTheme()
+
+// SetTheme replaces the colors of the roles.
+func SetTheme(t Theme) {
+	theme = t
+}
+
+// Style returns the text in the color of its role, or the text alone when
+// color is disabled or the theme has no color for the role.
+func Style(text string, role Role) string {
+	color, ok := theme[role]
+	if !ok {
+		return text
+	}
+	return Colorize(text, color)
+}
@@ -0,0 +1,51 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// inotify signals the files created in, written to or moved into a
+// directory. Events are coalesced: the channel holds at most one.
+type inotify struct {
+	f      *os.File
+	events chan struct{}
+}
+
+func newNotifier(dir string) (notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("could not set up inotify: %w", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, syscall.IN_CREATE|syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("could not watch %s: %w", dir, err)
+	}
+	// A non-blocking descriptor goes through the runtime poller, so Close
+	// interrupts the pending Read.
+	n := &inotify{f: os.NewFile(uintptr(fd), "inotify"), events: make(chan struct{}, 1)}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotify) read() {
+	buf := make([]byte, 4096)
+	for {
+		if _, err := n.f.Read(buf); err != nil {
+			return
+		}
+		select {
+		case n.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *inotify) Events() <-chan struct{} {
+	return n.events
+}
+
+func (n *inotify) Close() error {
+	return n.f.Close()
+}
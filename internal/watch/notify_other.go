@@ -0,0 +1,11 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+// newNotifier fails on systems without inotify, where the Watcher falls
+// back to polling.
+func newNotifier(dir string) (notifier, error) {
+	return nil, errors.New("file notifications are not supported on this system")
+}
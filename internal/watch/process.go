@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/file"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// Processor applies the configured steps to a file, in order: metadata
+// rules, a rename template and a move to an output directory. Steps that
+// are not configured are skipped.
+type Processor struct {
+	Metadata pdf.PDFMetadataHandler
+	Files    file.FileHandler
+	Rules    *manager.RulesEngine
+	// RenameTemplate names the file as manager.ExpandTemplate does.
+	RenameTemplate string
+	OutDir         string
+}
+
+// Process runs the steps on the file and returns its new path.
+func (p *Processor) Process(path string) (string, error) {
+	if p.Rules != nil {
+		report := p.Rules.Apply([]string{path}, false)
+		if msg := report.Files[0].Error; msg != "" {
+			return "", fmt.Errorf("could not apply rules: %s", msg)
+		}
+	}
+	// Reading the metadata also rejects files that are not PDFs before
+	// they are renamed or moved.
+	fields, err := p.Metadata.ReadMetadata(path)
+	if err != nil {
+		return "", err
+	}
+	if p.RenameTemplate != "" {
+		name := manager.ExpandTemplate(p.RenameTemplate, path, fields, time.Now())
+		if name != "" && name != strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) {
+			if path, err = p.Files.RenameFile(path, name); err != nil {
+				return "", err
+			}
+		}
+	}
+	if p.OutDir != "" {
+		if path, err = p.Files.MoveFile(path, p.OutDir); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
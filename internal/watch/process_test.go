@@ -0,0 +1,90 @@
+package watch_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sidshirsat/pdfmod/internal/manager"
+	"github.com/sidshirsat/pdfmod/internal/pdf"
+	"github.com/sidshirsat/pdfmod/internal/watch"
+	"github.com/sidshirsat/pdfmod/mocks"
+)
+
+func TestProcessor_Process(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	files := mocks.NewMockFileHandler(ctrl)
+	rules, err := manager.NewRulesEngine(metadata, &manager.RuleSet{Rules: []manager.Rule{
+		{Filename: `^scan-(\d+)`, Set: map[string]string{"Title": "Scan $1"}},
+	}})
+	if err != nil {
+		t.Fatalf("NewRulesEngine failed: %v", err)
+	}
+	gomock.InOrder(
+		metadata.EXPECT().ReadMetadata("/in/scan-7.pdf").Return(map[string]string{}, nil),
+		metadata.EXPECT().WriteMetadata("/in/scan-7.pdf", map[string]string{"Title": "Scan 7"}).Return(nil),
+		metadata.EXPECT().ReadMetadata("/in/scan-7.pdf").Return(map[string]string{"Title": "Scan 7", "Author": "Jane"}, nil),
+		files.EXPECT().RenameFile("/in/scan-7.pdf", "Scan 7 - Jane").Return("/in/Scan 7 - Jane.pdf", nil),
+		files.EXPECT().MoveFile("/in/Scan 7 - Jane.pdf", "/out").Return("/out/Scan 7 - Jane.pdf", nil),
+	)
+
+	p := &watch.Processor{
+		Metadata:       metadata,
+		Files:          files,
+		Rules:          rules,
+		RenameTemplate: "{title} - {author}",
+		OutDir:         "/out",
+	}
+	got, err := p.Process("/in/scan-7.pdf")
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if got != "/out/Scan 7 - Jane.pdf" {
+		t.Errorf("Expected the moved path, got %q", got)
+	}
+}
+
+func TestProcessor_Process_KeepsNameWithoutTemplateMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	files := mocks.NewMockFileHandler(ctrl)
+	// The template expands to nothing without a title, and the file keeps
+	// its name.
+	metadata.EXPECT().ReadMetadata("/in/scan.pdf").Return(map[string]string{}, nil)
+
+	p := &watch.Processor{Metadata: metadata, Files: files, RenameTemplate: "{title}"}
+	got, err := p.Process("/in/scan.pdf")
+	if err != nil || got != "/in/scan.pdf" {
+		t.Errorf("Process() = %q, %v, want the unchanged path", got, err)
+	}
+}
+
+func TestProcessor_Process_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metadata := mocks.NewMockPDFMetadataHandler(ctrl)
+	files := mocks.NewMockFileHandler(ctrl)
+	metadata.EXPECT().ReadMetadata("/in/broken.pdf").Return(nil, pdf.ErrNotPDF)
+
+	// A file that cannot be read is neither renamed nor moved.
+	p := &watch.Processor{Metadata: metadata, Files: files, RenameTemplate: "{title}", OutDir: "/out"}
+	if _, err := p.Process("/in/broken.pdf"); !errors.Is(err, pdf.ErrNotPDF) {
+		t.Errorf("Expected ErrNotPDF, got %v", err)
+	}
+
+	rules, _ := manager.NewRulesEngine(metadata, &manager.RuleSet{Rules: []manager.Rule{
+		{Set: map[string]string{"Producer": "Scanner"}},
+	}})
+	metadata.EXPECT().ReadMetadata("/in/locked.pdf").Return(map[string]string{}, nil)
+	metadata.EXPECT().WriteMetadata("/in/locked.pdf", gomock.Any()).Return(pdf.ErrEncrypted)
+	p = &watch.Processor{Metadata: metadata, Files: files, Rules: rules, OutDir: "/out"}
+	if _, err := p.Process("/in/locked.pdf"); err == nil {
+		t.Error("Expected an error when the rules cannot be applied")
+	}
+}
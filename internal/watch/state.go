@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is the outcome of processing a file.
+type Entry struct {
+	// Source is the path the file was found at.
+	Source string `json:"source"`
+	// Path is where the file ended up, unless processing failed.
+	Path      string    `json:"path,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Processed time.Time `json:"processed"`
+}
+
+// State records the files a Watcher handled in a JSON file, keyed by the
+// SHA-256 hash of their content before and after processing.
+type State struct {
+	path  string
+	mu    sync.Mutex
+	files map[string]Entry
+}
+
+// LoadState reads the state file at path. A missing file gives an empty
+// state, which is written on the first change.
+func LoadState(path string) (*State, error) {
+	s := &State{path: path, files: map[string]Entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Files map[string]Entry `json:"files"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("could not read state file %s: %w", path, err)
+	}
+	if file.Files != nil {
+		s.files = file.Files
+	}
+	return s, nil
+}
+
+// Lookup returns the entry of the file with the given hash.
+func (s *State) Lookup(sum string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.files[sum]
+	return e, ok
+}
+
+// Record stores e under each of the hashes and saves the state file. The
+// file is replaced in one step, so it is never left half written.
+func (s *State) Record(sums []string, e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sum := range sums {
+		s.files[sum] = e
+	}
+	data, err := json.MarshalIndent(map[string]any{"files": s.files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".pdfmod-state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
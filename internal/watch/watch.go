@@ -0,0 +1,218 @@
+// Package watch processes PDF files as they are added to a directory, such
+// as the output folder of a scanner.
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Defaults for the Watcher intervals.
+const (
+	DefaultSettle = 2 * time.Second
+	DefaultPoll   = 5 * time.Second
+)
+
+// Watcher processes the PDFs added to a directory once they are completely
+// written. Files are identified by the hash of their content in the State,
+// so files processed before a restart are skipped.
+type Watcher struct {
+	Dir string
+	// Process handles a file and returns its new path.
+	Process func(path string) (string, error)
+	State   *State
+	// Settle is how long a file must keep its size and modification time
+	// before it is taken to be completely written. It defaults to
+	// DefaultSettle.
+	Settle time.Duration
+	// Poll is the interval between scans of the directory. With file
+	// notifications the directory is also scanned on every change, and the
+	// scans catch events that were missed. It defaults to DefaultPoll.
+	Poll time.Duration
+	// Polling disables file notifications, which are otherwise used where
+	// the system supports them.
+	Polling bool
+	// Logger receives a message for each processed file. It defaults to
+	// the default logger.
+	Logger *slog.Logger
+}
+
+// notifier signals changes to a directory.
+type notifier interface {
+	Events() <-chan struct{}
+	Close() error
+}
+
+// fileKey tells apart versions of a file without reading it.
+type fileKey struct {
+	size    int64
+	modTime time.Time
+}
+
+// candidate is a file waiting to settle.
+type candidate struct {
+	key   fileKey
+	since time.Time
+}
+
+// Run watches the directory until ctx is done. A file being processed when
+// ctx is done is finished first.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.Settle <= 0 {
+		w.Settle = DefaultSettle
+	}
+	if w.Poll <= 0 {
+		w.Poll = DefaultPoll
+	}
+	if w.Logger == nil {
+		w.Logger = slog.Default()
+	}
+	if _, err := os.ReadDir(w.Dir); err != nil {
+		return err
+	}
+
+	var changes <-chan struct{}
+	if !w.Polling {
+		n, err := newNotifier(w.Dir)
+		if err != nil {
+			w.Logger.Info("polling for new files", "dir", w.Dir, "interval", w.Poll, "reason", err)
+		} else {
+			defer n.Close()
+			changes = n.Events()
+		}
+	}
+
+	pending := map[string]candidate{}
+	seen := map[string]fileKey{}
+	for {
+		w.scan(ctx, pending, seen)
+
+		wait := w.Poll
+		for _, c := range pending {
+			wait = min(wait, max(time.Until(c.since.Add(w.Settle)), 10*time.Millisecond))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-changes:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// scan lists the directory, tracking new and changed PDFs in pending until
+// they settle and processing them then. seen holds the files that were
+// handled, so they are only looked at again when they change.
+func (w *Watcher) scan(ctx context.Context, pending map[string]candidate, seen map[string]fileKey) {
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		w.Logger.Error("could not list the directory", "dir", w.Dir, "error", err)
+		return
+	}
+	now := time.Now()
+	present := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		// Hidden files include the temporary files written while saving.
+		if strings.HasPrefix(name, ".") || !entry.Type().IsRegular() || !strings.EqualFold(filepath.Ext(name), ".pdf") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(w.Dir, name)
+		present[path] = true
+		key := fileKey{size: info.Size(), modTime: info.ModTime()}
+		if k, ok := seen[path]; ok && k == key {
+			continue
+		}
+		if c, ok := pending[path]; !ok || c.key != key {
+			pending[path] = candidate{key: key, since: now}
+			continue
+		} else if now.Sub(c.since) < w.Settle {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		delete(pending, path)
+		seen[path] = key
+		w.handle(path, seen)
+	}
+	for path := range pending {
+		if !present[path] {
+			delete(pending, path)
+		}
+	}
+	for path := range seen {
+		if !present[path] {
+			delete(seen, path)
+		}
+	}
+}
+
+// handle processes a settled file unless the state lists it, and records
+// the outcome.
+func (w *Watcher) handle(path string, seen map[string]fileKey) {
+	logger := w.Logger.With("file", filepath.Base(path))
+	sum, err := hashFile(path)
+	if err != nil {
+		logger.Error("could not read the file", "error", err)
+		return
+	}
+	if entry, ok := w.State.Lookup(sum); ok {
+		if entry.Error == "" {
+			logger.Info("skipped, already processed")
+		} else {
+			logger.Info("skipped, failed before and unchanged")
+		}
+		return
+	}
+
+	entry := Entry{Source: path, Processed: time.Now().UTC()}
+	sums := []string{sum}
+	newPath, err := w.Process(path)
+	if err != nil {
+		entry.Error = err.Error()
+		logger.Error("processing failed", "error", err)
+	} else {
+		entry.Path = newPath
+		logger.Info("processed", "path", newPath)
+		// The processed file is recorded too, so it is not processed
+		// again when it stays in the directory.
+		if newSum, err := hashFile(newPath); err == nil {
+			sums = append(sums, newSum)
+		}
+		if info, err := os.Stat(newPath); err == nil && filepath.Dir(newPath) == filepath.Clean(w.Dir) {
+			seen[newPath] = fileKey{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	if err := w.State.Record(sums, entry); err != nil {
+		logger.Error("could not save the state", "error", err)
+	}
+}
+
+// hashFile returns the hex SHA-256 hash of the content of the file.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,241 @@
+package watch_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sidshirsat/pdfmod/internal/watch"
+)
+
+// recorder is a Process function that moves files to an output directory
+// and reports them on a channel.
+type recorder struct {
+	outDir    string
+	processed chan string
+}
+
+func newRecorder(t *testing.T) *recorder {
+	return &recorder{outDir: t.TempDir(), processed: make(chan string, 10)}
+}
+
+func (r *recorder) process(path string) (string, error) {
+	newPath := filepath.Join(r.outDir, filepath.Base(path))
+	if err := os.Rename(path, newPath); err != nil {
+		return "", err
+	}
+	r.processed <- filepath.Base(path)
+	return newPath, nil
+}
+
+// syncBuffer is a log destination that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// start runs w until the test ends.
+func start(t *testing.T, w *watch.Watcher) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run failed: %v", err)
+		}
+	})
+}
+
+func waitFor(t *testing.T, processed <-chan string) string {
+	t.Helper()
+	select {
+	case name := <-processed:
+		return name
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a file to be processed")
+		return ""
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestWatcher_ProcessesSettledPDFs(t *testing.T) {
+	for _, polling := range []bool{true, false} {
+		name := "notify"
+		if polling {
+			name = "polling"
+		}
+		t.Run(name, func(t *testing.T) {
+			if !polling && runtime.GOOS != "linux" {
+				t.Skip("file notifications are only supported on Linux")
+			}
+			dir := t.TempDir()
+			state, err := watch.LoadState(filepath.Join(t.TempDir(), "state.json"))
+			if err != nil {
+				t.Fatalf("LoadState failed: %v", err)
+			}
+			rec := newRecorder(t)
+			poll := 20 * time.Millisecond
+			if !polling {
+				// Only a notification can trigger the scan in time.
+				poll = time.Hour
+			}
+			start(t, &watch.Watcher{
+				Dir:     dir,
+				Process: rec.process,
+				State:   state,
+				Settle:  50 * time.Millisecond,
+				Poll:    poll,
+				Polling: polling,
+				Logger:  slog.New(slog.NewTextHandler(&syncBuffer{}, nil)),
+			})
+
+			writeFile(t, filepath.Join(dir, "notes.txt"), "not a PDF")
+			writeFile(t, filepath.Join(dir, ".hidden.pdf"), "temporary")
+			writeFile(t, filepath.Join(dir, "scan.PDF"), "%PDF-1.4")
+			if got := waitFor(t, rec.processed); got != "scan.PDF" {
+				t.Errorf("Expected scan.PDF to be processed, got %s", got)
+			}
+			select {
+			case name := <-rec.processed:
+				t.Errorf("Expected only PDFs to be processed, got %s", name)
+			case <-time.After(200 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestWatcher_WaitsForWritesToSettle(t *testing.T) {
+	dir := t.TempDir()
+	state, _ := watch.LoadState(filepath.Join(t.TempDir(), "state.json"))
+	rec := newRecorder(t)
+	start(t, &watch.Watcher{
+		Dir: dir, Process: rec.process, State: state,
+		Settle: 300 * time.Millisecond, Poll: 20 * time.Millisecond, Polling: true,
+		Logger: slog.New(slog.NewTextHandler(&syncBuffer{}, nil)),
+	})
+
+	path := filepath.Join(dir, "scan.pdf")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	var lastWrite time.Time
+	for range 5 {
+		time.Sleep(100 * time.Millisecond)
+		f.WriteString("page\n")
+		lastWrite = time.Now()
+	}
+	f.Close()
+
+	waitFor(t, rec.processed)
+	if elapsed := time.Since(lastWrite); elapsed < 300*time.Millisecond {
+		t.Errorf("Expected processing to wait for writes to settle, processed %s after the last write", elapsed)
+	}
+	data, _ := os.ReadFile(filepath.Join(rec.outDir, "scan.pdf"))
+	if got := strings.Count(string(data), "page"); got != 5 {
+		t.Errorf("Expected the complete file, got %d pages", got)
+	}
+}
+
+func TestWatcher_StateSkipsProcessedFiles(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	logs := &syncBuffer{}
+
+	// The first run leaves the processed file where it is and fails on
+	// another.
+	state, _ := watch.LoadState(statePath)
+	processed := make(chan string, 10)
+	w := &watch.Watcher{
+		Dir: dir, State: state,
+		Settle: 10 * time.Millisecond, Poll: 10 * time.Millisecond, Polling: true,
+		Logger: slog.New(slog.NewTextHandler(logs, nil)),
+		Process: func(path string) (string, error) {
+			processed <- filepath.Base(path)
+			if filepath.Base(path) == "bad.pdf" {
+				return "", os.ErrPermission
+			}
+			return path, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	writeFile(t, filepath.Join(dir, "good.pdf"), "good")
+	writeFile(t, filepath.Join(dir, "bad.pdf"), "bad")
+	waitFor(t, processed)
+	waitFor(t, processed)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// A restarted watcher skips both, but processes a changed file.
+	state, err := watch.LoadState(statePath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	rec := newRecorder(t)
+	start(t, &watch.Watcher{
+		Dir: dir, Process: rec.process, State: state,
+		Settle: 10 * time.Millisecond, Poll: 10 * time.Millisecond, Polling: true,
+		Logger: slog.New(slog.NewTextHandler(logs, nil)),
+	})
+	time.Sleep(200 * time.Millisecond)
+	writeFile(t, filepath.Join(dir, "bad.pdf"), "fixed")
+	if got := waitFor(t, rec.processed); got != "bad.pdf" {
+		t.Errorf("Expected only the changed file to be processed, got %s", got)
+	}
+	for _, want := range []string{
+		`msg="skipped, already processed" file=good.pdf`,
+		`msg="skipped, failed before and unchanged" file=bad.pdf`,
+		`msg="processing failed" file=bad.pdf error="permission denied"`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("Expected the log to contain %q, got:\n%s", want, logs)
+		}
+	}
+}
+
+func TestWatcher_MissingDirectory(t *testing.T) {
+	state, _ := watch.LoadState(filepath.Join(t.TempDir(), "state.json"))
+	w := &watch.Watcher{Dir: filepath.Join(t.TempDir(), "missing"), State: state}
+	if err := w.Run(context.Background()); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestLoadState_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	writeFile(t, path, "{")
+	if _, err := watch.LoadState(path); err == nil {
+		t.Error("Expected an error for an invalid state file")
+	}
+}
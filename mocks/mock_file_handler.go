@@ -49,6 +49,21 @@ func (mr *MockFileHandlerMockRecorder) ListFiles(dir interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockFileHandler)(nil).ListFiles), dir)
 }
 
+// MoveFile mocks base method.
+func (m *MockFileHandler) MoveFile(filePath, dir string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", filePath, dir)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFile indicates an expected call of MoveFile.
+func (mr *MockFileHandlerMockRecorder) MoveFile(filePath, dir interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockFileHandler)(nil).MoveFile), filePath, dir)
+}
+
 // RenameFile mocks base method.
 func (m *MockFileHandler) RenameFile(filePath, newName string) (string, error) {
 	m.ctrl.T.Helper()
@@ -78,3 +93,18 @@ func (mr *MockFileHandlerMockRecorder) SelectFile(files interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectFile", reflect.TypeOf((*MockFileHandler)(nil).SelectFile), files)
 }
+
+// SelectFiles mocks base method.
+func (m *MockFileHandler) SelectFiles(files []os.FileInfo) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectFiles", files)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectFiles indicates an expected call of SelectFiles.
+func (mr *MockFileHandlerMockRecorder) SelectFiles(files interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectFiles", reflect.TypeOf((*MockFileHandler)(nil).SelectFiles), files)
+}
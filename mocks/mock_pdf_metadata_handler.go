@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	pdf "github.com/sidshirsat/pdfmod/internal/pdf"
 )
 
 // MockPDFMetadataHandler is a mock of PDFMetadataHandler interface.
@@ -33,16 +34,603 @@ func (m *MockPDFMetadataHandler) EXPECT() *MockPDFMetadataHandlerMockRecorder {
 	return m.recorder
 }
 
+// ReadMetadata mocks base method.
+func (m *MockPDFMetadataHandler) ReadMetadata(filePath string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMetadata", filePath)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadMetadata indicates an expected call of ReadMetadata.
+func (mr *MockPDFMetadataHandlerMockRecorder) ReadMetadata(filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMetadata", reflect.TypeOf((*MockPDFMetadataHandler)(nil).ReadMetadata), filePath)
+}
+
 // UpdateMetadata mocks base method.
-func (m *MockPDFMetadataHandler) UpdateMetadata(filePath, title, producer string) error {
+func (m *MockPDFMetadataHandler) UpdateMetadata(filePath string, fields map[string]string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateMetadata", filePath, title, producer)
+	ret := m.ctrl.Call(m, "UpdateMetadata", filePath, fields)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateMetadata indicates an expected call of UpdateMetadata.
-func (mr *MockPDFMetadataHandlerMockRecorder) UpdateMetadata(filePath, title, producer interface{}) *gomock.Call {
+func (mr *MockPDFMetadataHandlerMockRecorder) UpdateMetadata(filePath, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockPDFMetadataHandler)(nil).UpdateMetadata), filePath, fields)
+}
+
+// WriteMetadata mocks base method.
+func (m *MockPDFMetadataHandler) WriteMetadata(filePath string, fields map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteMetadata", filePath, fields)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteMetadata indicates an expected call of WriteMetadata.
+func (mr *MockPDFMetadataHandlerMockRecorder) WriteMetadata(filePath, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteMetadata", reflect.TypeOf((*MockPDFMetadataHandler)(nil).WriteMetadata), filePath, fields)
+}
+
+// MockOutlineHandler is a mock of OutlineHandler interface.
+type MockOutlineHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutlineHandlerMockRecorder
+}
+
+// MockOutlineHandlerMockRecorder is the mock recorder for MockOutlineHandler.
+type MockOutlineHandlerMockRecorder struct {
+	mock *MockOutlineHandler
+}
+
+// NewMockOutlineHandler creates a new mock instance.
+func NewMockOutlineHandler(ctrl *gomock.Controller) *MockOutlineHandler {
+	mock := &MockOutlineHandler{ctrl: ctrl}
+	mock.recorder = &MockOutlineHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutlineHandler) EXPECT() *MockOutlineHandlerMockRecorder {
+	return m.recorder
+}
+
+// ReadOutline mocks base method.
+func (m *MockOutlineHandler) ReadOutline(filePath string) ([]*pdf.OutlineItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOutline", filePath)
+	ret0, _ := ret[0].([]*pdf.OutlineItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOutline indicates an expected call of ReadOutline.
+func (mr *MockOutlineHandlerMockRecorder) ReadOutline(filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOutline", reflect.TypeOf((*MockOutlineHandler)(nil).ReadOutline), filePath)
+}
+
+// WriteOutline mocks base method.
+func (m *MockOutlineHandler) WriteOutline(filePath string, items []*pdf.OutlineItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteOutline", filePath, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteOutline indicates an expected call of WriteOutline.
+func (mr *MockOutlineHandlerMockRecorder) WriteOutline(filePath, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteOutline", reflect.TypeOf((*MockOutlineHandler)(nil).WriteOutline), filePath, items)
+}
+
+// MockPageLabelHandler is a mock of PageLabelHandler interface.
+type MockPageLabelHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockPageLabelHandlerMockRecorder
+}
+
+// MockPageLabelHandlerMockRecorder is the mock recorder for MockPageLabelHandler.
+type MockPageLabelHandlerMockRecorder struct {
+	mock *MockPageLabelHandler
+}
+
+// NewMockPageLabelHandler creates a new mock instance.
+func NewMockPageLabelHandler(ctrl *gomock.Controller) *MockPageLabelHandler {
+	mock := &MockPageLabelHandler{ctrl: ctrl}
+	mock.recorder = &MockPageLabelHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPageLabelHandler) EXPECT() *MockPageLabelHandlerMockRecorder {
+	return m.recorder
+}
+
+// ReadPageLabels mocks base method.
+func (m *MockPageLabelHandler) ReadPageLabels(filePath string) ([]pdf.PageLabelRange, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPageLabels", filePath)
+	ret0, _ := ret[0].([]pdf.PageLabelRange)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadPageLabels indicates an expected call of ReadPageLabels.
+func (mr *MockPageLabelHandlerMockRecorder) ReadPageLabels(filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPageLabels", reflect.TypeOf((*MockPageLabelHandler)(nil).ReadPageLabels), filePath)
+}
+
+// WritePageLabels mocks base method.
+func (m *MockPageLabelHandler) WritePageLabels(filePath string, ranges []pdf.PageLabelRange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WritePageLabels", filePath, ranges)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WritePageLabels indicates an expected call of WritePageLabels.
+func (mr *MockPageLabelHandlerMockRecorder) WritePageLabels(filePath, ranges interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WritePageLabels", reflect.TypeOf((*MockPageLabelHandler)(nil).WritePageLabels), filePath, ranges)
+}
+
+// MockTextExtractor is a mock of TextExtractor interface.
+type MockTextExtractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTextExtractorMockRecorder
+}
+
+// MockTextExtractorMockRecorder is the mock recorder for MockTextExtractor.
+type MockTextExtractorMockRecorder struct {
+	mock *MockTextExtractor
+}
+
+// NewMockTextExtractor creates a new mock instance.
+func NewMockTextExtractor(ctrl *gomock.Controller) *MockTextExtractor {
+	mock := &MockTextExtractor{ctrl: ctrl}
+	mock.recorder = &MockTextExtractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTextExtractor) EXPECT() *MockTextExtractorMockRecorder {
+	return m.recorder
+}
+
+// ExtractText mocks base method.
+func (m *MockTextExtractor) ExtractText(filePath, selection string) ([]pdf.PageText, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtractText", filePath, selection)
+	ret0, _ := ret[0].([]pdf.PageText)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtractText indicates an expected call of ExtractText.
+func (mr *MockTextExtractorMockRecorder) ExtractText(filePath, selection interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractText", reflect.TypeOf((*MockTextExtractor)(nil).ExtractText), filePath, selection)
+}
+
+// MockInfoHandler is a mock of InfoHandler interface.
+type MockInfoHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockInfoHandlerMockRecorder
+}
+
+// MockInfoHandlerMockRecorder is the mock recorder for MockInfoHandler.
+type MockInfoHandlerMockRecorder struct {
+	mock *MockInfoHandler
+}
+
+// NewMockInfoHandler creates a new mock instance.
+func NewMockInfoHandler(ctrl *gomock.Controller) *MockInfoHandler {
+	mock := &MockInfoHandler{ctrl: ctrl}
+	mock.recorder = &MockInfoHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInfoHandler) EXPECT() *MockInfoHandlerMockRecorder {
+	return m.recorder
+}
+
+// ReadInfo mocks base method.
+func (m *MockInfoHandler) ReadInfo(filePath string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadInfo", filePath)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadInfo indicates an expected call of ReadInfo.
+func (mr *MockInfoHandlerMockRecorder) ReadInfo(filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadInfo", reflect.TypeOf((*MockInfoHandler)(nil).ReadInfo), filePath)
+}
+
+// WriteInfo mocks base method.
+func (m *MockInfoHandler) WriteInfo(filePath string, fields map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteInfo", filePath, fields)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteInfo indicates an expected call of WriteInfo.
+func (mr *MockInfoHandlerMockRecorder) WriteInfo(filePath, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteInfo", reflect.TypeOf((*MockInfoHandler)(nil).WriteInfo), filePath, fields)
+}
+
+// MockContentReader is a mock of ContentReader interface.
+type MockContentReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockContentReaderMockRecorder
+}
+
+// MockContentReaderMockRecorder is the mock recorder for MockContentReader.
+type MockContentReaderMockRecorder struct {
+	mock *MockContentReader
+}
+
+// NewMockContentReader creates a new mock instance.
+func NewMockContentReader(ctrl *gomock.Controller) *MockContentReader {
+	mock := &MockContentReader{ctrl: ctrl}
+	mock.recorder = &MockContentReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContentReader) EXPECT() *MockContentReaderMockRecorder {
+	return m.recorder
+}
+
+// ReadPageTextSpans mocks base method.
+func (m *MockContentReader) ReadPageTextSpans(filePath string, page int) ([]pdf.TextSpan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPageTextSpans", filePath, page)
+	ret0, _ := ret[0].([]pdf.TextSpan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadPageTextSpans indicates an expected call of ReadPageTextSpans.
+func (mr *MockContentReaderMockRecorder) ReadPageTextSpans(filePath, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPageTextSpans", reflect.TypeOf((*MockContentReader)(nil).ReadPageTextSpans), filePath, page)
+}
+
+// MockImageHandler is a mock of ImageHandler interface.
+type MockImageHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageHandlerMockRecorder
+}
+
+// MockImageHandlerMockRecorder is the mock recorder for MockImageHandler.
+type MockImageHandlerMockRecorder struct {
+	mock *MockImageHandler
+}
+
+// NewMockImageHandler creates a new mock instance.
+func NewMockImageHandler(ctrl *gomock.Controller) *MockImageHandler {
+	mock := &MockImageHandler{ctrl: ctrl}
+	mock.recorder = &MockImageHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageHandler) EXPECT() *MockImageHandlerMockRecorder {
+	return m.recorder
+}
+
+// ExtractImages mocks base method.
+func (m *MockImageHandler) ExtractImages(filePath, selection, outDir string) ([]pdf.ExtractedImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtractImages", filePath, selection, outDir)
+	ret0, _ := ret[0].([]pdf.ExtractedImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtractImages indicates an expected call of ExtractImages.
+func (mr *MockImageHandlerMockRecorder) ExtractImages(filePath, selection, outDir interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractImages", reflect.TypeOf((*MockImageHandler)(nil).ExtractImages), filePath, selection, outDir)
+}
+
+// ListImages mocks base method.
+func (m *MockImageHandler) ListImages(filePath, selection string) ([]pdf.ImageInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImages", filePath, selection)
+	ret0, _ := ret[0].([]pdf.ImageInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImages indicates an expected call of ListImages.
+func (mr *MockImageHandlerMockRecorder) ListImages(filePath, selection interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockImageHandler)(nil).ListImages), filePath, selection)
+}
+
+// MockOptimizer is a mock of Optimizer interface.
+type MockOptimizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockOptimizerMockRecorder
+}
+
+// MockOptimizerMockRecorder is the mock recorder for MockOptimizer.
+type MockOptimizerMockRecorder struct {
+	mock *MockOptimizer
+}
+
+// NewMockOptimizer creates a new mock instance.
+func NewMockOptimizer(ctrl *gomock.Controller) *MockOptimizer {
+	mock := &MockOptimizer{ctrl: ctrl}
+	mock.recorder = &MockOptimizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOptimizer) EXPECT() *MockOptimizerMockRecorder {
+	return m.recorder
+}
+
+// Optimize mocks base method.
+func (m *MockOptimizer) Optimize(filePath, outPath string, opts pdf.OptimizeOptions) (*pdf.OptimizeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Optimize", filePath, outPath, opts)
+	ret0, _ := ret[0].(*pdf.OptimizeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Optimize indicates an expected call of Optimize.
+func (mr *MockOptimizerMockRecorder) Optimize(filePath, outPath, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Optimize", reflect.TypeOf((*MockOptimizer)(nil).Optimize), filePath, outPath, opts)
+}
+
+// MockValidator is a mock of Validator interface.
+type MockValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockValidatorMockRecorder
+}
+
+// MockValidatorMockRecorder is the mock recorder for MockValidator.
+type MockValidatorMockRecorder struct {
+	mock *MockValidator
+}
+
+// NewMockValidator creates a new mock instance.
+func NewMockValidator(ctrl *gomock.Controller) *MockValidator {
+	mock := &MockValidator{ctrl: ctrl}
+	mock.recorder = &MockValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockValidator) EXPECT() *MockValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MockValidator) Validate(filePath string) (*pdf.ValidationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", filePath)
+	ret0, _ := ret[0].(*pdf.ValidationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockValidatorMockRecorder) Validate(filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockValidator)(nil).Validate), filePath)
+}
+
+// ValidatePDFA mocks base method.
+func (m *MockValidator) ValidatePDFA(filePath string) (*pdf.ValidationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidatePDFA", filePath)
+	ret0, _ := ret[0].(*pdf.ValidationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidatePDFA indicates an expected call of ValidatePDFA.
+func (mr *MockValidatorMockRecorder) ValidatePDFA(filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePDFA", reflect.TypeOf((*MockValidator)(nil).ValidatePDFA), filePath)
+}
+
+// MockRepairer is a mock of Repairer interface.
+type MockRepairer struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepairerMockRecorder
+}
+
+// MockRepairerMockRecorder is the mock recorder for MockRepairer.
+type MockRepairerMockRecorder struct {
+	mock *MockRepairer
+}
+
+// NewMockRepairer creates a new mock instance.
+func NewMockRepairer(ctrl *gomock.Controller) *MockRepairer {
+	mock := &MockRepairer{ctrl: ctrl}
+	mock.recorder = &MockRepairerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepairer) EXPECT() *MockRepairerMockRecorder {
+	return m.recorder
+}
+
+// Repair mocks base method.
+func (m *MockRepairer) Repair(filePath, outPath string) (*pdf.RepairReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Repair", filePath, outPath)
+	ret0, _ := ret[0].(*pdf.RepairReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Repair indicates an expected call of Repair.
+func (mr *MockRepairerMockRecorder) Repair(filePath, outPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Repair", reflect.TypeOf((*MockRepairer)(nil).Repair), filePath, outPath)
+}
+
+// MockPageAssembler is a mock of PageAssembler interface.
+type MockPageAssembler struct {
+	ctrl     *gomock.Controller
+	recorder *MockPageAssemblerMockRecorder
+}
+
+// MockPageAssemblerMockRecorder is the mock recorder for MockPageAssembler.
+type MockPageAssemblerMockRecorder struct {
+	mock *MockPageAssembler
+}
+
+// NewMockPageAssembler creates a new mock instance.
+func NewMockPageAssembler(ctrl *gomock.Controller) *MockPageAssembler {
+	mock := &MockPageAssembler{ctrl: ctrl}
+	mock.recorder = &MockPageAssemblerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPageAssembler) EXPECT() *MockPageAssemblerMockRecorder {
+	return m.recorder
+}
+
+// Merge mocks base method.
+func (m *MockPageAssembler) Merge(filePaths []string, outPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", filePaths, outPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockPageAssemblerMockRecorder) Merge(filePaths, outPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockPageAssembler)(nil).Merge), filePaths, outPath)
+}
+
+// Split mocks base method.
+func (m *MockPageAssembler) Split(filePath, outDir string, selections []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Split", filePath, outDir, selections)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Split indicates an expected call of Split.
+func (mr *MockPageAssemblerMockRecorder) Split(filePath, outDir, selections interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Split", reflect.TypeOf((*MockPageAssembler)(nil).Split), filePath, outDir, selections)
+}
+
+// MockContentHasher is a mock of ContentHasher interface.
+type MockContentHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockContentHasherMockRecorder
+}
+
+// MockContentHasherMockRecorder is the mock recorder for MockContentHasher.
+type MockContentHasherMockRecorder struct {
+	mock *MockContentHasher
+}
+
+// NewMockContentHasher creates a new mock instance.
+func NewMockContentHasher(ctrl *gomock.Controller) *MockContentHasher {
+	mock := &MockContentHasher{ctrl: ctrl}
+	mock.recorder = &MockContentHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContentHasher) EXPECT() *MockContentHasherMockRecorder {
+	return m.recorder
+}
+
+// ContentHash mocks base method.
+func (m *MockContentHasher) ContentHash(filePath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContentHash", filePath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContentHash indicates an expected call of ContentHash.
+func (mr *MockContentHasherMockRecorder) ContentHash(filePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContentHash", reflect.TypeOf((*MockContentHasher)(nil).ContentHash), filePath)
+}
+
+// MockDiffer is a mock of Differ interface.
+type MockDiffer struct {
+	ctrl     *gomock.Controller
+	recorder *MockDifferMockRecorder
+}
+
+// MockDifferMockRecorder is the mock recorder for MockDiffer.
+type MockDifferMockRecorder struct {
+	mock *MockDiffer
+}
+
+// NewMockDiffer creates a new mock instance.
+func NewMockDiffer(ctrl *gomock.Controller) *MockDiffer {
+	mock := &MockDiffer{ctrl: ctrl}
+	mock.recorder = &MockDifferMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiffer) EXPECT() *MockDifferMockRecorder {
+	return m.recorder
+}
+
+// DiffFiles mocks base method.
+func (m *MockDiffer) DiffFiles(oldPath, newPath string) ([]pdf.Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffFiles", oldPath, newPath)
+	ret0, _ := ret[0].([]pdf.Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffFiles indicates an expected call of DiffFiles.
+func (mr *MockDifferMockRecorder) DiffFiles(oldPath, newPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffFiles", reflect.TypeOf((*MockDiffer)(nil).DiffFiles), oldPath, newPath)
+}
+
+// DiffRevisions mocks base method.
+func (m *MockDiffer) DiffRevisions(filePath string) ([]pdf.RevisionDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffRevisions", filePath)
+	ret0, _ := ret[0].([]pdf.RevisionDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffRevisions indicates an expected call of DiffRevisions.
+func (mr *MockDifferMockRecorder) DiffRevisions(filePath interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockPDFMetadataHandler)(nil).UpdateMetadata), filePath, title, producer)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffRevisions", reflect.TypeOf((*MockDiffer)(nil).DiffRevisions), filePath)
 }
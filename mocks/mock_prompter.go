@@ -34,11 +34,12 @@ func (m *MockPrompter) EXPECT() *MockPrompterMockRecorder {
 }
 
 // PromptUser mocks base method.
-func (m *MockPrompter) PromptUser(arg0 string) string {
+func (m *MockPrompter) PromptUser(arg0 string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "PromptUser", arg0)
 	ret0, _ := ret[0].(string)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // PromptUser indicates an expected call of PromptUser.
@@ -0,0 +1,53 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/sidshirsat/pdfmod/pdf"
+)
+
+func Example() {
+	data := samplePDF()
+	doc, err := pdf.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := doc.SetMetadata(map[string]string{"Title": "Annual Report"}); err != nil {
+		log.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := doc.Save(&out, nil); err != nil {
+		log.Fatal(err)
+	}
+
+	saved, err := pdf.Read(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	metadata, err := saved.Metadata()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(metadata["Title"])
+	// Output: Annual Report
+}
+
+func ExampleDocument_Pages() {
+	data := samplePDF()
+	doc, err := pdf.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, page := range pages {
+		fmt.Printf("%s: %gx%g rotated %d\n", page.Label, page.Width, page.Height, page.Rotate)
+	}
+	// Output:
+	// i: 612x792 rotated 0
+	// ii: 595x842 rotated 270
+}
@@ -0,0 +1,205 @@
+// Package pdf reads and edits the metadata of PDF files. It is the stable
+// library interface of pdfmod: the command line tool reads and writes
+// metadata through it, and its API follows semantic versioning.
+//
+// Documents are opened from a path or from any io.ReaderAt and saved to
+// any io.Writer:
+//
+//	doc, err := pdf.Open("report.pdf")
+//	if err != nil {
+//		return err
+//	}
+//	defer doc.Close()
+//	err = doc.SetMetadata(map[string]string{"Title": "Quarterly Report"})
+//	...
+//	err = doc.Save(w, nil)
+//
+// Metadata fields are keyed by their Info dictionary entry, such as
+// "Title", or by their XMP name, such as "dc:title".
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	ipdf "github.com/sidshirsat/pdfmod/internal/pdf"
+)
+
+// Errors returned for files that cannot be processed. Errors wrap them, so
+// test for them with errors.Is.
+var (
+	ErrNotPDF            = ipdf.ErrNotPDF
+	ErrMalformed         = ipdf.ErrMalformed
+	ErrEncrypted         = ipdf.ErrEncrypted
+	ErrUnsupportedFilter = ipdf.ErrUnsupportedFilter
+)
+
+// ErrClosed is returned when a document is used after Close.
+var ErrClosed = errors.New("document is closed")
+
+// Document is an open PDF file. Changes are kept in memory until the
+// document is saved. A Document is not safe for concurrent use.
+type Document struct {
+	doc *ipdf.Document
+}
+
+// Open opens the PDF file at path. The caller must Close the document.
+func Open(path string) (*Document, error) {
+	doc, err := ipdf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{doc: doc}, nil
+}
+
+// Read parses a PDF from r, which holds size bytes. Objects are read from r
+// as they are needed, so r must stay readable until the document is saved.
+func Read(r io.ReaderAt, size int64) (*Document, error) {
+	doc, err := ipdf.NewDocument(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{doc: doc}, nil
+}
+
+// Close releases the file of a document opened with Open. It does not close
+// the reader passed to Read. The document cannot be used afterwards.
+func (d *Document) Close() error {
+	if d.doc == nil {
+		return ErrClosed
+	}
+	doc := d.doc
+	d.doc = nil
+	return doc.Close()
+}
+
+// Version returns the PDF version from the file header, such as "1.7".
+func (d *Document) Version() string {
+	if d.doc == nil {
+		return ""
+	}
+	return d.doc.Version()
+}
+
+// Metadata returns the text entries of the document information dictionary
+// and the simple XMP properties.
+func (d *Document) Metadata() (map[string]string, error) {
+	if d.doc == nil {
+		return nil, ErrClosed
+	}
+	return d.doc.Fields()
+}
+
+// SetMetadata updates fields keyed as Metadata returns them. An empty value
+// removes the field. For documents that claim PDF/A conformance, Info
+// entries are mirrored into the XMP metadata to keep the file conforming.
+func (d *Document) SetMetadata(fields map[string]string) error {
+	if d.doc == nil {
+		return ErrClosed
+	}
+	return d.doc.SetFields(fields)
+}
+
+// Page describes a page of a document.
+type Page struct {
+	// Number is the 1-based position of the page.
+	Number int
+	// Label is the page label, such as "iv", or the number as text when the
+	// document defines no labels.
+	Label string
+	// Width and Height are the size of the media box in points, before
+	// rotation.
+	Width, Height float64
+	// Rotate is the clockwise rotation in degrees: 0, 90, 180 or 270.
+	Rotate int
+}
+
+// Pages returns the pages of the document in order.
+func (d *Document) Pages() ([]Page, error) {
+	if d.doc == nil {
+		return nil, ErrClosed
+	}
+	refs, err := d.doc.Pages()
+	if err != nil {
+		return nil, err
+	}
+	labels, err := d.doc.PageLabels()
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]Page, len(refs))
+	for i, ref := range refs {
+		width, height, rotate := d.doc.PageSize(ref)
+		pages[i] = Page{Number: i + 1, Label: labels[i], Width: width, Height: height, Rotate: rotate}
+	}
+	return pages, nil
+}
+
+// SaveOptions control how a document is saved. A nil *SaveOptions uses the
+// defaults.
+type SaveOptions struct {
+	// Compact packs objects into compressed object streams, which makes
+	// most files smaller but requires readers supporting PDF 1.5.
+	Compact bool
+	// Incremental appends the changes to an unchanged copy of the original
+	// file instead of writing every object again. It is the fastest way to
+	// save small changes to large files, but earlier values of the changed
+	// fields can still be recovered from the file. It cannot be combined
+	// with Compact.
+	Incremental bool
+}
+
+// Save writes the document, with its changes, to w as a new file. The data
+// of unchanged streams, such as scanned images, is copied from the original
+// file without being held in memory.
+func (d *Document) Save(w io.Writer, opts *SaveOptions) error {
+	if d.doc == nil {
+		return ErrClosed
+	}
+	return d.doc.WriteWithOptions(w, writeOptions(opts))
+}
+
+// SaveFile writes the document to path. The file is written under a
+// temporary name and renamed when complete, so path may be the file the
+// document was opened from.
+func (d *Document) SaveFile(path string, opts *SaveOptions) error {
+	if d.doc == nil {
+		return ErrClosed
+	}
+	if err := d.doc.WriteFileWithOptions(path, writeOptions(opts)); err != nil {
+		return fmt.Errorf("could not write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func writeOptions(opts *SaveOptions) ipdf.WriteOptions {
+	if opts == nil {
+		return ipdf.WriteOptions{}
+	}
+	return ipdf.WriteOptions{ObjectStreams: opts.Compact, Incremental: opts.Incremental}
+}
+
+// ReadMetadata returns the metadata of the PDF file at path.
+func ReadMetadata(path string) (map[string]string, error) {
+	doc, err := Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+	return doc.Metadata()
+}
+
+// WriteMetadata updates the metadata of the PDF file at path in place.
+func WriteMetadata(path string, fields map[string]string) error {
+	doc, err := Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open PDF file: %w", err)
+	}
+	defer doc.Close()
+	if err := doc.SetMetadata(fields); err != nil {
+		return err
+	}
+	return doc.SaveFile(path, nil)
+}
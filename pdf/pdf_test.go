@@ -0,0 +1,135 @@
+package pdf_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sidshirsat/pdfmod/internal/pdftest"
+	"github.com/sidshirsat/pdfmod/pdf"
+)
+
+// samplePDF returns a document with a US Letter page, a rotated A4 page
+// inheriting its media box from the page tree, roman page labels and a
+// title.
+func samplePDF() []byte {
+	return pdftest.BuildWithTrailer("/Info 5 0 R ",
+		"<< /Type /Catalog /Pages 2 0 R /PageLabels << /Nums [0 << /S /r >>] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 595 842] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Rotate -90 >>",
+		"<< /Title (Sample) /Author (Jane Doe) >>",
+	)
+}
+
+func readPDF(t *testing.T, data []byte) *pdf.Document {
+	t.Helper()
+	doc, err := pdf.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return doc
+}
+
+func TestRead_Metadata(t *testing.T) {
+	doc := readPDF(t, samplePDF())
+	if got := doc.Version(); got != "1.4" {
+		t.Errorf("Version() = %q, want 1.4", got)
+	}
+	got, err := doc.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	want := map[string]string{"Title": "Sample", "Author": "Jane Doe"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Metadata() = %v, want %v", got, want)
+	}
+}
+
+func TestRead_NotPDF(t *testing.T) {
+	data := []byte("hello")
+	if _, err := pdf.Read(bytes.NewReader(data), int64(len(data))); !errors.Is(err, pdf.ErrNotPDF) {
+		t.Errorf("expected ErrNotPDF, got %v", err)
+	}
+}
+
+func TestDocument_Pages(t *testing.T) {
+	doc := readPDF(t, samplePDF())
+	got, err := doc.Pages()
+	if err != nil {
+		t.Fatalf("Pages failed: %v", err)
+	}
+	want := []pdf.Page{
+		{Number: 1, Label: "i", Width: 612, Height: 792},
+		{Number: 2, Label: "ii", Width: 595, Height: 842, Rotate: 270},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Pages() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDocument_SetMetadataAndSave(t *testing.T) {
+	doc := readPDF(t, samplePDF())
+	if err := doc.SetMetadata(map[string]string{"Title": "Changed", "Author": "", "dc:subject": "tests"}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	for _, opts := range []*pdf.SaveOptions{nil, {Compact: true}, {Incremental: true}} {
+		var buf bytes.Buffer
+		if err := doc.Save(&buf, opts); err != nil {
+			t.Fatalf("Save(%+v) failed: %v", opts, err)
+		}
+		got, err := readPDF(t, buf.Bytes()).Metadata()
+		if err != nil {
+			t.Fatalf("Metadata failed: %v", err)
+		}
+		if got["Title"] != "Changed" || got["dc:subject"] != "tests" {
+			t.Errorf("Save(%+v): metadata = %v", opts, got)
+		}
+		if _, ok := got["Author"]; ok {
+			t.Errorf("Save(%+v): Author was not removed: %v", opts, got)
+		}
+	}
+}
+
+func TestWriteMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.pdf")
+	if err := os.WriteFile(path, samplePDF(), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	if err := pdf.WriteMetadata(path, map[string]string{"Title": "On disk"}); err != nil {
+		t.Fatalf("WriteMetadata failed: %v", err)
+	}
+	got, err := pdf.ReadMetadata(path)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed: %v", err)
+	}
+	if got["Title"] != "On disk" || got["Author"] != "Jane Doe" {
+		t.Errorf("ReadMetadata() = %v", got)
+	}
+}
+
+func TestDocument_Closed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.pdf")
+	if err := os.WriteFile(path, samplePDF(), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	doc, err := pdf.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := doc.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := doc.Metadata(); !errors.Is(err, pdf.ErrClosed) {
+		t.Errorf("Metadata after Close: expected ErrClosed, got %v", err)
+	}
+	if err := doc.Save(&bytes.Buffer{}, nil); !errors.Is(err, pdf.ErrClosed) {
+		t.Errorf("Save after Close: expected ErrClosed, got %v", err)
+	}
+	if err := doc.Close(); !errors.Is(err, pdf.ErrClosed) {
+		t.Errorf("second Close: expected ErrClosed, got %v", err)
+	}
+}